@@ -0,0 +1,125 @@
+// bitconv converts Xilinx bitstream (.bit) files into raw (.bin) images and
+// decodes their configuration packet stream.
+//
+// The .bin image is the configuration data with the .bit header removed,
+// optionally bit- and byte-swapped for the configuration interface that the
+// loader uses. The packet dump lists the configuration packets (sync word,
+// IDCODE, FAR, CMD, CRC, COR0 and so on), which helps debug boot problems.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"cp/build/vivado/lib/bitstream"
+)
+
+// interfaces maps a configuration interface name to whether the data bits
+// need to be reversed within each byte for it.
+var interfaces = map[string]bool{
+	"spi":     false,
+	"smapx8":  true,
+	"smapx16": true,
+	"smapx32": true,
+}
+
+// readData reads the configuration data from a .bit or a .bin file.
+func readData(fn string, stderr io.Writer) ([]byte, error) {
+	h, d, err := bitstream.ReadFile(fn)
+	if errors.Is(err, bitstream.ErrNoHeader) {
+		// Not a .bit file, take the contents as they are.
+		return os.ReadFile(fn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(stderr, "design: %v, part: %v, tool version: %v, built: %v %v\n",
+		h.DesignName, h.Part, h.ToolVersion, h.Date, h.Time)
+	return d, nil
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("bitconv", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var inFile, outBin, dumpFile, iface string
+	var byteSwap int
+	fs.StringVar(&inFile, "bitfile", "", "The input bitstream (.bit or .bin) file")
+	fs.StringVar(&outBin, "out-bin", "", "The raw (.bin) image to write")
+	fs.StringVar(&iface, "interface", "spi", "The configuration interface: spi, smapx8, smapx16 or smapx32")
+	fs.IntVar(&byteSwap, "byte-swap", 0, "If set, reverses the byte order within words of this many bytes")
+	fs.StringVar(&dumpFile, "dump", "", "The file to write the configuration packet dump to, - for stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if inFile == "" {
+		return fmt.Errorf("param --bitfile is required")
+	}
+	bitSwap, ok := interfaces[strings.ToLower(iface)]
+	if !ok {
+		return fmt.Errorf("unknown --interface: %q", iface)
+	}
+	switch byteSwap {
+	case 0, 2, 4:
+	default:
+		return fmt.Errorf("--byte-swap must be one of 0, 2, 4, got: %v", byteSwap)
+	}
+
+	data, err := readData(inFile, stderr)
+	if err != nil {
+		return fmt.Errorf("read %v: %w", inFile, err)
+	}
+
+	if dumpFile != "" {
+		ps, err := bitstream.Decode(data)
+		if err != nil {
+			return fmt.Errorf("decode %v: %w", inFile, err)
+		}
+		w := stdout
+		if dumpFile != "-" {
+			f, err := os.Create(dumpFile)
+			if err != nil {
+				return fmt.Errorf("create: %w", err)
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := bitstream.Dump(w, ps); err != nil {
+			return fmt.Errorf("write dump %v: %w", dumpFile, err)
+		}
+	}
+
+	if outBin != "" {
+		if bitSwap {
+			bitstream.SwapBits(data)
+		}
+		if err := bitstream.SwapBytes(data, byteSwap); err != nil {
+			return fmt.Errorf("swap bytes: %w", err)
+		}
+		if err := os.WriteFile(outBin, data, 0644); err != nil {
+			return fmt.Errorf("write %v: %w", outBin, err)
+		}
+	}
+
+	return nil
+}
+
+func runCLI(osArgs []string, stdout, stderr io.Writer) error {
+	p := path.Base(osArgs[0])
+	log.SetPrefix(fmt.Sprintf("%v: ", p))
+
+	return run(osArgs[1:], stdout, stderr)
+}
+
+func main() {
+	if err := runCLI(os.Args, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+}
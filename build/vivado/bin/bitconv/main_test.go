@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testData is a minimal configuration packet stream.
+var testData = []byte{
+	0xff, 0xff, 0xff, 0xff,
+	0xaa, 0x99, 0x55, 0x66, // sync
+	0x30, 0x00, 0x80, 0x01, 0x00, 0x00, 0x00, 0x07, // WRITE CMD RCRC
+	0x30, 0x01, 0x80, 0x01, 0x03, 0x63, 0x60, 0x93, // WRITE IDCODE
+	0x30, 0x00, 0x80, 0x01, 0x00, 0x00, 0x00, 0x0d, // WRITE CMD DESYNC
+}
+
+// writeBit writes a .bit file with a header and testData.
+func writeBit(t *testing.T, fn string) {
+	t.Helper()
+	var bit []byte
+	bit = append(bit, 0x00, 0x09, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x00, 0x00, 0x01)
+	for _, f := range []string{"atop;Version=2025.2", "b7a200tfbg484", "c2025/11/20", "d10:42:17"} {
+		bit = append(bit, f[0], 0x00, byte(len(f)))
+		bit = append(bit, f[1:]...)
+		bit = append(bit, 0x00)
+	}
+	bit = append(bit, 'e', 0x00, 0x00, 0x00, byte(len(testData)))
+	bit = append(bit, testData...)
+	if err := os.WriteFile(fn, bit, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	bitFile := filepath.Join(tmpDir, "top.bit")
+	writeBit(t, bitFile)
+	binFile := filepath.Join(tmpDir, "raw.bin")
+	if err := os.WriteFile(binFile, testData, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	swapped := make([]byte, len(testData))
+	for i, b := range testData {
+		var r byte
+		for j := 0; j < 8; j++ {
+			r |= ((b >> j) & 1) << (7 - j)
+		}
+		swapped[i] = r
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantBin []byte
+		wantErr bool
+	}{
+		{
+			name:    "spi",
+			args:    []string{"--bitfile", bitFile},
+			wantBin: testData,
+		},
+		{
+			name:    "bin input",
+			args:    []string{"--bitfile", binFile},
+			wantBin: testData,
+		},
+		{
+			name:    "selectmap",
+			args:    []string{"--bitfile", bitFile, "--interface", "SMAPx8"},
+			wantBin: swapped,
+		},
+		{
+			name: "byte swap",
+			args: []string{"--bitfile", bitFile, "--byte-swap", "4"},
+			wantBin: []byte{
+				0xff, 0xff, 0xff, 0xff,
+				0x66, 0x55, 0x99, 0xaa,
+				0x01, 0x80, 0x00, 0x30, 0x07, 0x00, 0x00, 0x00,
+				0x01, 0x80, 0x01, 0x30, 0x93, 0x60, 0x63, 0x03,
+				0x01, 0x80, 0x00, 0x30, 0x0d, 0x00, 0x00, 0x00,
+			},
+		},
+		{
+			name:    "missing bitfile",
+			args:    []string{},
+			wantErr: true,
+		},
+		{
+			name:    "unknown interface",
+			args:    []string{"--bitfile", bitFile, "--interface", "jtag"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid byte swap",
+			args:    []string{"--bitfile", bitFile, "--byte-swap", "3"},
+			wantErr: true,
+		},
+		{
+			name:    "byte swap of one byte",
+			args:    []string{"--bitfile", bitFile, "--byte-swap", "1"},
+			wantErr: true,
+		},
+		{
+			name:    "nonexistent bitfile",
+			args:    []string{"--bitfile", filepath.Join(tmpDir, "nonexistent.bit")},
+			wantErr: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(tmpDir, "out"+string(rune('a'+i))+".bin")
+			args := append(tt.args, "--out-bin", out)
+			err := run(args, &bytes.Buffer{}, &bytes.Buffer{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			b, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, tt.wantBin) {
+				t.Errorf("run() wrote %x, want %x", b, tt.wantBin)
+			}
+		})
+	}
+}
+
+func TestRunDump(t *testing.T) {
+	tmpDir := t.TempDir()
+	bitFile := filepath.Join(tmpDir, "top.bit")
+	writeBit(t, bitFile)
+
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"--bitfile", bitFile, "--dump", "-"}, &stdout, &stderr); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := `0x00000008: WRITE CMD RCRC
+0x00000010: WRITE IDCODE 0x03636093
+0x00000018: WRITE CMD DESYNC
+`
+	if stdout.String() != want {
+		t.Errorf("run() dump = %q, want %q", stdout.String(), want)
+	}
+	if !strings.Contains(stderr.String(), "part: 7a200tfbg484") {
+		t.Errorf("run() did not report the header: %q", stderr.String())
+	}
+}
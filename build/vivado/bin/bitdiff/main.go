@@ -0,0 +1,267 @@
+// bitdiff compares two Xilinx bitstreams (.bit or .bin) at the configuration
+// frame level, to check that a design builds reproducibly.
+//
+// Parts of a bitstream that legitimately change from build to build are
+// ignored: the build date and time and the USERID in the .bit header, the
+// USR_ACCESS register (which may hold a timestamp), and the CRC checks that
+// depend on it. Everything else, the configuration frames and all other
+// register writes, must match. Differing frames are reported by the frame
+// address (FAR) of the write that contains them.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"sort"
+
+	"cp/build/vivado/lib/bitstream"
+)
+
+// ignoredRegisters are not compared, since their values are expected to
+// differ between otherwise identical builds.
+var ignoredRegisters = map[bitstream.Register]bool{
+	bitstream.RegAXSS: true,
+	bitstream.RegCRC:  true,
+}
+
+// defaultFrameWords is used when the part is not known.
+const defaultFrameWords = 101
+
+// image is a decoded bitstream.
+type image struct {
+	header *bitstream.Header
+	frames []bitstream.Frame
+	writes map[bitstream.Register][]uint32
+}
+
+// frameKey identifies a frame within a bitstream.
+type frameKey struct {
+	far   uint32
+	index int
+}
+
+func (k frameKey) String() string {
+	return fmt.Sprintf("FAR 0x%08x (%v) + frame %d", k.far, bitstream.DecodeFAR(k.far), k.index)
+}
+
+// readImage reads the configuration data from a .bit or a .bin file.
+func readImage(fn string) (*bitstream.Header, []byte, error) {
+	h, d, err := bitstream.ReadFile(fn)
+	if errors.Is(err, bitstream.ErrNoHeader) {
+		d, err := os.ReadFile(fn)
+		return nil, d, err
+	}
+	return h, d, err
+}
+
+// decode decodes data, which may have been bit-swapped for a SelectMAP
+// interface.
+func decode(data []byte) ([]bitstream.Packet, error) {
+	ps, err := bitstream.Decode(data)
+	if err == nil {
+		return ps, nil
+	}
+	swapped := append([]byte(nil), data...)
+	bitstream.SwapBits(swapped)
+	if ps, serr := bitstream.Decode(swapped); serr == nil {
+		return ps, nil
+	}
+	return nil, err
+}
+
+func load(fn, part string, frameWords int) (*image, error) {
+	h, data, err := readImage(fn)
+	if err != nil {
+		return nil, fmt.Errorf("read %v: %w", fn, err)
+	}
+	ps, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode %v: %w", fn, err)
+	}
+	if part == "" && h != nil {
+		part = h.Part
+	}
+	if frameWords == 0 {
+		frameWords = bitstream.FrameWords(part)
+	}
+	if frameWords == 0 {
+		log.Printf("unknown frame size for part %q, assuming %d words", part, defaultFrameWords)
+		frameWords = defaultFrameWords
+	}
+	frames, writes := bitstream.Frames(ps, frameWords)
+	img := &image{
+		header: h,
+		frames: frames,
+		writes: map[bitstream.Register][]uint32{},
+	}
+	for _, w := range writes {
+		if ignoredRegisters[w.Reg] {
+			continue
+		}
+		img.writes[w.Reg] = append(img.writes[w.Reg], w.Value)
+	}
+	return img, nil
+}
+
+// compareHeaders returns the differences between the headers that matter.
+func compareHeaders(a, b *bitstream.Header) []string {
+	if a == nil || b == nil {
+		return nil
+	}
+	var diffs []string
+	for _, f := range []struct{ name, a, b string }{
+		{"design name", a.DesignName, b.DesignName},
+		{"part", a.Part, b.Part},
+		{"tool version", a.ToolVersion, b.ToolVersion},
+	} {
+		if f.a != f.b {
+			diffs = append(diffs, fmt.Sprintf("header: %v differs: %q != %q", f.name, f.a, f.b))
+		}
+	}
+	return diffs
+}
+
+// compareRegisters returns the differences in register writes.
+func compareRegisters(a, b map[bitstream.Register][]uint32) []string {
+	var regs []bitstream.Register
+	for r := range a {
+		regs = append(regs, r)
+	}
+	for r := range b {
+		if _, ok := a[r]; !ok {
+			regs = append(regs, r)
+		}
+	}
+	sort.Slice(regs, func(i, j int) bool { return regs[i] < regs[j] })
+	var diffs []string
+	for _, r := range regs {
+		if countDiff(a[r], b[r]) > 0 {
+			diffs = append(diffs, fmt.Sprintf("register %v differs: %v != %v", r, hexWords(a[r]), hexWords(b[r])))
+		}
+	}
+	return diffs
+}
+
+func hexWords(ws []uint32) string {
+	s := "["
+	for i, w := range ws {
+		if i > 0 {
+			s += " "
+		}
+		s += fmt.Sprintf("0x%08x", w)
+	}
+	return s + "]"
+}
+
+// compareFrames returns the differences in frame content.
+func compareFrames(a, b []bitstream.Frame) []string {
+	bm := map[frameKey][]uint32{}
+	for _, f := range b {
+		bm[frameKey{f.FAR, f.Index}] = f.Words
+	}
+	var diffs []string
+	seen := map[frameKey]bool{}
+	for _, f := range a {
+		k := frameKey{f.FAR, f.Index}
+		seen[k] = true
+		bw, ok := bm[k]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("%v: only in the first bitstream", k))
+			continue
+		}
+		if n := countDiff(f.Words, bw); n > 0 {
+			diffs = append(diffs, fmt.Sprintf("%v: %d words differ", k, n))
+		}
+	}
+	for _, f := range b {
+		if k := (frameKey{f.FAR, f.Index}); !seen[k] {
+			diffs = append(diffs, fmt.Sprintf("%v: only in the second bitstream", k))
+		}
+	}
+	return diffs
+}
+
+// countDiff returns the number of words that differ between a and b.
+func countDiff(a, b []uint32) int {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	n := len(b) - len(a)
+	for i := range a {
+		if a[i] != b[i] {
+			n++
+		}
+	}
+	return n
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("bitdiff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var aFile, bFile, part string
+	var frameWords, maxFrames int
+	fs.StringVar(&aFile, "bitfile", "", "The bitstream (.bit or .bin) file to check")
+	fs.StringVar(&bFile, "reference", "", "The bitstream (.bit or .bin) file to compare against")
+	fs.StringVar(&part, "part", "", "The device part, if not given it is taken from the .bit header")
+	fs.IntVar(&frameWords, "frame-words", 0, "If set, overrides the number of words per frame derived from the part")
+	fs.IntVar(&maxFrames, "max-frames", 50, "The maximum number of differing frames to list, 0 for all")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if aFile == "" {
+		return fmt.Errorf("param --bitfile is required")
+	}
+	if bFile == "" {
+		return fmt.Errorf("param --reference is required")
+	}
+
+	a, err := load(aFile, part, frameWords)
+	if err != nil {
+		return err
+	}
+	b, err := load(bFile, part, frameWords)
+	if err != nil {
+		return err
+	}
+
+	diffs := compareHeaders(a.header, b.header)
+	diffs = append(diffs, compareRegisters(a.writes, b.writes)...)
+	for _, d := range diffs {
+		fmt.Fprintln(stdout, d)
+	}
+	frameDiffs := compareFrames(a.frames, b.frames)
+	for i, d := range frameDiffs {
+		if maxFrames > 0 && i == maxFrames {
+			fmt.Fprintf(stdout, "... and %d more differing frames\n", len(frameDiffs)-i)
+			break
+		}
+		fmt.Fprintln(stdout, d)
+	}
+
+	if n := len(diffs) + len(frameDiffs); n > 0 {
+		return fmt.Errorf("bitstreams %v and %v differ: %d frames of %d, %d other differences",
+			aFile, bFile, len(frameDiffs), len(a.frames), len(diffs))
+	}
+	fmt.Fprintf(stdout, "bitstreams %v and %v match: %d frames\n", aFile, bFile, len(a.frames))
+	return nil
+}
+
+func runCLI(osArgs []string, stdout, stderr io.Writer) error {
+	p := path.Base(osArgs[0])
+	log.SetPrefix(fmt.Sprintf("%v: ", p))
+
+	return run(osArgs[1:], stdout, stderr)
+}
+
+func main() {
+	if err := runCLI(os.Args, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+}
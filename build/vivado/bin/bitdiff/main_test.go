@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stream returns a configuration packet stream writing two frames of two
+// words each at FAR 0, and the given USR_ACCESS and CRC values.
+func stream(frame1 uint32, usrAccess, crc uint32) []byte {
+	ws := []uint32{
+		0xffffffff,
+		0xaa995566,             // sync
+		0x30018001, 0x03636093, // WRITE IDCODE
+		0x3001a001, usrAccess, // WRITE AXSS
+		0x30002001, 0x00000000, // WRITE FAR
+		0x30004004, // WRITE FDRI 4 words
+		0x11111111, frame1, 0x33333333, 0x44444444,
+		0x30000001, crc, // WRITE CRC
+		0x30008001, 0x0000000d, // WRITE CMD DESYNC
+	}
+	b := make([]byte, 4*len(ws))
+	for i, w := range ws {
+		binary.BigEndian.PutUint32(b[4*i:], w)
+	}
+	return b
+}
+
+// writeBit writes a .bit file with a header and data.
+func writeBit(t *testing.T, fn, part, date string, data []byte) {
+	t.Helper()
+	var bit []byte
+	bit = append(bit, 0x00, 0x09, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x00, 0x00, 0x01)
+	for _, f := range []string{"atop;UserID=0XFFFFFFFF;Version=2025.2", "b" + part, "c" + date, "d10:42:17"} {
+		bit = append(bit, f[0], 0x00, byte(len(f)))
+		bit = append(bit, f[1:]...)
+		bit = append(bit, 0x00)
+	}
+	bit = append(bit, 'e', 0x00, 0x00, 0x00, byte(len(data)))
+	bit = append(bit, data...)
+	if err := os.WriteFile(fn, bit, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	ref := filepath.Join(tmpDir, "ref.bit")
+	writeBit(t, ref, "7a200tfbg484", "2025/11/20", stream(0x22222222, 0x11111111, 0x12345678))
+	same := filepath.Join(tmpDir, "same.bit")
+	writeBit(t, same, "7a200tfbg484", "2025/11/21", stream(0x22222222, 0x22222222, 0x87654321))
+	changed := filepath.Join(tmpDir, "changed.bit")
+	writeBit(t, changed, "7a200tfbg484", "2025/11/20", stream(0x22222200, 0x11111111, 0x12345678))
+	writeBit(t, filepath.Join(tmpDir, "other.bit"), "7a100tcsg324", "2025/11/20", stream(0x22222200, 0x11111111, 0x12345678))
+	bin := filepath.Join(tmpDir, "same.bin")
+	if err := os.WriteFile(bin, stream(0x22222222, 0, 0), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantErr    bool
+		wantOutput string
+	}{
+		{
+			name:    "missing reference",
+			args:    []string{"--bitfile", same},
+			wantErr: true,
+		},
+		{
+			name:       "ignores timestamp and USR_ACCESS",
+			args:       []string{"--bitfile", same, "--reference", ref, "--frame-words", "2"},
+			wantOutput: "match: 2 frames",
+		},
+		{
+			name:       "bin against bit",
+			args:       []string{"--bitfile", bin, "--reference", ref, "--part", "7a200t"},
+			wantOutput: "match",
+		},
+		{
+			name:       "frame differs",
+			args:       []string{"--bitfile", changed, "--reference", ref, "--frame-words", "2"},
+			wantErr:    true,
+			wantOutput: "FAR 0x00000000 (block 0, top, row 0, column 0, minor 0) + frame 0: 1 words differ",
+		},
+		{
+			name:       "header differs",
+			args:       []string{"--bitfile", changed, "--reference", filepath.Join(tmpDir, "other.bit")},
+			wantErr:    true,
+			wantOutput: "header: part differs",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := run(tt.args, &stdout, &stderr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(stdout.String(), tt.wantOutput) {
+				t.Errorf("run() output = %q, want it to contain %q", stdout.String(), tt.wantOutput)
+			}
+		})
+	}
+}
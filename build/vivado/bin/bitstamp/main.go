@@ -0,0 +1,79 @@
+// bitstamp decodes the build stamp (git commit, dirty flag and build time)
+// that vivado_place_and_route2 writes into the USERID and USR_ACCESS values
+// of a bitstream when stamping is enabled.
+//
+// The stamp is read either from a .bit file, or from the USERID and
+// USR_ACCESS values read back from a device over JTAG.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+
+	"cp/build/vivado/lib/buildstamp"
+)
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("bitstamp", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var bitFile, userID, usrAccess string
+	fs.StringVar(&bitFile, "bitfile", "", "The bitstream (.bit) file to read the stamp from")
+	fs.StringVar(&userID, "userid", "", "The USERID value read from a device, in hex")
+	fs.StringVar(&usrAccess, "usr-access", "", "The USR_ACCESS value read from a device, in hex")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var s buildstamp.Stamp
+	switch {
+	case bitFile != "" && (userID != "" || usrAccess != ""):
+		return fmt.Errorf("param --bitfile can not be used with --userid or --usr-access")
+	case bitFile != "":
+		var err error
+		s, err = buildstamp.FromBitFile(bitFile)
+		if err != nil {
+			return fmt.Errorf("read stamp from %v: %w", bitFile, err)
+		}
+	case userID == "":
+		return fmt.Errorf("param --bitfile or --userid is required")
+	case usrAccess == "":
+		return fmt.Errorf("param --usr-access is required")
+	default:
+		u, err := buildstamp.ParseWord(userID)
+		if err != nil {
+			return fmt.Errorf("param --userid: %w", err)
+		}
+		a, err := buildstamp.ParseWord(usrAccess)
+		if err != nil {
+			return fmt.Errorf("param --usr-access: %w", err)
+		}
+		s, err = buildstamp.Decode(u, a)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(stdout, "commit: %v\n", s.Commit)
+	fmt.Fprintf(stdout, "dirty:  %v\n", s.Dirty)
+	fmt.Fprintf(stdout, "built:  %v\n", s.Time.Format("2006-01-02 15:04:05 MST"))
+	return nil
+}
+
+func runCLI(osArgs []string, stdout, stderr io.Writer) error {
+	p := path.Base(osArgs[0])
+	log.SetPrefix(fmt.Sprintf("%v: ", p))
+
+	return run(osArgs[1:], stdout, stderr)
+}
+
+func main() {
+	if err := runCLI(os.Args, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeBit writes a .bit file with a stamped USERID in the header and a
+// write of the matching USR_ACCESS value.
+func writeBit(t *testing.T, fn string) {
+	t.Helper()
+	data := []byte{
+		0xaa, 0x99, 0x55, 0x66, // sync
+		0x30, 0x01, 0xa0, 0x01, 0x9d, 0x34, 0xd3, 0x8f, // WRITE AXSS
+		0x30, 0x00, 0x80, 0x01, 0x00, 0x00, 0x00, 0x0d, // WRITE CMD DESYNC
+	}
+	var bit []byte
+	bit = append(bit, 0x00, 0x09, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x00, 0x00, 0x01)
+	for _, f := range []string{"atop;UserID=0X1A2B3C43;Version=2025.2", "b7a200tfbg484", "c2026/10/19", "d13:14:15"} {
+		bit = append(bit, f[0], 0x00, byte(len(f)))
+		bit = append(bit, f[1:]...)
+		bit = append(bit, 0x00)
+	}
+	bit = append(bit, 'e', 0x00, 0x00, 0x00, byte(len(data)))
+	bit = append(bit, data...)
+	if err := os.WriteFile(fn, bit, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	bitFile := filepath.Join(tmpDir, "top.bit")
+	writeBit(t, bitFile)
+
+	const want = "commit: 1a2b3c4\ndirty:  true\nbuilt:  2026-10-19 13:14:15 UTC\n"
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "From bit file",
+			args: []string{"--bitfile", bitFile},
+			want: want,
+		},
+		{
+			name: "From device values",
+			args: []string{"--userid", "0X1A2B3C43", "--usr-access", "9d34d38f"},
+			want: want,
+		},
+		{
+			name:    "Not stamped",
+			args:    []string{"--userid", "0xFFFFFFFF", "--usr-access", "0x0"},
+			wantErr: true,
+		},
+		{
+			name:    "Both sources",
+			args:    []string{"--bitfile", bitFile, "--userid", "0x1a2b3c43"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing USR_ACCESS",
+			args:    []string{"--userid", "0x1a2b3c43"},
+			wantErr: true,
+		},
+		{
+			name:    "No args",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(tt.args, &stdout, &bytes.Buffer{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("run() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// cdcreport checks the clock domain crossings of a design, from the text
+// output of Vivado's `report_cdc -details` and `report_clock_interaction`.
+//
+// It writes the crossings, classified as safe, unsafe or unknown, and the
+// clock pairs as JSON, and fails if a critical crossing or an unsafe clock
+// pair is not waived. Waivers are read from a JSON file, see
+// report.Waiver. Waivers that match nothing are reported, so that they can
+// be removed.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"cp/build/vivado/lib/report"
+)
+
+// Result is the JSON output.
+type Result struct {
+	Crossings  []report.Violation `json:"crossings"`
+	ClockPairs []report.ClockPair `json:"clock_pairs"`
+	// Unsafe and Unknown count the crossings by category, Waived the
+	// critical crossings and unsafe clock pairs that are waived.
+	Unsafe  int `json:"unsafe"`
+	Unknown int `json:"unknown"`
+	Waived  int `json:"waived"`
+	// Unwaived are the critical crossings and unsafe clock pairs that fail
+	// the check.
+	Unwaived      []report.Violation `json:"unwaived"`
+	UnusedWaivers []report.Waiver    `json:"unused_waivers,omitempty"`
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("cdcreport", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var cdcFile, interactionFile, waiversFile, outFile string
+	fs.StringVar(&cdcFile, "cdc-report", "", "The report_cdc -details text report to read")
+	fs.StringVar(&interactionFile, "clock-interaction-report", "", "The report_clock_interaction text report to read")
+	fs.StringVar(&waiversFile, "waivers", "", "The JSON waiver file to apply")
+	fs.StringVar(&outFile, "out", "", "The JSON file to write, stdout if empty")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if cdcFile == "" {
+		return fmt.Errorf("param --cdc-report is required")
+	}
+
+	var res Result
+	var err error
+	res.Crossings, err = report.ParseFile(cdcFile, report.ParseCDC)
+	if err != nil {
+		return err
+	}
+	violations := res.Crossings
+	if interactionFile != "" {
+		res.ClockPairs, err = report.ParseFile(interactionFile, report.ParseClockInteraction)
+		if err != nil {
+			return err
+		}
+		for _, p := range res.ClockPairs {
+			if p.Unsafe() {
+				violations = append(violations, p.Violation())
+			}
+		}
+	}
+
+	var ws *report.Waivers
+	if waiversFile != "" {
+		ws, err = report.ReadWaivers(waiversFile)
+		if err != nil {
+			return err
+		}
+	}
+	res.UnusedWaivers = ws.Apply(violations)
+	for _, w := range res.UnusedWaivers {
+		fmt.Fprintf(stderr, "WARNING: %v: waiver matches nothing: %v\n", waiversFile, w)
+	}
+	// Apply marks the copies of the crossings in violations.
+	copy(res.Crossings, violations)
+	for _, v := range violations {
+		if v.Critical() && v.Waived() {
+			res.Waived++
+		}
+	}
+	for _, c := range res.Crossings {
+		switch c.Category {
+		case "unsafe":
+			res.Unsafe++
+		case "unknown":
+			res.Unknown++
+		}
+	}
+	res.Unwaived = report.Unwaived(violations)
+
+	b, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if outFile == "" {
+		if _, err := stdout.Write(b); err != nil {
+			return err
+		}
+	} else if err := os.WriteFile(outFile, b, 0644); err != nil {
+		return err
+	}
+
+	if len(res.Unwaived) > 0 {
+		var lines []string
+		for _, v := range res.Unwaived {
+			lines = append(lines, fmt.Sprintf("  %v %v -> %v: %v: %v -> %v",
+				v.ID, v.FromClock, v.ToClock, v.Description, v.Startpoint, v.Endpoint))
+		}
+		return fmt.Errorf("%d unwaived critical clock domain crossings:\n%v",
+			len(res.Unwaived), strings.Join(lines, "\n"))
+	}
+	return nil
+}
+
+func runCLI(osArgs []string, stdout, stderr io.Writer) error {
+	p := path.Base(osArgs[0])
+	log.SetPrefix(fmt.Sprintf("%v: ", p))
+
+	return run(osArgs[1:], stdout, stderr)
+}
+
+func main() {
+	if err := runCLI(os.Args, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+}
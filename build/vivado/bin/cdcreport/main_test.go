@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const cdcReport = `2. Details
+----------
+
+Source Clock: clk_adc
+Destination Clock: clk_sys
+
++----------+-------+-----------------------------+-----------+-------------------+---------------------+----------+
+| Severity | ID    | Description                 | Exception | Source            | Destination         | Category |
++----------+-------+-----------------------------+-----------+-------------------+---------------------+----------+
+| Critical | CDC-1 | 1-bit unknown CDC circuitry | None      | u_adc/valid_reg/C | u_sys/valid_q_reg/D | Unknown  |
+| Warning  | CDC-3 | 1-bit synchronized          | None      | u_adc/flag_reg/C  | u_sync/sync_reg/D   | Safe     |
++----------+-------+-----------------------------+-----------+-------------------+---------------------+----------+
+`
+
+const clockInteractionReport = `+------------+----------+---------------------------+-------------------------+
+| From Clock | To Clock | Clock Pair Classification | Inter-Clock Constraints |
++------------+----------+---------------------------+-------------------------+
+| clk_adc    | clk_sys  | No Common Clock           | Timed (unsafe)          |
++------------+----------+---------------------------+-------------------------+
+`
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	cdcFile := filepath.Join(tmpDir, "top.cdc.rpt")
+	interactionFile := filepath.Join(tmpDir, "top.clock_interaction.rpt")
+	waiveCDC := filepath.Join(tmpDir, "cdc.json")
+	waiveAll := filepath.Join(tmpDir, "all.json")
+	for fn, content := range map[string]string{
+		cdcFile:         cdcReport,
+		interactionFile: clockInteractionReport,
+		waiveCDC: `{"waivers": [
+			{"id": "CDC-1", "endpoint": "u_sys/valid_q_reg/D", "reason": "Held stable for 4 cycles"}
+		]}`,
+		waiveAll: `{"waivers": [
+			{"id": "CDC-1", "endpoint": "u_sys/valid_q_reg/D", "reason": "Held stable for 4 cycles"},
+			{"id": "clock_interaction", "from_clock": "clk_adc", "to_clock": "clk_sys", "reason": "Covered by CDC waivers"},
+			{"id": "CDC-4", "reason": "Stale"}
+		]}`,
+	} {
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantErr    string
+		wantWaived int
+		wantUnused int
+	}{
+		{
+			name:    "Unwaived crossing",
+			args:    []string{"--cdc-report", cdcFile},
+			wantErr: "1 unwaived critical clock domain crossings",
+		},
+		{
+			name:       "Waived crossing",
+			args:       []string{"--cdc-report", cdcFile, "--waivers", waiveCDC},
+			wantWaived: 1,
+		},
+		{
+			name:       "Unsafe clock pair",
+			args:       []string{"--cdc-report", cdcFile, "--clock-interaction-report", interactionFile, "--waivers", waiveCDC},
+			wantErr:    "clock_interaction clk_adc -> clk_sys",
+			wantWaived: 1,
+		},
+		{
+			name:       "All waived",
+			args:       []string{"--cdc-report", cdcFile, "--clock-interaction-report", interactionFile, "--waivers", waiveAll},
+			wantWaived: 2,
+			wantUnused: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			outFile := filepath.Join(t.TempDir(), "cdc.json")
+			var stdout, stderr bytes.Buffer
+			err := run(append(tc.args, "--out", outFile), &stdout, &stderr)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("run() error = %v, want %q", err, tc.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+
+			b, err := os.ReadFile(outFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got Result
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatalf("invalid JSON %s: %v", b, err)
+			}
+			if len(got.Crossings) != 2 || got.Unknown != 1 || got.Unsafe != 0 {
+				t.Errorf("run() wrote %s", b)
+			}
+			if got.Waived != tc.wantWaived {
+				t.Errorf("run() waived %d, want %d", got.Waived, tc.wantWaived)
+			}
+			if len(got.UnusedWaivers) != tc.wantUnused {
+				t.Errorf("run() unused waivers = %+v, want %d", got.UnusedWaivers, tc.wantUnused)
+			}
+			if tc.wantUnused > 0 && !strings.Contains(stderr.String(), "waiver matches nothing: id=CDC-4") {
+				t.Errorf("run() stderr = %q, want a warning about the unused waiver", stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunMissingReport(t *testing.T) {
+	if err := run(nil, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Errorf("run() succeeded, want error")
+	}
+}
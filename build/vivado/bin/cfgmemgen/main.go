@@ -0,0 +1,323 @@
+// cfgmemgen generates a Vivado TCL script that writes a configuration memory
+// (flash) image from a bitstream with write_cfgmem.
+//
+// Besides the bitstream, the image can carry data files, such as a soft CPU
+// firmware, at fixed offsets. The generator checks that everything fits into
+// the flash and that no two regions overlap, so that mistakes show up at build
+// time instead of as a board that does not boot.
+//
+// With --layout, the image follows a MultiBoot flash layout instead (see the
+// flashlayout package), and bitstreams are given per layout image with
+// --image. With --out-properties, the generator writes the constraints that
+// set the bitstream properties a layout image must be built with.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"cp/build/vivado/lib/bitstream"
+	"cp/build/vivado/lib/flags"
+	"cp/build/vivado/lib/flashlayout"
+)
+
+// interfaces are the flash interfaces that write_cfgmem accepts.
+var interfaces = []string{
+	"SPIx1", "SPIx2", "SPIx4", "SPIx8", "BPIx8", "BPIx16",
+}
+
+// formats are the flash image formats that write_cfgmem can produce.
+var formats = []string{"mcs", "bin"}
+
+// CfgmemBinding is the data model of the write_cfgmem script template.
+type CfgmemBinding struct {
+	// Format is the image format, "mcs" or "bin".
+	Format string
+	// Size is the flash size in megabytes.
+	Size int
+	// Interface is the flash interface, e.g. "SPIx4".
+	Interface string
+	// Bitstreams are the bitstreams to load.
+	Bitstreams []DataFile
+	// DataFiles are the data files to load in addition to the bitstreams.
+	DataFiles []DataFile
+	// OutFile is the flash image to write.
+	OutFile string
+}
+
+// DataFile is a file loaded into the flash at a fixed address.
+type DataFile struct {
+	Address uint64
+	File    string
+}
+
+// PropertiesBinding is the data model of the bitstream properties template.
+type PropertiesBinding struct {
+	// Layout is the flash layout file.
+	Layout string
+	// Image is the name of the layout image.
+	Image string
+	// Properties are the bitstream properties to set.
+	Properties []flashlayout.Property
+}
+
+// region is a range of flash addresses occupied by a file.
+type region struct {
+	name       string
+	start, end uint64
+}
+
+// parseDataFile parses a data file spec in the form address=file.
+func parseDataFile(s string) (DataFile, error) {
+	a, f, ok := strings.Cut(s, "=")
+	if !ok || f == "" {
+		return DataFile{}, fmt.Errorf("want address=file, got: %q", s)
+	}
+	addr, err := strconv.ParseUint(a, 0, 64)
+	if err != nil {
+		return DataFile{}, fmt.Errorf("invalid address in %q: %w", s, err)
+	}
+	return DataFile{Address: addr, File: f}, nil
+}
+
+// bitstreamLength returns the number of bytes that the bitstream takes in the
+// flash.
+func bitstreamLength(fn string) (uint64, error) {
+	h, _, err := bitstream.ReadFile(fn)
+	if err == nil {
+		return uint64(h.DataLength), nil
+	}
+	if !errors.Is(err, bitstream.ErrNoHeader) {
+		return 0, err
+	}
+	return fileLength(fn)
+}
+
+func fileLength(fn string) (uint64, error) {
+	fi, err := os.Stat(fn)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(fi.Size()), nil
+}
+
+// checkLayout returns an error if a region does not fit into a flash of
+// the given size in bytes, or if two regions overlap.
+func checkLayout(regions []region, size uint64) error {
+	sort.Slice(regions, func(i, j int) bool { return regions[i].start < regions[j].start })
+	for i, r := range regions {
+		if r.end > size {
+			return fmt.Errorf("%v at 0x%08x-0x%08x does not fit into the %d byte flash",
+				r.name, r.start, r.end, size)
+		}
+		if i > 0 && regions[i-1].end > r.start {
+			p := regions[i-1]
+			return fmt.Errorf("%v at 0x%08x-0x%08x overlaps %v at 0x%08x-0x%08x",
+				r.name, r.start, r.end, p.name, p.start, p.end)
+		}
+	}
+	return nil
+}
+
+func contains(vs []string, v string) bool {
+	for _, s := range vs {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	var b CfgmemBinding
+	fs := flag.NewFlagSet("cfgmemgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var outTcl, outProperties, bitFile, loadAddress, layoutFile, propertiesFor string
+	fs.StringVar(&outTcl, "out-tcl", "", "The TCL script to generate")
+	fs.StringVar(&b.OutFile, "out-file", "", "The flash image that the script writes")
+	fs.StringVar(&bitFile, "bitfile", "", "The bitstream to load into the flash image")
+	fs.StringVar(&b.Format, "format", "mcs", "The flash image format: mcs or bin")
+	fs.IntVar(&b.Size, "size", 0, "The flash size in megabytes")
+	fs.StringVar(&b.Interface, "interface", "SPIx4", "The flash interface: SPIx1, SPIx2, SPIx4, SPIx8, BPIx8 or BPIx16")
+	fs.StringVar(&loadAddress, "load-address", "0x0", "The address to load the bitstream at")
+	var dataFiles flags.Strings
+	fs.Var(&dataFiles, "data", "each is: address=file, a data file to load at address")
+	fs.StringVar(&layoutFile, "layout", "", "The MultiBoot flash layout (.json) file")
+	var images flags.Strings
+	fs.Var(&images, "image", "each is: name=file, the bitstream to load into the named layout image")
+	fs.StringVar(&outProperties, "out-properties", "", "The constraints (.xdc) file with bitstream properties to generate")
+	fs.StringVar(&propertiesFor, "properties-for", "", "The layout image to generate bitstream properties for")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var layout *flashlayout.Layout
+	if layoutFile != "" {
+		l, err := flashlayout.Read(layoutFile)
+		if err != nil {
+			return fmt.Errorf("invalid --layout: %w", err)
+		}
+		layout = l
+	}
+
+	if outProperties != "" {
+		if layout == nil {
+			return fmt.Errorf("param --layout is required with --out-properties")
+		}
+		ps, err := layout.Properties(propertiesFor)
+		if err != nil {
+			return fmt.Errorf("invalid --properties-for: %w", err)
+		}
+		p := PropertiesBinding{Layout: layoutFile, Image: propertiesFor, Properties: ps}
+		tpl, err := loadTemplate(propertiesTemplate)
+		if err != nil {
+			return err
+		}
+		if err := WriteFile(outProperties, tpl, &p); err != nil {
+			return err
+		}
+	}
+	if outTcl == "" {
+		if outProperties != "" {
+			return nil
+		}
+		return fmt.Errorf("param --out-tcl is required")
+	}
+	if b.OutFile == "" {
+		return fmt.Errorf("param --out-file is required")
+	}
+
+	var regions []region
+	if layout != nil {
+		if bitFile != "" {
+			return fmt.Errorf("use --image instead of --bitfile with --layout")
+		}
+		if len(images) == 0 {
+			return fmt.Errorf("param --image is required with --layout")
+		}
+		// The rules know the size and interface up front, so when given,
+		// they must match the layout.
+		set := map[string]bool{}
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if set["size"] && b.Size != layout.FlashSizeMB {
+			return fmt.Errorf("--size %v does not match the layout flash size %v", b.Size, layout.FlashSizeMB)
+		}
+		if set["interface"] && b.Interface != layout.Interface {
+			return fmt.Errorf("--interface %v does not match the layout interface %v", b.Interface, layout.Interface)
+		}
+		b.Size = layout.FlashSizeMB
+		b.Interface = layout.Interface
+		for _, v := range images {
+			name, fn, ok := strings.Cut(v, "=")
+			if !ok || fn == "" {
+				return fmt.Errorf("invalid --image, want name=file, got: %q", v)
+			}
+			im, err := layout.Image(name)
+			if err != nil {
+				return fmt.Errorf("invalid --image: %w", err)
+			}
+			l, err := bitstreamLength(fn)
+			if err != nil {
+				return fmt.Errorf("could not read bitstream: %w", err)
+			}
+			if size := uint64(im.Size); l > size {
+				return fmt.Errorf("bitstream %v (%d bytes) does not fit into the %d byte region of image %q",
+					fn, l, size, name)
+			}
+			addr := uint64(im.Address)
+			b.Bitstreams = append(b.Bitstreams, DataFile{Address: addr, File: fn})
+			regions = append(regions, region{name: fn, start: addr, end: addr + l})
+		}
+	} else {
+		if bitFile == "" {
+			return fmt.Errorf("param --bitfile is required")
+		}
+		addr, err := strconv.ParseUint(loadAddress, 0, 64)
+		if err != nil {
+			return fmt.Errorf("invalid --load-address: %w", err)
+		}
+		l, err := bitstreamLength(bitFile)
+		if err != nil {
+			return fmt.Errorf("could not read bitstream: %w", err)
+		}
+		b.Bitstreams = []DataFile{{Address: addr, File: bitFile}}
+		regions = append(regions, region{name: bitFile, start: addr, end: addr + l})
+	}
+	if b.Size <= 0 {
+		return fmt.Errorf("param --size is required and must be positive, got: %v", b.Size)
+	}
+	if !contains(formats, b.Format) {
+		return fmt.Errorf("--format must be one of %v, got: %q", formats, b.Format)
+	}
+	if !contains(interfaces, b.Interface) {
+		return fmt.Errorf("--interface must be one of %v, got: %q", interfaces, b.Interface)
+	}
+
+	for _, d := range dataFiles {
+		df, err := parseDataFile(d)
+		if err != nil {
+			return fmt.Errorf("invalid --data: %w", err)
+		}
+		l, err := fileLength(df.File)
+		if err != nil {
+			return fmt.Errorf("could not read data file: %w", err)
+		}
+		r := region{name: df.File, start: df.Address, end: df.Address + l}
+		if layout != nil {
+			for _, im := range layout.Images {
+				if r.start < uint64(im.End()) && uint64(im.Address) < r.end {
+					return fmt.Errorf("data file %v at 0x%08x-0x%08x overlaps the region of image %q at %v-%v",
+						r.name, r.start, r.end, im.Name, im.Address, im.End())
+				}
+			}
+		}
+		regions = append(regions, r)
+		b.DataFiles = append(b.DataFiles, df)
+	}
+	if err := checkLayout(regions, uint64(b.Size)<<20); err != nil {
+		return fmt.Errorf("invalid flash layout: %w", err)
+	}
+
+	tpl, err := loadTemplate(cfgmemTemplate)
+	if err != nil {
+		return err
+	}
+	return WriteFile(outTcl, tpl, &b)
+}
+
+// WriteFile writes the file fn from the template tpl.
+func WriteFile(fn string, tpl *template.Template, b interface{}) error {
+	f, err := os.Create(fn)
+	if err != nil {
+		return fmt.Errorf("could not create: %v: %w", fn, err)
+	}
+	if err := tpl.Execute(f, b); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write: %v: %w", fn, err)
+	}
+	return f.Close()
+}
+
+func runCLI(osArgs []string, stdout, stderr io.Writer) error {
+	p := path.Base(osArgs[0])
+	log.SetPrefix(fmt.Sprintf("%v: ", p))
+
+	return run(osArgs[1:], stdout, stderr)
+}
+
+func main() {
+	if err := runCLI(os.Args, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	bitFile := filepath.Join(tmpDir, "top.bin")
+	if err := os.WriteFile(bitFile, make([]byte, 0x1000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fwFile := filepath.Join(tmpDir, "fw.bin")
+	if err := os.WriteFile(fwFile, make([]byte, 0x100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outTcl := filepath.Join(tmpDir, "out.tcl")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "bitstream only",
+			args: []string{"--bitfile", bitFile, "--size", "16"},
+			want: []string{
+				"write_cfgmem -force -format mcs -size 16 -interface SPIx4",
+				"-loadbit {up 0x00000000 " + bitFile + "}",
+				"-file top.mcs",
+			},
+		},
+		{
+			name: "data file",
+			args: []string{"--bitfile", bitFile, "--size", "16", "--interface", "BPIx16",
+				"--format", "bin", "--load-address", "0x10000", "--data", "0x400000=" + fwFile},
+			want: []string{
+				"-format bin -size 16 -interface BPIx16",
+				"-loadbit {up 0x00010000 " + bitFile + "}",
+				"-loaddata {up 0x00400000 " + fwFile + "}",
+			},
+		},
+		{
+			name:    "missing size",
+			args:    []string{"--bitfile", bitFile},
+			wantErr: true,
+		},
+		{
+			name:    "unknown interface",
+			args:    []string{"--bitfile", bitFile, "--size", "16", "--interface", "SPIx3"},
+			wantErr: true,
+		},
+		{
+			name:    "overlap",
+			args:    []string{"--bitfile", bitFile, "--size", "16", "--data", "0x800=" + fwFile},
+			wantErr: true,
+		},
+		{
+			name:    "does not fit",
+			args:    []string{"--bitfile", bitFile, "--size", "1", "--data", "0xfff80=" + fwFile},
+			wantErr: true,
+		},
+		{
+			name:    "bad data spec",
+			args:    []string{"--bitfile", bitFile, "--size", "16", "--data", fwFile},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--out-tcl", outTcl, "--out-file", "top.mcs"}, tt.args...)
+			var stdout, stderr bytes.Buffer
+			err := run(args, &stdout, &stderr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			b, err := os.ReadFile(outTcl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(string(b), w) {
+					t.Errorf("run() script = %s\nwant it to contain %q", b, w)
+				}
+			}
+		})
+	}
+}
+
+func TestRunLayout(t *testing.T) {
+	tmpDir := t.TempDir()
+	golden := filepath.Join(tmpDir, "golden.bin")
+	if err := os.WriteFile(golden, make([]byte, 0x1000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	update := filepath.Join(tmpDir, "update.bin")
+	if err := os.WriteFile(update, make([]byte, 0x3000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	layout := filepath.Join(tmpDir, "layout.json")
+	if err := os.WriteFile(layout, []byte(`{
+		"flash_size_mb": 16,
+		"sector_size": "0x1000",
+		"interface": "SPIx1",
+		"images": [
+			{"name": "golden", "address": "0x0"},
+			{"name": "update", "address": "0x2000", "size": "0x2000"}
+		]
+	}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outTcl := filepath.Join(tmpDir, "out.tcl")
+	outXdc := filepath.Join(tmpDir, "out.xdc")
+
+	tests := []struct {
+		name    string
+		args    []string
+		outFile string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "golden image",
+			args:    []string{"--out-tcl", outTcl, "--out-file", "top.mcs", "--image", "golden=" + golden},
+			outFile: outTcl,
+			want: []string{
+				"-size 16 -interface SPIx1",
+				"-loadbit {up 0x00000000 " + golden + "}",
+			},
+		},
+		{
+			name:    "update does not fit",
+			args:    []string{"--out-tcl", outTcl, "--out-file", "top.mcs", "--image", "golden=" + golden, "--image", "update=" + update},
+			wantErr: true,
+		},
+		{
+			name:    "data in image region",
+			args:    []string{"--out-tcl", outTcl, "--out-file", "top.mcs", "--image", "golden=" + golden, "--data", "0x3000=" + golden},
+			wantErr: true,
+		},
+		{
+			name:    "data after images",
+			args:    []string{"--out-tcl", outTcl, "--out-file", "top.mcs", "--image", "golden=" + golden, "--data", "0x4000=" + golden},
+			outFile: outTcl,
+			want:    []string{"-loaddata {up 0x00004000 " + golden + "}"},
+		},
+		{
+			name:    "unknown image",
+			args:    []string{"--out-tcl", outTcl, "--out-file", "top.mcs", "--image", "other=" + golden},
+			wantErr: true,
+		},
+		{
+			name:    "interface does not match",
+			args:    []string{"--out-tcl", outTcl, "--out-file", "top.mcs", "--interface", "SPIx4", "--image", "golden=" + golden},
+			wantErr: true,
+		},
+		{
+			name:    "bitfile with layout",
+			args:    []string{"--out-tcl", outTcl, "--out-file", "top.mcs", "--bitfile", golden},
+			wantErr: true,
+		},
+		{
+			name:    "properties",
+			args:    []string{"--out-properties", outXdc, "--properties-for", "golden"},
+			outFile: outXdc,
+			want: []string{
+				"set_property BITSTREAM.CONFIG.NEXT_CONFIG_ADDR 0x00002000 [current_design]",
+				"set_property BITSTREAM.CONFIG.CONFIGFALLBACK ENABLE [current_design]",
+			},
+		},
+		{
+			name:    "properties for unknown image",
+			args:    []string{"--out-properties", outXdc, "--properties-for", "other"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--layout", layout}, tt.args...)
+			var stdout, stderr bytes.Buffer
+			err := run(args, &stdout, &stderr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			b, err := os.ReadFile(tt.outFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(string(b), w) {
+					t.Errorf("run() output = %s\nwant it to contain %q", b, w)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	for _, name := range []string{cfgmemTemplate, propertiesTemplate} {
+		if _, err := loadTemplate(name); err != nil {
+			t.Errorf("loadTemplate(%q) error = %v", name, err)
+		}
+	}
+	if _, err := loadTemplate("nonexistent.tmpl"); err == nil {
+		t.Errorf("loadTemplate() of a nonexistent template: want error")
+	}
+}
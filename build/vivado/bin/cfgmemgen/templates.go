@@ -0,0 +1,29 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"text/template"
+)
+
+const (
+	// The TCL script writing the configuration memory image. The braces
+	// around `up <address> <file>` are required by write_cfgmem.
+	cfgmemTemplate = "cfgmem.tcl.tmpl"
+	// The constraints setting the bitstream properties of a MultiBoot image.
+	propertiesTemplate = "properties.xdc.tmpl"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// loadTemplate parses the embedded template name. The errors have the
+// template name and line, e.g. `template: cfgmem.tcl.tmpl:6: ...`.
+func loadTemplate(name string) (*template.Template, error) {
+	b, err := templates.ReadFile(path.Join("templates", name))
+	if err != nil {
+		return nil, fmt.Errorf("read template %s: %w", name, err)
+	}
+	return template.New(name).Parse(string(b))
+}
@@ -0,0 +1,211 @@
+// htmlreport writes a self-contained HTML page that summarizes the reports
+// and logs of an implementation run: the timing summary and its clocks, the
+// utilization of each site type, the DRC and methodology findings, the
+// warnings of the logs by message ID, and the runtime of each step.
+//
+// All reports are optional, the sections of the missing ones are left out.
+// Logs are given as "stage=file", e.g. "route=top.route.log", in the order
+// that the stages ran.
+package main
+
+import (
+	_ "embed"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"cp/build/vivado/lib/flags"
+	"cp/build/vivado/lib/report"
+)
+
+//go:embed report.html.tmpl
+var pageTemplate string
+
+// Stage is the log of an implementation step.
+type Stage struct {
+	Name     string
+	Commands []report.CommandTime
+	// ElapsedSeconds is the sum of the elapsed times of the commands, and
+	// PeakMemoryMB the most memory that any of them used.
+	ElapsedSeconds float64
+	PeakMemoryMB   float64
+}
+
+// Findings are the violations of a report, such as the DRC.
+type Findings struct {
+	Title      string
+	Violations []report.Violation
+}
+
+// Page is the data of the page template.
+type Page struct {
+	Name        string
+	Timing      *report.TimingSummary
+	Utilization *report.Utilization
+	// Findings are those of the DRC and methodology reports that are
+	// given, even if they have none.
+	Findings []Findings
+	// Warnings are the warnings, critical warnings and errors of all logs.
+	Warnings []report.MessageCount
+	Stages   []Stage
+	// ElapsedSeconds is the elapsed time of all stages.
+	ElapsedSeconds float64
+}
+
+// barWidth returns the width in percent of a utilization bar.
+func barWidth(pct float64) float64 {
+	if pct > 100 {
+		return 100
+	}
+	if pct < 0 {
+		return 0
+	}
+	return pct
+}
+
+// utilClass returns the CSS class of a utilization bar.
+func utilClass(pct float64) string {
+	switch {
+	case pct >= 90:
+		return "bad"
+	case pct >= 70:
+		return "warn"
+	}
+	return "good"
+}
+
+// severityClass returns the CSS class of a violation or message severity.
+func severityClass(severity string) string {
+	s := strings.ToLower(severity)
+	switch {
+	case strings.HasPrefix(s, "critical") || strings.HasPrefix(s, "error"):
+		return "bad"
+	case strings.HasPrefix(s, "warning"):
+		return "warn"
+	}
+	return ""
+}
+
+var funcs = template.FuncMap{
+	"seconds":       report.FormatSeconds,
+	"barWidth":      barWidth,
+	"utilClass":     utilClass,
+	"severityClass": severityClass,
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("htmlreport", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var name, timingFile, utilizationFile, drcFile, checkTimingFile, methodologyFile, outFile string
+	var logs flags.Strings
+	fs.StringVar(&name, "name", "", "The name of the design, for the page title")
+	fs.StringVar(&timingFile, "timing-summary", "", "The report_timing_summary text report to read")
+	fs.StringVar(&utilizationFile, "utilization", "", "The report_utilization text report to read")
+	fs.StringVar(&drcFile, "drc", "", "The report_drc text report to read")
+	fs.StringVar(&checkTimingFile, "check-timing-report", "", "The check_timing -verbose text report to read")
+	fs.StringVar(&methodologyFile, "methodology-report", "", "The report_methodology text report to read")
+	fs.Var(&logs, "log", "A stage log to read, as stage=file, can be repeated")
+	fs.StringVar(&outFile, "out", "", "The HTML file to write, stdout if empty")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("param --name is required")
+	}
+
+	p := Page{Name: name}
+	var err error
+	if timingFile != "" {
+		if p.Timing, err = report.ParseFile(timingFile, report.ParseTimingSummary); err != nil {
+			return err
+		}
+	}
+	if utilizationFile != "" {
+		if p.Utilization, err = report.ParseFile(utilizationFile, report.ParseUtilization); err != nil {
+			return err
+		}
+	}
+	for _, f := range []struct {
+		title string
+		fn    string
+		parse func(io.Reader) ([]report.Violation, error)
+	}{
+		{"DRC", drcFile, report.ParseDRC},
+		{"Timing checks", checkTimingFile, report.ParseCheckTiming},
+		{"Methodology", methodologyFile, report.ParseMethodology},
+	} {
+		if f.fn == "" {
+			continue
+		}
+		vs, err := report.ParseFile(f.fn, f.parse)
+		if err != nil {
+			return err
+		}
+		p.Findings = append(p.Findings, Findings{Title: f.title, Violations: vs})
+	}
+
+	var messages []report.LogMessage
+	for _, l := range logs {
+		stage, fn, ok := strings.Cut(l, "=")
+		if !ok || stage == "" || fn == "" {
+			return fmt.Errorf("--log %q: want stage=file", l)
+		}
+		s := Stage{Name: stage}
+		if s.Commands, err = report.ParseFile(fn, report.ParseLogTimes); err != nil {
+			return err
+		}
+		for _, c := range s.Commands {
+			s.ElapsedSeconds += c.ElapsedSeconds
+			if c.PeakMemoryMB > s.PeakMemoryMB {
+				s.PeakMemoryMB = c.PeakMemoryMB
+			}
+		}
+		p.Stages = append(p.Stages, s)
+		p.ElapsedSeconds += s.ElapsedSeconds
+
+		ms, err := report.ParseFile(fn, report.ParseLogMessages)
+		if err != nil {
+			return err
+		}
+		for _, m := range ms {
+			if m.Severity != "INFO" {
+				messages = append(messages, m)
+			}
+		}
+	}
+	p.Warnings = report.CountMessages(messages)
+
+	t, err := template.New("report.html").Funcs(funcs).Parse(pageTemplate)
+	if err != nil {
+		return fmt.Errorf("parse page template: %w", err)
+	}
+	var out strings.Builder
+	if err := t.Execute(&out, p); err != nil {
+		return fmt.Errorf("execute page template: %w", err)
+	}
+	if outFile == "" {
+		_, err := io.WriteString(stdout, out.String())
+		return err
+	}
+	return os.WriteFile(outFile, []byte(out.String()), 0644)
+}
+
+func runCLI(osArgs []string, stdout, stderr io.Writer) error {
+	p := path.Base(osArgs[0])
+	log.SetPrefix(fmt.Sprintf("%v: ", p))
+
+	return run(osArgs[1:], stdout, stderr)
+}
+
+func main() {
+	if err := runCLI(os.Args, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+}
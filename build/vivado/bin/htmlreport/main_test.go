@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const timingSummary = `| Design Timing Summary
+| ---------------------
+
+    WNS(ns)  TNS(ns)  TNS Failing Endpoints  TNS Total Endpoints  WHS(ns)  THS(ns)  THS Failing Endpoints  THS Total Endpoints  WPWS(ns)  TPWS(ns)
+    -------  -------  ---------------------  -------------------  -------  -------  ---------------------  -------------------  --------  --------
+     -0.250   -1.000                      4                  100    0.050    0.000                      0                  100     1.750     0.000
+
+
+| Clock Summary
+| -------------
+
+Clock  Waveform(ns)   Period(ns)  Frequency(MHz)
+-----  ------------   ----------  --------------
+clk    {0.000 2.500}  5.000       200.000
+`
+
+const utilization = `1. Slice Logic
+--------------
+
++-----------------+-------+-----------+-------+
+|    Site Type    |  Used | Available | Util% |
++-----------------+-------+-----------+-------+
+| Slice LUTs      | 60000 |     63400 | 94.64 |
+| Slice Registers |  2000 |    126800 |  1.58 |
++-----------------+-------+-----------+-------+
+`
+
+const drc = `2. REPORT DETAILS
+-----------------
+NSTD-1#1 Critical Warning
+Unspecified I/O Standard
+Problem ports: led<0>.
+Related violations: <none>
+`
+
+const routeLog = `WARNING: [Route 35-328] Router estimated timing not met.
+CRITICAL WARNING: [Timing 38-282] The design failed to meet the timing requirements.
+WARNING: [Route 35-328] Router estimated timing not met.
+INFO: [Common 17-206] Exiting Vivado
+route_design: Time (s): cpu = 00:02:00 ; elapsed = 00:01:05 . Memory (MB): peak = 3000.000 ; gain = 10.000
+write_checkpoint: Time (s): cpu = 00:00:10 ; elapsed = 00:00:05 . Memory (MB): peak = 3100.000 ; gain = 1.000
+`
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"top.timing_summary.pnr.rpt": timingSummary,
+		"top.utilization.pnr.rpt":    utilization,
+		"top.drc.rpt":                drc,
+		"top.route.log":              routeLog,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file := func(name string) string {
+		return filepath.Join(tmpDir, name)
+	}
+	outFile := file("top.report.html")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		notWant []string
+		wantErr string
+	}{
+		{
+			name: "All reports",
+			args: []string{
+				"--name", "top",
+				"--timing-summary", file("top.timing_summary.pnr.rpt"),
+				"--utilization", file("top.utilization.pnr.rpt"),
+				"--drc", file("top.drc.rpt"),
+				"--log", "route=" + file("top.route.log"),
+				"--out", outFile,
+			},
+			want: []string{
+				"<title>top: Vivado build report</title>",
+				"Timing constraints are not met.",
+				`<td class="num bad">-0.250</td>`,
+				"<td>clk</td><td>{0.000 2.500}</td>",
+				`<div class="bad" style="width: 94.64%"></div>`,
+				"<td>NSTD-1</td>",
+				"Problem ports: led&lt;0&gt;.",
+				`<td>Timing 38-282</td><td class="bad">CRITICAL WARNING</td><td class="num">1</td>`,
+				`<td>Route 35-328</td><td class="warn">WARNING</td><td class="num">2</td>`,
+				`<tr><th>route</th><th></th><th class="num">0:01:10</th><th></th><th class="num">3100</th></tr>`,
+			},
+			notWant: []string{"Common 17-206", "Methodology"},
+		},
+		{
+			name:    "No reports",
+			args:    []string{"--name", "top", "--out", outFile},
+			want:    []string{"<h1>top</h1>"},
+			notWant: []string{`id="timing"`, `id="utilization"`, `id="findings"`, `id="runtime"`},
+		},
+		{
+			name:    "Bad log",
+			args:    []string{"--name", "top", "--log", file("top.route.log"), "--out", outFile},
+			wantErr: "want stage=file",
+		},
+		{
+			name:    "Missing report",
+			args:    []string{"--name", "top", "--drc", file("missing.rpt"), "--out", outFile},
+			wantErr: "missing.rpt",
+		},
+		{
+			name:    "Missing name",
+			args:    []string{"--out", outFile},
+			wantErr: "param --name is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := run(tt.args, &stdout, &stderr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			b, err := os.ReadFile(outFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(b)
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("run() page does not contain %q:\n%v", w, got)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("run() page contains %q:\n%v", w, got)
+				}
+			}
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"text/template"
@@ -15,6 +19,9 @@ type Args struct {
 	RunDockerFile string
 	GotoptFile    string
 	LtxFile       string
+	// LtxFilePath is the path to LtxFile at generation time. If set, the
+	// probes file is checked before the ILA read script is generated.
+	LtxFilePath string
 
 	VivadoVersion string
 }
@@ -25,6 +32,56 @@ func printEnv() {
 	}
 }
 
+// countProbes returns the number of debug probes described in the contents
+// of a probes (.ltx) file. Both the legacy XML and the JSON formats are
+// understood.
+func countProbes(b []byte) (int, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if b[0] == '{' {
+		var ltx struct {
+			DebugCores []json.RawMessage `json:"debug_cores"`
+		}
+		if err := json.Unmarshal(b, &ltx); err != nil {
+			return 0, fmt.Errorf("parse JSON: %w", err)
+		}
+		return len(ltx.DebugCores), nil
+	}
+	n := 0
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("parse XML: %w", err)
+		}
+		if e, ok := t.(xml.StartElement); ok && e.Name.Local == "probe" {
+			n++
+		}
+	}
+}
+
+// checkLtxFile refuses probes files that describe no probes, such as the
+// placeholders written when the design has no debug cores.
+func checkLtxFile(fn string) error {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return fmt.Errorf("could not read probes file: %v:\n\t\t%w", fn, err)
+	}
+	n, err := countProbes(b)
+	if err != nil {
+		return fmt.Errorf("could not parse probes file: %v:\n\t\t%w", fn, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("refusing an empty probes file, does the design have debug cores?: %v", fn)
+	}
+	return nil
+}
+
 func run(args Args) error {
 	printEnv()
 
@@ -47,6 +104,12 @@ func run(args Args) error {
 		return fmt.Errorf("param --vivado-version is required")
 	}
 
+	if args.LtxFilePath != "" {
+		if err := checkLtxFile(args.LtxFilePath); err != nil {
+			return err
+		}
+	}
+
 	tpl, err := template.ParseFiles(args.TemplateFile)
 	if err != nil {
 		return fmt.Errorf("could not open or parse template file: %v:\n\t\t%w", args.TemplateFile, err)
@@ -73,6 +136,7 @@ func runCLI(cmdArgs []string) error {
 	fs.StringVar(&args.RunDockerFile, "run-docker", "", "The script for running docker")
 	fs.StringVar(&args.GotoptFile, "gotopt2", "", "the gotopt2 binary to use")
 	fs.StringVar(&args.LtxFile, "ltxfile", "", "The probes .ltx file")
+	fs.StringVar(&args.LtxFilePath, "ltxfile-path", "", "The path to --ltxfile at generation time, used to check its contents")
 	fs.StringVar(&args.VivadoVersion, "vivado-version", "", "The Vivado version to use")
 
 	if err := fs.Parse(cmdArgs); err != nil {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -163,3 +164,64 @@ func TestRunCLI(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckLtxFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "empty file",
+			content: "",
+			wantErr: true,
+		},
+		{
+			name:    "placeholder probes",
+			content: `<?xml version="1.0" encoding="UTF-8"?><probes></probes>`,
+			wantErr: true,
+		},
+		{
+			name: "XML probes",
+			content: `<?xml version="1.0" encoding="UTF-8"?>
+<probeData Version="1" Minor="2">
+  <probeset name="EDA_PROBESET" active="false">
+    <probe type="ila" busType="net" source="netlist" spec="ILA_V2_RT"></probe>
+  </probeset>
+</probeData>`,
+		},
+		{
+			name:    "JSON without debug cores",
+			content: `{"debug_cores": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "JSON probes",
+			content: `{"debug_cores": [{"type": "ila", "name": "u_ila_0"}]}`,
+		},
+		{
+			name:    "garbage",
+			content: `<probes`,
+			wantErr: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := filepath.Join(tmpDir, fmt.Sprintf("probes%d.ltx", i))
+			if err := os.WriteFile(fn, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			err := checkLtxFile(fn)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkLtxFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := checkLtxFile(filepath.Join(tmpDir, "nonexistent.ltx")); err == nil {
+		t.Errorf("checkLtxFile() on a missing file: want error")
+	}
+}
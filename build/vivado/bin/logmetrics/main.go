@@ -0,0 +1,196 @@
+// logmetrics collects the run time and memory of every command in Vivado
+// logs, from the line that Vivado prints after each command:
+//
+//	route_design: Time (s): cpu = 00:01:02 ; elapsed = 00:00:41 . Memory (MB): peak = 2843.148 ; gain = 112.023
+//
+// It writes them as JSON, by step and command, and optionally as a
+// Prometheus textfile for the node exporter, so that the build cost of each
+// target can be tracked and flow time regressions caught.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"cp/build/vivado/lib/flags"
+	"cp/build/vivado/lib/report"
+)
+
+// Step is the log of a step of the flow, such as "synth" or "route".
+type Step struct {
+	Step string `json:"step"`
+	// CPUSeconds and ElapsedSeconds sum the times of the commands, and
+	// PeakMemoryMB is the most memory that any of them used.
+	CPUSeconds     float64              `json:"cpu_s"`
+	ElapsedSeconds float64              `json:"elapsed_s"`
+	PeakMemoryMB   float64              `json:"peak_memory_mb"`
+	Commands       []report.CommandTime `json:"commands"`
+}
+
+// Metrics is the JSON output.
+type Metrics struct {
+	Target         string  `json:"target,omitempty"`
+	CPUSeconds     float64 `json:"cpu_s"`
+	ElapsedSeconds float64 `json:"elapsed_s"`
+	PeakMemoryMB   float64 `json:"peak_memory_mb"`
+	Steps          []Step  `json:"steps"`
+}
+
+// labelValue escapes v as a Prometheus label value.
+func labelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// gauge is a Prometheus gauge, and its samples by label set.
+type gauge struct {
+	name, help string
+	samples    []string
+}
+
+func (g *gauge) add(labels string, v float64) {
+	g.samples = append(g.samples, fmt.Sprintf("%v{%v} %g", g.name, labels, v))
+}
+
+// writePrometheus writes m in the Prometheus text format. The commands that
+// run several times in a step, such as write_checkpoint, are summed.
+func writePrometheus(w io.Writer, m Metrics) error {
+	var (
+		cmdElapsed = gauge{name: "vivado_command_elapsed_seconds", help: "The elapsed time of a Vivado command in a step, summed over its runs."}
+		cmdCPU     = gauge{name: "vivado_command_cpu_seconds", help: "The CPU time of a Vivado command in a step, summed over its runs."}
+		cmdMemory  = gauge{name: "vivado_command_peak_memory_megabytes", help: "The peak memory of Vivado after a command in a step."}
+		elapsed    = gauge{name: "vivado_step_elapsed_seconds", help: "The elapsed time of the commands of a step."}
+		cpu        = gauge{name: "vivado_step_cpu_seconds", help: "The CPU time of the commands of a step."}
+		memory     = gauge{name: "vivado_step_peak_memory_megabytes", help: "The peak memory of Vivado in a step."}
+	)
+	target := labelValue(m.Target)
+	for _, s := range m.Steps {
+		labels := fmt.Sprintf(`target="%v",step="%v"`, target, labelValue(s.Step))
+		elapsed.add(labels, s.ElapsedSeconds)
+		cpu.add(labels, s.CPUSeconds)
+		memory.add(labels, s.PeakMemoryMB)
+
+		var cmds []report.CommandTime
+		index := map[string]int{}
+		for _, c := range s.Commands {
+			i, ok := index[c.Command]
+			if !ok {
+				i = len(cmds)
+				index[c.Command] = i
+				cmds = append(cmds, report.CommandTime{Command: c.Command})
+			}
+			cmds[i].ElapsedSeconds += c.ElapsedSeconds
+			cmds[i].CPUSeconds += c.CPUSeconds
+			if c.PeakMemoryMB > cmds[i].PeakMemoryMB {
+				cmds[i].PeakMemoryMB = c.PeakMemoryMB
+			}
+		}
+		for _, c := range cmds {
+			cmdLabels := fmt.Sprintf(`%v,command="%v"`, labels, labelValue(c.Command))
+			cmdElapsed.add(cmdLabels, c.ElapsedSeconds)
+			cmdCPU.add(cmdLabels, c.CPUSeconds)
+			cmdMemory.add(cmdLabels, c.PeakMemoryMB)
+		}
+	}
+	for _, g := range []gauge{elapsed, cpu, memory, cmdElapsed, cmdCPU, cmdMemory} {
+		if _, err := fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v gauge\n", g.name, g.help, g.name); err != nil {
+			return err
+		}
+		for _, s := range g.samples {
+			if _, err := fmt.Fprintln(w, s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("logmetrics", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var target, outFile, promFile string
+	var logs flags.Strings
+	fs.Var(&logs, "log", "A step log to read, as step=file, can be repeated")
+	fs.StringVar(&target, "target", "", "The Bazel target that the logs are of, e.g. //fpga:top")
+	fs.StringVar(&outFile, "out", "", "The JSON file to write, stdout if empty")
+	fs.StringVar(&promFile, "prometheus", "", "The Prometheus textfile to write, if set")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(logs) == 0 {
+		return fmt.Errorf("param --log is required")
+	}
+
+	m := Metrics{Target: target}
+	for _, l := range logs {
+		step, fn, ok := strings.Cut(l, "=")
+		if !ok || step == "" || fn == "" {
+			return fmt.Errorf("--log %q: want step=file", l)
+		}
+		cmds, err := report.ParseFile(fn, report.ParseLogTimes)
+		if err != nil {
+			return err
+		}
+		s := Step{Step: step, Commands: cmds}
+		if s.Commands == nil {
+			s.Commands = []report.CommandTime{}
+		}
+		for _, c := range cmds {
+			s.CPUSeconds += c.CPUSeconds
+			s.ElapsedSeconds += c.ElapsedSeconds
+			if c.PeakMemoryMB > s.PeakMemoryMB {
+				s.PeakMemoryMB = c.PeakMemoryMB
+			}
+		}
+		m.Steps = append(m.Steps, s)
+		m.CPUSeconds += s.CPUSeconds
+		m.ElapsedSeconds += s.ElapsedSeconds
+		if s.PeakMemoryMB > m.PeakMemoryMB {
+			m.PeakMemoryMB = s.PeakMemoryMB
+		}
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if outFile == "" {
+		if _, err := stdout.Write(b); err != nil {
+			return err
+		}
+	} else if err := os.WriteFile(outFile, b, 0644); err != nil {
+		return err
+	}
+
+	if promFile != "" {
+		var sb strings.Builder
+		if err := writePrometheus(&sb, m); err != nil {
+			return err
+		}
+		if err := os.WriteFile(promFile, []byte(sb.String()), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runCLI(osArgs []string, stdout, stderr io.Writer) error {
+	p := path.Base(osArgs[0])
+	log.SetPrefix(fmt.Sprintf("%v: ", p))
+
+	return run(osArgs[1:], stdout, stderr)
+}
+
+func main() {
+	if err := runCLI(os.Args, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+}
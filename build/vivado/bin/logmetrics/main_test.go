@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const synthLog = `synth_design: Time (s): cpu = 00:01:00 ; elapsed = 00:00:50 . Memory (MB): peak = 1500.000 ; gain = 500.000
+write_checkpoint: Time (s): cpu = 00:00:02 ; elapsed = 00:00:03 . Memory (MB): peak = 1600.000 ; gain = 100.000
+`
+
+const routeLog = `Phase 1 Build RT Design
+Time (s): cpu = 00:00:20 ; elapsed = 00:00:15 . Memory (MB): peak = 2600.000 ; gain = 0.000
+route_design: Time (s): cpu = 00:02:00 ; elapsed = 00:01:05 . Memory (MB): peak = 3000.000 ; gain = 10.000
+write_checkpoint: Time (s): cpu = 00:00:10 ; elapsed = 00:00:05 . Memory (MB): peak = 3100.000 ; gain = 1.000
+write_checkpoint: Time (s): cpu = 00:00:04 ; elapsed = 00:00:02 . Memory (MB): peak = 3050.000 ; gain = 0.000
+`
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	synthFile := filepath.Join(tmpDir, "top.log")
+	routeFile := filepath.Join(tmpDir, "top.route.log")
+	for fn, content := range map[string]string{synthFile: synthLog, routeFile: routeLog} {
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outFile := filepath.Join(tmpDir, "top.metrics.json")
+	promFile := filepath.Join(tmpDir, "top.metrics.prom")
+
+	err := run([]string{
+		"--target", "//fpga:top",
+		"--log", "synth=" + synthFile,
+		"--log", "route=" + routeFile,
+		"--out", outFile,
+		"--prometheus", promFile,
+	}, &bytes.Buffer{}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	b, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m Metrics
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if m.Target != "//fpga:top" || m.ElapsedSeconds != 125 || m.CPUSeconds != 196 || m.PeakMemoryMB != 3100 {
+		t.Errorf("metrics = %+v, want //fpga:top with 125s elapsed, 196s CPU and 3100MB peak", m)
+	}
+	if len(m.Steps) != 2 || m.Steps[0].Step != "synth" || len(m.Steps[1].Commands) != 3 || m.Steps[1].ElapsedSeconds != 72 {
+		t.Errorf("steps = %+v, want synth, and route with 3 commands and 72s elapsed", m.Steps)
+	}
+
+	b, err = os.ReadFile(promFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# TYPE vivado_step_elapsed_seconds gauge\n",
+		`vivado_step_elapsed_seconds{target="//fpga:top",step="route"} 72` + "\n",
+		`vivado_step_peak_memory_megabytes{target="//fpga:top",step="synth"} 1600` + "\n",
+		`vivado_command_elapsed_seconds{target="//fpga:top",step="route",command="write_checkpoint"} 7` + "\n",
+		`vivado_command_peak_memory_megabytes{target="//fpga:top",step="route",command="write_checkpoint"} 3100` + "\n",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("Prometheus textfile = %v, want it to contain %q", string(b), want)
+		}
+	}
+	if n := strings.Count(string(b), `command="write_checkpoint"} `); n != 6 {
+		t.Errorf("Prometheus textfile has %d write_checkpoint samples, want 6", n)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "No logs", wantErr: "param --log is required"},
+		{name: "Bad log", args: []string{"--log", "top.log"}, wantErr: "want step=file"},
+		{name: "Missing log", args: []string{"--log", "synth=missing.log"}, wantErr: "missing.log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := run(tt.args, &bytes.Buffer{}, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("run() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLabelValue(t *testing.T) {
+	if got, want := labelValue("a\"b\\c\nd"), `a\"b\\c\nd`; got != want {
+		t.Errorf("labelValue() = %q, want %q", got, want)
+	}
+}
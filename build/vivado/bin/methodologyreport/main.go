@@ -0,0 +1,123 @@
+// methodologyreport checks the timing constraints and design methodology of
+// a design, from the text output of Vivado's `check_timing -verbose` and
+// `report_methodology`.
+//
+// It writes the violations, and their counts by check ID such as "no_clock"
+// or "TIMING-17", as JSON. It fails if a critical violation is not waived.
+// Waivers are read from a JSON file, see report.Waiver; methodology
+// violations name their objects in the description only, so waive them by
+// description pattern. Waivers that match nothing are reported, so that they
+// can be removed.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"cp/build/vivado/lib/report"
+)
+
+// Result is the JSON output.
+type Result struct {
+	// Checks are the violation counts by check ID.
+	Checks     map[string]report.CheckSummary `json:"checks"`
+	Violations []report.Violation             `json:"violations"`
+	// Unwaived are the critical violations that fail the check.
+	Unwaived      []report.Violation `json:"unwaived"`
+	UnusedWaivers []report.Waiver    `json:"unused_waivers,omitempty"`
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("methodologyreport", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var checkTimingFile, methodologyFile, waiversFile, outFile string
+	fs.StringVar(&checkTimingFile, "check-timing-report", "", "The check_timing -verbose text report to read")
+	fs.StringVar(&methodologyFile, "methodology-report", "", "The report_methodology text report to read")
+	fs.StringVar(&waiversFile, "waivers", "", "The JSON waiver file to apply")
+	fs.StringVar(&outFile, "out", "", "The JSON file to write, stdout if empty")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if checkTimingFile == "" && methodologyFile == "" {
+		return fmt.Errorf("param --check-timing-report or --methodology-report is required")
+	}
+
+	var res Result
+	for _, r := range []struct {
+		fn    string
+		parse func(io.Reader) ([]report.Violation, error)
+	}{
+		{checkTimingFile, report.ParseCheckTiming},
+		{methodologyFile, report.ParseMethodology},
+	} {
+		if r.fn == "" {
+			continue
+		}
+		vs, err := report.ParseFile(r.fn, r.parse)
+		if err != nil {
+			return err
+		}
+		res.Violations = append(res.Violations, vs...)
+	}
+
+	if waiversFile != "" {
+		ws, err := report.ReadWaivers(waiversFile)
+		if err != nil {
+			return err
+		}
+		res.UnusedWaivers = ws.Apply(res.Violations)
+		for _, w := range res.UnusedWaivers {
+			fmt.Fprintf(stderr, "WARNING: %v: waiver matches nothing: %v\n", waiversFile, w)
+		}
+	}
+	res.Checks = report.Summarize(res.Violations)
+	res.Unwaived = report.Unwaived(res.Violations)
+
+	b, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if outFile == "" {
+		if _, err := stdout.Write(b); err != nil {
+			return err
+		}
+	} else if err := os.WriteFile(outFile, b, 0644); err != nil {
+		return err
+	}
+
+	if len(res.Unwaived) > 0 {
+		var lines []string
+		for _, v := range res.Unwaived {
+			l := fmt.Sprintf("  %v: %v", v.ID, v.Description)
+			if v.Endpoint != "" {
+				l += ": " + v.Endpoint
+			}
+			lines = append(lines, l)
+		}
+		return fmt.Errorf("%d unwaived critical timing and methodology violations:\n%v",
+			len(res.Unwaived), strings.Join(lines, "\n"))
+	}
+	return nil
+}
+
+func runCLI(osArgs []string, stdout, stderr io.Writer) error {
+	p := path.Base(osArgs[0])
+	log.SetPrefix(fmt.Sprintf("%v: ", p))
+
+	return run(osArgs[1:], stdout, stderr)
+}
+
+func main() {
+	if err := runCLI(os.Args, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+}
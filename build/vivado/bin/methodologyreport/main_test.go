@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const checkTimingReport = `1. checking no_clock (1)
+------------------------
+ There are 1 register/latch pins with no clock driven by root clock pin: u_cnt/q_reg[0]/C (HIGH)
+
+u_cnt/q_reg[0]/C
+
+
+2. checking no_input_delay (1)
+------------------------------
+ There are 1 input ports with no input delay specified. (HIGH)
+
+btn
+`
+
+const methodologyReport = `2. REPORT DETAILS
+-----------------
+TIMING-18#1 Warning
+Missing input or output delay
+An output delay is missing on led[0] relative to the rising and/or
+falling clock edge(s) of clk.
+Related violations: <none>
+`
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	checkTimingFile := filepath.Join(tmpDir, "top.check_timing.rpt")
+	methodologyFile := filepath.Join(tmpDir, "top.methodology.rpt")
+	waiveBtn := filepath.Join(tmpDir, "btn.json")
+	waiveAll := filepath.Join(tmpDir, "all.json")
+	for fn, content := range map[string]string{
+		checkTimingFile: checkTimingReport,
+		methodologyFile: methodologyReport,
+		waiveBtn: `{"waivers": [
+			{"id": "no_input_delay", "endpoint": "btn", "reason": "Debounced asynchronous input"}
+		]}`,
+		waiveAll: `{"waivers": [
+			{"id": "no_input_delay", "endpoint": "btn", "reason": "Debounced asynchronous input"},
+			{"id": "no_clock", "endpoint": "u_cnt/*", "reason": "Counter is clocked by a gated clock in simulation only"},
+			{"id": "TIMING-17", "reason": "Stale"}
+		]}`,
+	} {
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantErr    string
+		wantChecks map[string]int
+		wantUnused int
+	}{
+		{
+			name:       "Unwaived",
+			args:       []string{"--check-timing-report", checkTimingFile, "--methodology-report", methodologyFile},
+			wantErr:    "2 unwaived critical timing and methodology violations",
+			wantChecks: map[string]int{"no_clock": 0, "no_input_delay": 0, "TIMING-18": 0},
+		},
+		{
+			name:       "Partially waived",
+			args:       []string{"--check-timing-report", checkTimingFile, "--waivers", waiveBtn},
+			wantErr:    "no_clock: There are 1 register/latch pins",
+			wantChecks: map[string]int{"no_clock": 0, "no_input_delay": 1},
+		},
+		{
+			name:       "All waived",
+			args:       []string{"--check-timing-report", checkTimingFile, "--methodology-report", methodologyFile, "--waivers", waiveAll},
+			wantChecks: map[string]int{"no_clock": 1, "no_input_delay": 1, "TIMING-18": 0},
+			wantUnused: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			outFile := filepath.Join(t.TempDir(), "methodology.json")
+			var stdout, stderr bytes.Buffer
+			err := run(append(tc.args, "--out", outFile), &stdout, &stderr)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("run() error = %v, want %q", err, tc.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+
+			b, err := os.ReadFile(outFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got Result
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatalf("invalid JSON %s: %v", b, err)
+			}
+			if len(got.Checks) != len(tc.wantChecks) {
+				t.Errorf("run() checks = %+v, want %v", got.Checks, tc.wantChecks)
+			}
+			for id, waived := range tc.wantChecks {
+				if c := got.Checks[id]; c.Count != 1 || c.Waived != waived {
+					t.Errorf("run() check %v = %+v, want 1 with %d waived", id, c, waived)
+				}
+			}
+			if len(got.UnusedWaivers) != tc.wantUnused {
+				t.Errorf("run() unused waivers = %+v, want %d", got.UnusedWaivers, tc.wantUnused)
+			}
+		})
+	}
+}
+
+func TestRunMissingReport(t *testing.T) {
+	if err := run(nil, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Errorf("run() succeeded, want error")
+	}
+}
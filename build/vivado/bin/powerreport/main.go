@@ -0,0 +1,83 @@
+// powerreport converts the text output of Vivado's `report_power` to JSON,
+// with the total on-chip, dynamic and static power, the junction temperature
+// and the current drawn from each supply rail.
+//
+// With --max-total-power or --max-junction-temp, it fails if the design
+// exceeds the power or thermal budget, e.g. of a passively cooled enclosure.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"cp/build/vivado/lib/report"
+)
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("powerreport", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var reportFile, outFile string
+	var maxTotal, maxJunction float64
+	fs.StringVar(&reportFile, "report", "", "The report_power text report to read")
+	fs.StringVar(&outFile, "out", "", "The JSON file to write, stdout if empty")
+	fs.Float64Var(&maxTotal, "max-total-power", 0, "Fail if the total on-chip power in W exceeds this, 0 to not check")
+	fs.Float64Var(&maxJunction, "max-junction-temp", 0, "Fail if the junction temperature in C exceeds this, 0 to not check")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if reportFile == "" {
+		return fmt.Errorf("param --report is required")
+	}
+	if maxTotal < 0 || maxJunction < 0 {
+		return fmt.Errorf("params --max-total-power and --max-junction-temp must not be negative")
+	}
+
+	f, err := os.Open(reportFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	p, err := report.ParsePower(f)
+	if err != nil {
+		return fmt.Errorf("parse %v: %w", reportFile, err)
+	}
+
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if outFile == "" {
+		if _, err := stdout.Write(b); err != nil {
+			return err
+		}
+	} else if err := os.WriteFile(outFile, b, 0644); err != nil {
+		return err
+	}
+
+	if vs := p.Check(maxTotal, maxJunction); len(vs) > 0 {
+		return fmt.Errorf("%v: %v", reportFile, strings.Join(vs, "; "))
+	}
+	return nil
+}
+
+func runCLI(osArgs []string, stdout, stderr io.Writer) error {
+	p := path.Base(osArgs[0])
+	log.SetPrefix(fmt.Sprintf("%v: ", p))
+
+	return run(osArgs[1:], stdout, stderr)
+}
+
+func main() {
+	if err := runCLI(os.Args, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const powerReport = `1. Summary
+----------
+
++--------------------------+--------------+
+| Total On-Chip Power (W)  | 2.150        |
+| Dynamic (W)              | 1.900        |
+| Device Static (W)        | 0.250        |
+| Max Ambient (C)          | 79.6         |
+| Junction Temperature (C) | 30.4         |
+| Confidence Level         | Medium       |
++--------------------------+--------------+
+
+
+1.2 Power Supply Summary
+------------------------
+
++-----------+-------------+-----------+-------------+------------+
+| Source    | Voltage (V) | Total (A) | Dynamic (A) | Static (A) |
++-----------+-------------+-----------+-------------+------------+
+| Vccint    |       0.850 |     1.800 |       1.700 |      0.100 |
++-----------+-------------+-----------+-------------+------------+
+`
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	rptFile := filepath.Join(tmpDir, "top.power.rpt")
+	if err := os.WriteFile(rptFile, []byte(powerReport), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name: "No limits",
+			args: []string{"--report", rptFile},
+		},
+		{
+			name: "Within budget",
+			args: []string{"--report", rptFile, "--max-total-power", "2.5", "--max-junction-temp", "85"},
+		},
+		{
+			name:    "Over power budget",
+			args:    []string{"--report", rptFile, "--max-total-power", "2"},
+			wantErr: "total on-chip power 2.150 W exceeds the budget of 2.000 W",
+		},
+		{
+			name:    "Over thermal limit",
+			args:    []string{"--report", rptFile, "--max-junction-temp", "30"},
+			wantErr: "junction temperature 30.4 C exceeds the limit of 30.0 C",
+		},
+		{
+			name:    "Missing report",
+			args:    []string{},
+			wantErr: "param --report is required",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			outFile := filepath.Join(t.TempDir(), "power.json")
+			var stdout, stderr bytes.Buffer
+			err := run(append(tc.args, "--out", outFile), &stdout, &stderr)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("run() error = %v, want %q", err, tc.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if len(tc.args) == 0 {
+				return
+			}
+
+			// The JSON is written even if the budget is exceeded.
+			b, err := os.ReadFile(outFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got struct {
+				Total float64 `json:"total_on_chip_w"`
+				Rails []struct {
+					Name string `json:"name"`
+				} `json:"rails"`
+			}
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatalf("invalid JSON %s: %v", b, err)
+			}
+			if got.Total != 2.15 || len(got.Rails) != 1 || got.Rails[0].Name != "Vccint" {
+				t.Errorf("run() wrote %s", b)
+			}
+		})
+	}
+}
@@ -65,12 +65,7 @@ if [[ "${_yaml_config}" == "" ]]; then
     _yaml_config="$(rlocation rules_vivado/build/vivado/bin/proggen/flags.yaml)"
 fi
 
-readonly _mcsfile="{{ .McsFile }}"
-if [[ ! -f "${_mcsfile}" && ! -L "${_mcsfile}" ]]; then
-    echo "flash image (.mcs) not found at ${_mcsfile}"
-    ls -lR
-    exit 1
-fi
+_mcsfile="{{ .McsFile }}"
 
 readonly _flash_part="{{ .FlashPart }}"
 if [[ "${_flash_part}" == "" ]]; then
@@ -86,6 +81,38 @@ fi
 
 eval "${GOTOPT2_OUTPUT}"
 
+{{- if .LayoutImages }}
+
+# The flash image follows a MultiBoot layout, and --image selects a single
+# image of it to program. Only the sectors of that image are erased.
+case "${gotopt2_image}" in
+    "")
+        ;;
+{{- range .LayoutImages }}
+    "{{ .Name }}")
+        _mcsfile="{{ .McsFile }}"
+        log::info "Programming only layout image {{ .Name }} at {{ .Address }}-{{ .End }}"
+        ;;
+{{- end }}
+    *)
+        log::error "unknown --image: ${gotopt2_image}, want one of: {{ .LayoutImageNames }}"
+        exit 1
+        ;;
+esac
+{{- else }}
+
+if [[ "${gotopt2_image}" != "" ]]; then
+    log::error "--image needs a flash image built from a MultiBoot layout"
+    exit 1
+fi
+{{- end }}
+readonly _mcsfile
+if [[ ! -f "${_mcsfile}" && ! -L "${_mcsfile}" ]]; then
+    echo "flash image (.mcs) not found at ${_mcsfile}"
+    ls -lR
+    exit 1
+fi
+
 if [[ "${gotopt2_hostport}" == "" ]]; then
     echo "--hostport is required, often the value should be 'localhost:3122'"
     exit 1
@@ -96,13 +123,89 @@ if [[ "${gotopt2_device}" == "" ]]; then
     exit 1
 fi
 
+# Resolves a path given on the command line. `bazel run` runs this script in
+# its runfiles directory, so relative paths are taken from where the user ran
+# the command.
+function _local_path() {
+    if [[ "${1}" == /* ]]; then
+        echo "${1}"
+    else
+        echo "${BUILD_WORKING_DIRECTORY:-${PWD}}/${1}"
+    fi
+}
+
+# The programming mode selects what program_hw_cfgmem does. The address range
+# of erase and blank-check is the whole flash, unless --image selects a single
+# layout image.
+readonly _mode="${gotopt2_mode:-program}"
+_files="${_mcsfile}"
+_address_range="use_file"
+_erase=0
+_cfg_program=0
+_verify=0
+_blank_check=0
+case "${_mode}" in
+    program)
+        _erase=1
+        _cfg_program=1
+        _verify=1
+        ;;
+    erase)
+        _erase=1
+        ;;
+    blank-check)
+        _blank_check=1
+        ;;
+    verify)
+        _verify=1
+        if [[ "${gotopt2_verify_file}" != "" ]]; then
+            # Vivado only sees the files below the current directory.
+            _verify_file="$(_local_path "${gotopt2_verify_file}")"
+            if [[ ! -f "${_verify_file}" ]]; then
+                log::error "--verify_file not found: ${_verify_file}"
+                exit 1
+            fi
+            _files="verify_image.${_verify_file##*.}"
+            cp "${_verify_file}" "${_files}"
+        fi
+        ;;
+    readback)
+        if [[ "${gotopt2_readback_file}" == "" || "${gotopt2_readback_size}" == "" ]]; then
+            log::error "--mode=readback needs --readback_file and --readback_size"
+            exit 1
+        fi
+        ;;
+    *)
+        log::error "unknown --mode: ${_mode}, want one of: program, erase, blank-check, verify, readback"
+        exit 1
+        ;;
+esac
+_done_hint=""
+if [[ "${_mode}" == "program" ]]; then
+    _done_hint=" Power-cycle the board to load the design from flash."
+fi
+readonly _done_hint
+if [[ "${_mode}" == "erase" || "${_mode}" == "blank-check" ]] && [[ "${gotopt2_image}" == "" ]]; then
+    _files=""
+    _address_range="entire_device"
+fi
+readonly _files _address_range _erase _cfg_program _verify _blank_check
+
+readonly _readback_tmp_file="readback.${gotopt2_readback_file##*.}"
+_readback_format="bin"
+if [[ "${gotopt2_readback_file}" == *.mcs ]]; then
+    _readback_format="mcs"
+fi
+readonly _readback_format
+
 readonly _tcl_script_file="prog_flash.tcl"
 # The root of the Vivado installation in the container's filesystem.
 readonly _vivado_version="{{ .VivadoVersion }}"
 readonly _vivado_root="/opt/Xilinx/${_vivado_version}/Vivado"
 
 log::debug "Creating script file: ${_tcl_script_file}"
-log::debug "Using flash image:    ${_mcsfile}"
+log::debug "Using mode:           ${_mode}"
+log::debug "Using flash image:    ${_files}"
 log::debug "Using flash part:     ${_flash_part}"
 log::debug "Using PWD:            ${PWD}"
 
@@ -140,26 +243,43 @@ puts "INFO: Creating configuration memory for flash part: $_flash_part"
 create_hw_cfgmem -hw_device \$Device [lindex [get_cfgmem_parts {$_flash_part}] 0]
 set Cfgmem [get_property PROGRAM.HW_CFGMEM \$Device]
 
-set_property PROGRAM.FILES [list "$_mcsfile"] \$Cfgmem
-set_property PROGRAM.ADDRESS_RANGE {use_file} \$Cfgmem
-set_property PROGRAM.BLANK_CHECK 0 \$Cfgmem
-set_property PROGRAM.ERASE 1 \$Cfgmem
-set_property PROGRAM.CFG_PROGRAM 1 \$Cfgmem
-set_property PROGRAM.VERIFY 1 \$Cfgmem
+set_property PROGRAM.FILES [list $_files] \$Cfgmem
+set_property PROGRAM.ADDRESS_RANGE {$_address_range} \$Cfgmem
+set_property PROGRAM.BLANK_CHECK $_blank_check \$Cfgmem
+set_property PROGRAM.ERASE $_erase \$Cfgmem
+set_property PROGRAM.CFG_PROGRAM $_cfg_program \$Cfgmem
+set_property PROGRAM.VERIFY $_verify \$Cfgmem
 set_property PROGRAM.CHECKSUM 0 \$Cfgmem
 
 puts "INFO: Loading configuration-memory programming bridge into the FPGA"
 create_hw_bitstream -hw_device \$Device [get_property PROGRAM.HW_CFGMEM_BITFILE \$Device]
 program_hw_devices \$Device
 refresh_hw_device \$Device
+EOF
+
+if [[ "${_mode}" == "readback" ]]; then
+    cat <<EOF >> "${_tcl_script_file}" || log::error "Could not write the file: ${_tcl_script_file}"
+
+puts "INFO: Reading back ${gotopt2_readback_size} bytes of configuration flash at ${gotopt2_readback_address}"
+readback_hw_cfgmem -force -hw_cfgmem \$Cfgmem -format $_readback_format \\
+    -offset ${gotopt2_readback_address} -datacount ${gotopt2_readback_size} \\
+    -file "$_readback_tmp_file"
+puts "INFO: DONE reading back configuration flash."
+EOF
+else
+    cat <<EOF >> "${_tcl_script_file}" || log::error "Could not write the file: ${_tcl_script_file}"
 
-puts "INFO: Programming configuration flash with: $_mcsfile"
+puts "INFO: Running ${_mode} on configuration flash: $_address_range $_files"
 program_hw_cfgmem -hw_cfgmem \$Cfgmem
-puts "INFO: DONE programming configuration flash."
+puts "INFO: DONE ${_mode} on configuration flash."
+EOF
+fi
+
+cat <<EOF >> "${_tcl_script_file}" || log::error "Could not write the file: ${_tcl_script_file}"
 
 puts "INFO: Refresh."
 refresh_hw_device \$Device
-puts "INFO: Done. Power-cycle the board to load the design from flash."
+puts "INFO: Done.${_done_hint}"
 EOF
 
 env RUNFILES_DIR="$PWD/.." \
@@ -176,3 +296,13 @@ env RUNFILES_DIR="$PWD/.." \
     -source "/work/${_tcl_script_file}" | log::prefix "[vivado] " \
     && log::info "OK" \
     || log::error "The flash programming command failed."
+
+if [[ "${_mode}" == "readback" ]]; then
+    if [[ ! -f "${_readback_tmp_file}" ]]; then
+        log::error "Vivado did not write the readback file"
+        exit 1
+    fi
+    readonly _readback_file="$(_local_path "${gotopt2_readback_file}")"
+    cp "${_readback_tmp_file}" "${_readback_file}"
+    log::info "Wrote readback to: ${_readback_file}"
+fi
@@ -1,14 +1,29 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
+
+	"cp/build/vivado/lib/bitstream"
+	"cp/build/vivado/lib/buildstamp"
+	"cp/build/vivado/lib/flags"
+	"cp/build/vivado/lib/flashlayout"
 )
 
+// dummyBitstreamPrefix is the content that the place and route script writes
+// in place of a bitstream when it is allowed to bypass bitstream generation.
+const dummyBitstreamPrefix = "Dummy bitstream"
+
+// syncWord is the configuration sync word, present in every real bitstream.
+var syncWord = []byte{0xaa, 0x99, 0x55, 0x66}
+
 type Args struct {
 	Outfile      string
 	TemplateFile string
@@ -16,6 +31,22 @@ type Args struct {
 	RunDockerFile string
 	GotoptFile    string
 	BitFile       string
+	// BitFilePath is the path to BitFile at generation time. If set, the
+	// bitstream is checked before the programming script is generated.
+	BitFilePath string
+	// BitHeader is the header of the bitstream at BitFilePath, if it has one.
+	// It is used to check the part of the device before programming.
+	BitHeader *bitstream.Header
+	// BitStamp is the build stamp of the bitstream at BitFilePath, if it
+	// was built with stamping enabled.
+	BitStamp *buildstamp.Stamp
+	// BitstampBinary decodes the build stamp read back from a device in
+	// the identify mode.
+	BitstampBinary string
+	// Partial is set if BitFile is the partial bitstream of a reconfigurable
+	// partition. It is only programmed onto a device that is already
+	// configured, with a full bitstream of the same DFX design.
+	Partial bool
 
 	// Flash (cfgmem) programming mode. When McsFile is set, the generator
 	// emits a script that programs the device's non-volatile configuration
@@ -23,6 +54,16 @@ type Args struct {
 	McsFile        string
 	FlashPart      string
 	FlashInterface string
+	// LayoutFilePath is the path to the MultiBoot flash layout of McsFile at
+	// generation time. If set, the script can program single images of the
+	// layout, e.g. only the update image in the field.
+	LayoutFilePath string
+	// LayoutImageFiles are the flash images of single layout images, each
+	// in the form name=file.
+	LayoutImageFiles []string
+	// LayoutImages are the layout images that have flash images, in address
+	// order.
+	LayoutImages []LayoutImage
 
 	ProgRunnerArgs   string
 	ProgRunnerBinary string
@@ -30,12 +71,76 @@ type Args struct {
 	VivadoVersion string
 }
 
+// LayoutImage is a flash image holding a single image of a flash layout.
+type LayoutImage struct {
+	Name    string
+	McsFile string
+	Address flashlayout.Address
+	End     flashlayout.Address
+}
+
+// LayoutImageNames returns the names of the layout images, for messages.
+func (a Args) LayoutImageNames() string {
+	var ns []string
+	for _, im := range a.LayoutImages {
+		ns = append(ns, im.Name)
+	}
+	return strings.Join(ns, ", ")
+}
+
+// layoutImages matches the layout image files to the images of the layout at
+// fn.
+func layoutImages(fn string, files []string) ([]LayoutImage, error) {
+	l, err := flashlayout.Read(fn)
+	if err != nil {
+		return nil, fmt.Errorf("could not read flash layout:\n\t\t%w", err)
+	}
+	byName := map[string]string{}
+	for _, f := range files {
+		name, file, ok := strings.Cut(f, "=")
+		if !ok || file == "" {
+			return nil, fmt.Errorf("invalid --layout-image, want name=file, got: %q", f)
+		}
+		if _, err := l.Image(name); err != nil {
+			return nil, fmt.Errorf("invalid --layout-image: %w", err)
+		}
+		byName[name] = file
+	}
+	var ims []LayoutImage
+	for _, im := range l.Images {
+		if file, ok := byName[im.Name]; ok {
+			ims = append(ims, LayoutImage{Name: im.Name, McsFile: file, Address: im.Address, End: im.End()})
+		}
+	}
+	return ims, nil
+}
+
 func printEnv() {
 	for _, e := range os.Environ() {
 		log.Printf("env: %v", e)
 	}
 }
 
+// checkBitFile refuses bitstreams that can not be programmed into a device,
+// such as the placeholders written when bitstream generation was bypassed.
+func checkBitFile(fn string) error {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return fmt.Errorf("could not read bitstream: %v:\n\t\t%w", fn, err)
+	}
+	if len(b) == 0 {
+		return fmt.Errorf("bitstream is empty: %v", fn)
+	}
+	if bytes.HasPrefix(b, []byte(dummyBitstreamPrefix)) {
+		return fmt.Errorf("refusing to program a dummy bitstream: %v:\n\t\t%s",
+			fn, bytes.TrimSpace(b))
+	}
+	if !bytes.Contains(b, syncWord) {
+		return fmt.Errorf("not a bitstream, no sync word found: %v", fn)
+	}
+	return nil
+}
+
 func run(args Args) error {
 	printEnv()
 
@@ -58,6 +163,48 @@ func run(args Args) error {
 		return fmt.Errorf("param --vivado-version is required")
 	}
 
+	if args.BitFilePath != "" {
+		if err := checkBitFile(args.BitFilePath); err != nil {
+			return err
+		}
+		h, d, err := bitstream.ReadFile(args.BitFilePath)
+		switch {
+		case errors.Is(err, bitstream.ErrNoHeader):
+			log.Printf("warning: bitstream has no header, can not check the device part: %v", args.BitFilePath)
+		case err != nil:
+			return fmt.Errorf("could not read bitstream header: %v:\n\t\t%w", args.BitFilePath, err)
+		default:
+			log.Printf("bitstream: design: %v, part: %v, tool version: %v, built: %v %v",
+				h.DesignName, h.Part, h.ToolVersion, h.Date, h.Time)
+			args.BitHeader = h
+			if h.Partial != args.Partial {
+				if h.Partial {
+					return fmt.Errorf("bitstream is partial, but --partial is not set: %v", args.BitFilePath)
+				}
+				return fmt.Errorf("--partial is set, but the bitstream is not partial: %v", args.BitFilePath)
+			}
+			stamp, err := buildstamp.FromBitstream(h, d)
+			switch {
+			case errors.Is(err, buildstamp.ErrNotStamped):
+			case err != nil:
+				log.Printf("warning: could not read the build stamp: %v: %v", args.BitFilePath, err)
+			default:
+				log.Printf("bitstream: build stamp: %v", stamp)
+				args.BitStamp = &stamp
+			}
+		}
+	}
+
+	if args.LayoutFilePath != "" {
+		ims, err := layoutImages(args.LayoutFilePath, args.LayoutImageFiles)
+		if err != nil {
+			return err
+		}
+		args.LayoutImages = ims
+	} else if len(args.LayoutImageFiles) > 0 {
+		return fmt.Errorf("param --layout-file-path is required with --layout-image")
+	}
+
 	tpl, err := template.ParseFiles(args.TemplateFile)
 	if err != nil {
 		return fmt.Errorf("could not open or parse template file: %v:\n\t\t%w", args.TemplateFile, err)
@@ -84,11 +231,16 @@ func runCLI(cmdArgs []string) error {
 	fs.StringVar(&args.RunDockerFile, "run-docker", "", "The script for running docker")
 	fs.StringVar(&args.GotoptFile, "gotopt2", "", "the gotopt2 binary to use")
 	fs.StringVar(&args.BitFile, "bitfile", "", "")
+	fs.StringVar(&args.BitFilePath, "bitfile-path", "", "The path to --bitfile at generation time, used to check its contents")
 	fs.StringVar(&args.McsFile, "mcs-file", "", "The flash image (.mcs/.bin) to program into configuration flash")
 	fs.StringVar(&args.FlashPart, "flash-part", "", "The Vivado cfgmem part name of the target flash device")
 	fs.StringVar(&args.FlashInterface, "flash-interface", "", "The flash programming interface, e.g. SPIx4")
+	fs.StringVar(&args.LayoutFilePath, "layout-file-path", "", "The MultiBoot flash layout of --mcs-file, at generation time")
+	fs.Var((*flags.Strings)(&args.LayoutImageFiles), "layout-image", "each is: name=file, the flash image of a single layout image")
 	fs.StringVar(&args.ProgRunnerArgs, "prog-runner-args", "", "the arguments to invoke the runner with")
 	fs.StringVar(&args.ProgRunnerBinary, "prog-runner-binary", "", "The program runner binary")
+	fs.StringVar(&args.BitstampBinary, "bitstamp", "", "The binary that decodes build stamps")
+	fs.BoolVar(&args.Partial, "partial", false, "--bitfile is a partial bitstream, to program onto a configured device")
 	fs.StringVar(&args.VivadoVersion, "vivado-version", "", "The Vivado version to use")
 
 	if err := fs.Parse(cmdArgs); err != nil {
@@ -87,6 +87,17 @@ if [[ "${gotopt2_device}" == "" ]]; then
     exit 1
 fi
 
+readonly _mode="${gotopt2_mode:-program}"
+case "${_mode}" in
+    program|identify) ;;
+    *)
+        log::error "--mode must be program or identify, got: ${_mode}"
+        exit 1
+        ;;
+esac
+readonly _identify_file="identify.txt"
+rm -f "${_identify_file}"
+
 readonly _tcl_script_file="prog.tcl"
 # The root of the Vivado installation in the container's filesystem.
 readonly _vivado_version="{{ .VivadoVersion }}"
@@ -94,8 +105,19 @@ readonly _vivado_root="/opt/Xilinx/${_vivado_version}/Vivado"
 
 log::debug "Creating script file: ${_tcl_script_file}"
 log::debug "Using bitfile:        ${_bitfile}"
+{{- with .BitHeader }}
+log::info "Bitstream design:     {{ .DesignName }}"
+log::info "Bitstream part:       {{ .Part }}"
+log::info "Bitstream tool:       {{ .ToolVersion }}"
+log::info "Bitstream built:      {{ .Date }} {{ .Time }}"
+{{- end }}
+{{- with .BitStamp }}
+log::info "Bitstream stamp:      {{ . }}"
+{{- end }}
 log::debug "Using PWD:            ${PWD}"
 
+readonly _bitstamp_binary="{{ .BitstampBinary }}"
+
 # Now, run the daemon.
 readonly _prog_runner_binary="{{ .ProgRunnerBinary }}"
 if [[ "${_prog_runner_binary}" != "" ]]; then
@@ -127,6 +149,43 @@ puts "INFO: Done connecting."
 set Device [lindex [get_hw_devices] 0]
 current_hw_device \$Device
 refresh_hw_device -update_hw_probes false \$Device
+EOF
+
+if [[ "${_mode}" == "identify" ]]; then
+cat <<EOF >> "${_tcl_script_file}"
+
+# Read back the values that a stamped bitstream carries its build stamp in.
+set IdFd [open ${_identify_file} w]
+puts \$IdFd "USERID [get_property REGISTER.USERCODE \$Device]"
+puts \$IdFd "USR_ACCESS [get_property REGISTER.USR_ACCESS \$Device]"
+close \$IdFd
+puts "INFO: Done."
+EOF
+else
+cat <<EOF >> "${_tcl_script_file}"
+{{- with .BitHeader }}
+
+# Refuse to program a bitstream built for a different part. The device part
+# is only known here. Its family prefix is stripped, as in
+# bitstream.DevicePrefix, to compare it with the part in the bitstream header.
+set DevicePart [string tolower [get_property PART \$Device]]
+regsub {^x[a-z]} \$DevicePart {} DevicePart
+if { [string first \$DevicePart "{{ .Part }}"] != 0 } {
+    puts "ERROR: The bitstream is for part {{ .Part }}, but the device is [get_property PART \$Device]"
+    exit 1
+}
+puts "INFO: Bitstream part {{ .Part }} matches device [get_property PART \$Device]"
+{{- end }}
+{{- if .Partial }}
+
+# A partial bitstream only reconfigures its partition, so the device must
+# already run a full bitstream of the same DFX design.
+if { [get_property REGISTER.CONFIG_STATUS.BIT14_DONE_PIN \$Device] != 1 } {
+    puts "ERROR: The device is not configured, program the full bitstream first"
+    exit 1
+}
+puts "INFO: Programming a partial bitstream onto the configured device"
+{{- end }}
 
 set_property PROGRAM.FILE $_bitfile \$Device
 
@@ -140,6 +199,7 @@ puts "INFO: Refresh."
 refresh_hw_device \$Device
 puts "INFO: Done."
 EOF
+fi
 
 env RUNFILES_DIR="$PWD/.." \
 "${_run_docker}" \
@@ -154,5 +214,25 @@ env RUNFILES_DIR="$PWD/.." \
     -notrace -mode batch \
     -source "/work/${_tcl_script_file}" | log::prefix "[vivado] " \
     && log::info "OK" \
-    || log::error "The programming command failed."
+    || { log::error "The programming command failed."; exit 1; }
 
+if [[ "${_mode}" == "identify" ]]; then
+    if [[ ! -f "${_identify_file}" ]]; then
+        log::error "Could not read the device registers."
+        exit 1
+    fi
+    _userid="$(sed -n 's/^USERID //p' "${_identify_file}")"
+    _usr_access="$(sed -n 's/^USR_ACCESS //p' "${_identify_file}")"
+    log::info "Device USERID:        ${_userid}"
+    log::info "Device USR_ACCESS:    ${_usr_access}"
+    if [[ ! -x "${_bitstamp_binary}" ]]; then
+        log::error "build stamp decoder not found: ${_bitstamp_binary}"
+        exit 1
+    fi
+    "${_bitstamp_binary}" --userid="${_userid}" --usr-access="${_usr_access}" \
+        | log::prefix "[device] " \
+        || log::warn "The device is not running a stamped bitstream."
+{{- with .BitStamp }}
+    log::info "Built bitstream:      {{ . }}"
+{{- end }}
+fi
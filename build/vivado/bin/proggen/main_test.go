@@ -1,8 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -204,3 +207,384 @@ func TestRunCLI(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckBitFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content []byte
+		wantErr bool
+	}{
+		{
+			name:    "empty file",
+			content: nil,
+			wantErr: true,
+		},
+		{
+			name:    "dummy bitstream",
+			content: []byte("Dummy bitstream - Bypassed due to licensing restrictions or DRC.\n"),
+			wantErr: true,
+		},
+		{
+			name:    "no sync word",
+			content: []byte{0x00, 0x09, 0x0f, 0xf0, 0xff, 0xff},
+			wantErr: true,
+		},
+		{
+			name:    "bitstream",
+			content: []byte{0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0xbb, 0x11, 0x22, 0x00, 0x44, 0xaa, 0x99, 0x55, 0x66},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := filepath.Join(tmpDir, fmt.Sprintf("test%d.bit", i))
+			if err := os.WriteFile(fn, tt.content, 0644); err != nil {
+				t.Fatal(err)
+			}
+			err := checkBitFile(fn)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkBitFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunRefusesDummyBitstream(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	templatePath := filepath.Join(tmpDir, "main_script.tpl.sh")
+	if err := os.WriteFile(templatePath, []byte("BitFile: {{.BitFile}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bitPath := filepath.Join(tmpDir, "test.bit")
+	if err := os.WriteFile(bitPath, []byte("Dummy bitstream - Bypassed."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := run(Args{
+		BitFile:       "test.bit",
+		BitFilePath:   bitPath,
+		RunDockerFile: "docker.sh",
+		GotoptFile:    "gotopt2",
+		Outfile:       filepath.Join(tmpDir, "out.sh"),
+		TemplateFile:  templatePath,
+		VivadoVersion: "2025.1",
+	})
+	if err == nil || !strings.Contains(err.Error(), "dummy bitstream") {
+		t.Errorf("run() error = %v, want dummy bitstream error", err)
+	}
+}
+
+func TestRunBitHeader(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	templatePath := filepath.Join(tmpDir, "main_script.tpl.sh")
+	tpl := "{{ with .BitHeader }}{{ .DesignName }} {{ .Part }} {{ .ToolVersion }}{{ end }}"
+	if err := os.WriteFile(templatePath, []byte(tpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A .bit header followed by a minimal configuration data stream.
+	var bit []byte
+	bit = append(bit, 0x00, 0x09, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x00, 0x00, 0x01)
+	for _, f := range []string{"atop;Version=2025.2", "b7a200tfbg484", "c2025/11/20", "d10:42:17"} {
+		bit = append(bit, f[0], 0x00, byte(len(f)))
+		bit = append(bit, f[1:]...)
+		bit = append(bit, 0x00)
+	}
+	data := []byte{0xff, 0xff, 0xff, 0xff, 0xaa, 0x99, 0x55, 0x66}
+	bit = append(bit, 'e', 0x00, 0x00, 0x00, byte(len(data)))
+	bit = append(bit, data...)
+
+	bitPath := filepath.Join(tmpDir, "test.bit")
+	if err := os.WriteFile(bitPath, bit, 0644); err != nil {
+		t.Fatal(err)
+	}
+	binPath := filepath.Join(tmpDir, "test.bin")
+	if err := os.WriteFile(binPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		bitPath string
+		want    string
+	}{
+		{
+			name:    "bit file with header",
+			bitPath: bitPath,
+			want:    "top 7a200tfbg484 2025.2",
+		},
+		{
+			name:    "bin file without header",
+			bitPath: binPath,
+			want:    "",
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outfile := filepath.Join(tmpDir, fmt.Sprintf("out%d.sh", i))
+			err := run(Args{
+				BitFile:       "test.bit",
+				BitFilePath:   tt.bitPath,
+				RunDockerFile: "docker.sh",
+				GotoptFile:    "gotopt2",
+				Outfile:       outfile,
+				TemplateFile:  templatePath,
+				VivadoVersion: "2025.1",
+			})
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			b, err := os.ReadFile(outfile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("run() wrote %q, want %q", string(b), tt.want)
+			}
+		})
+	}
+}
+
+func TestRunFlashLayout(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	layoutPath := filepath.Join(tmpDir, "layout.json")
+	layout := `{
+		"flash_size_mb": 16,
+		"sector_size": "0x10000",
+		"images": [
+			{"name": "golden", "address": "0x0"},
+			{"name": "update", "address": "0x400000"}
+		]
+	}`
+	if err := os.WriteFile(layoutPath, []byte(layout), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		images  []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "update image",
+			images: []string{"update=top.update.mcs"},
+			want: []string{
+				`"update")`,
+				`_mcsfile="top.update.mcs"`,
+				"at 0x00400000-0x01000000",
+				"want one of: update",
+			},
+		},
+		{
+			name:    "unknown image",
+			images:  []string{"other=top.other.mcs"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid image",
+			images:  []string{"top.update.mcs"},
+			wantErr: true,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outfile := filepath.Join(tmpDir, fmt.Sprintf("out%d.sh", i))
+			err := run(Args{
+				McsFile:          "top.mcs",
+				FlashPart:        "mt25ql256-spi-x1_x2_x4",
+				LayoutFilePath:   layoutPath,
+				LayoutImageFiles: tt.images,
+				RunDockerFile:    "docker.sh",
+				GotoptFile:       "gotopt2",
+				Outfile:          outfile,
+				TemplateFile:     "flash_script.tpl.sh",
+				VivadoVersion:    "2025.1",
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			b, err := os.ReadFile(outfile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(string(b), w) {
+					t.Errorf("run() script does not contain %q", w)
+				}
+			}
+		})
+	}
+}
+
+func TestRunFlashScript(t *testing.T) {
+	tmpDir := t.TempDir()
+	outfile := filepath.Join(tmpDir, "flash.sh")
+	err := run(Args{
+		McsFile:       "top.mcs",
+		FlashPart:     "mt25ql256-spi-x1_x2_x4",
+		RunDockerFile: "docker.sh",
+		GotoptFile:    "gotopt2",
+		Outfile:       outfile,
+		TemplateFile:  "flash_script.tpl.sh",
+		VivadoVersion: "2025.1",
+	})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	b, err := os.ReadFile(outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []string{
+		`readonly _mode="${gotopt2_mode:-program}"`,
+		`_address_range="entire_device"`,
+		"set_property PROGRAM.BLANK_CHECK $_blank_check",
+		"readback_hw_cfgmem -force -hw_cfgmem",
+	} {
+		if !strings.Contains(string(b), w) {
+			t.Errorf("run() script does not contain %q", w)
+		}
+	}
+	if bash, err := exec.LookPath("bash"); err == nil {
+		if out, err := exec.Command(bash, "-n", outfile).CombinedOutput(); err != nil {
+			t.Errorf("generated script is not valid bash: %v\n%s", err, out)
+		}
+	}
+}
+
+func TestRunDeviceScript(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// A stamped .bit file: commit 1a2b3c4, dirty, built 2026-10-19 13:14:15.
+	var bit []byte
+	bit = append(bit, 0x00, 0x09, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x00, 0x00, 0x01)
+	for _, f := range []string{"atop;UserID=0X1A2B3C43;Version=2025.2", "b7a200tfbg484", "c2026/10/19", "d13:14:15"} {
+		bit = append(bit, f[0], 0x00, byte(len(f)))
+		bit = append(bit, f[1:]...)
+		bit = append(bit, 0x00)
+	}
+	data := []byte{
+		0xaa, 0x99, 0x55, 0x66,
+		0x30, 0x01, 0xa0, 0x01, 0x9d, 0x34, 0xd3, 0x8f, // WRITE AXSS
+	}
+	bit = append(bit, 'e', 0x00, 0x00, 0x00, byte(len(data)))
+	bit = append(bit, data...)
+	bitPath := filepath.Join(tmpDir, "test.bit")
+	if err := os.WriteFile(bitPath, bit, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outfile := filepath.Join(tmpDir, "prog.sh")
+	err := run(Args{
+		BitFile:        "test.bit",
+		BitFilePath:    bitPath,
+		BitstampBinary: "build/vivado/bin/bitstamp/bitstamp_/bitstamp",
+		RunDockerFile:  "docker.sh",
+		GotoptFile:     "gotopt2",
+		Outfile:        outfile,
+		TemplateFile:   "main_script.tpl.sh",
+		VivadoVersion:  "2025.1",
+	})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	b, err := os.ReadFile(outfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []string{
+		`log::info "Bitstream stamp:      1a2b3c4-dirty built 2026-10-19 13:14:15 UTC"`,
+		`readonly _bitstamp_binary="build/vivado/bin/bitstamp/bitstamp_/bitstamp"`,
+		"get_property REGISTER.USR_ACCESS",
+		"program_hw_devices",
+		// A refusal in the TCL, such as a part mismatch, fails the run.
+		`|| { log::error "The programming command failed."; exit 1; }`,
+	} {
+		if !strings.Contains(string(b), w) {
+			t.Errorf("run() script does not contain %q", w)
+		}
+	}
+	if bash, err := exec.LookPath("bash"); err == nil {
+		if out, err := exec.Command(bash, "-n", outfile).CombinedOutput(); err != nil {
+			t.Errorf("generated script is not valid bash: %v\n%s", err, out)
+		}
+	}
+}
+
+func TestRunPartial(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeBit := func(name, design string) string {
+		var bit []byte
+		bit = append(bit, 0x00, 0x09, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x00, 0x00, 0x01)
+		for _, f := range []string{"a" + design, "b7a200tfbg484", "c2026/10/19", "d13:14:15"} {
+			bit = append(bit, f[0], 0x00, byte(len(f)))
+			bit = append(bit, f[1:]...)
+			bit = append(bit, 0x00)
+		}
+		data := []byte{0xaa, 0x99, 0x55, 0x66}
+		bit = append(bit, 'e', 0x00, 0x00, 0x00, byte(len(data)))
+		bit = append(bit, data...)
+		fn := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(fn, bit, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return fn
+	}
+	partial := writeBit("rm.partial.bit", "top;PARTIAL=TRUE;UserID=0XFFFFFFFF;Version=2025.2")
+	full := writeBit("full.bit", "top;UserID=0XFFFFFFFF;Version=2025.2")
+
+	tests := []struct {
+		name      string
+		bitPath   string
+		partial   bool
+		wantErr   bool
+		wantCheck bool
+	}{
+		{"Partial", partial, true, false, true},
+		{"Full", full, false, false, false},
+		{"Partial without --partial", partial, false, true, false},
+		{"Full with --partial", full, true, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outfile := filepath.Join(tmpDir, "prog.sh")
+			err := run(Args{
+				BitFile:       filepath.Base(tt.bitPath),
+				BitFilePath:   tt.bitPath,
+				Partial:       tt.partial,
+				RunDockerFile: "docker.sh",
+				GotoptFile:    "gotopt2",
+				Outfile:       outfile,
+				TemplateFile:  "main_script.tpl.sh",
+				VivadoVersion: "2025.1",
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			b, err := os.ReadFile(outfile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(string(b), "REGISTER.CONFIG_STATUS.BIT14_DONE_PIN"); got != tt.wantCheck {
+				t.Errorf("run() script checks the DONE pin = %v, want %v", got, tt.wantCheck)
+			}
+			if bash, err := exec.LookPath("bash"); err == nil {
+				if out, err := exec.Command(bash, "-n", outfile).CombinedOutput(); err != nil {
+					t.Errorf("generated script is not valid bash: %v\n%s", err, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Check is the result of one check, a test case of the JUnit XML.
+type Check struct {
+	// Class groups the checks, e.g. "timing", and Name is the check within
+	// it, e.g. "setup".
+	Class, Name string
+	// Failure is why the check failed, empty if it passed.
+	Failure string
+	// Details are the violations behind the result.
+	Details string
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Details string `xml:",chardata"`
+}
+
+// writeJUnit writes checks as the test cases of suite, in the JUnit XML
+// format that Bazel reads from XML_OUTPUT_FILE.
+func writeJUnit(w io.Writer, suite string, checks []Check) error {
+	s := junitTestSuite{Name: suite, Tests: len(checks)}
+	for _, c := range checks {
+		tc := junitTestCase{ClassName: c.Class, Name: c.Name}
+		if c.Failure != "" {
+			s.Failures++
+			tc.Failure = &junitFailure{Message: c.Failure, Details: c.Details}
+		} else {
+			tc.SystemOut = c.Details
+		}
+		s.Cases = append(s.Cases, tc)
+	}
+	b, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{s}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%v%s\n", xml.Header, b)
+	return err
+}
+
+// writeJUnitFile writes the JUnit XML of checks to fn.
+func writeJUnitFile(fn, suite string, checks []Check) error {
+	f, err := os.Create(fn)
+	if err != nil {
+		return err
+	}
+	if err := writeJUnit(f, suite, checks); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
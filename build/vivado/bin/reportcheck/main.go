@@ -0,0 +1,280 @@
+// reportcheck checks the reports and logs of an implementation run against
+// quality gates, and writes each check as a test case of a JUnit XML file,
+// so that CI shows the failing checks next to the software unit tests:
+//
+//   - timing: that the design meets setup, hold and pulse width timing;
+//   - utilization: that each site type, such as "Slice LUTs", stays within
+//     its budget in percent;
+//   - drc: one check per DRC rule, that fails on critical and error
+//     violations that are not waived;
+//   - log_warnings: one check per step log, that fails on errors and
+//     critical warnings, and on more warnings than allowed.
+//
+// Only the checks of the given reports run. It fails if any check fails.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"cp/build/vivado/lib/flags"
+	"cp/build/vivado/lib/report"
+)
+
+// checkSlack checks that a worst slack is not negative.
+func checkSlack(name string, worst, total float64, failing int, clocks []string) Check {
+	c := Check{Class: "timing", Name: name, Details: strings.Join(clocks, "\n")}
+	if worst < 0 {
+		c.Failure = fmt.Sprintf("worst slack %.3f ns, total %.3f ns over %d failing endpoints", worst, total, failing)
+	}
+	return c
+}
+
+// checkTiming checks the slack of a timing summary.
+func checkTiming(s *report.TimingSummary) []Check {
+	var setup, hold, pulse []string
+	for _, c := range s.Clocks {
+		if c.WNSNs < 0 {
+			setup = append(setup, fmt.Sprintf("%v: WNS %.3f ns, %d failing endpoints", c.Name, c.WNSNs, c.TNSFailingEndpoints))
+		}
+		if c.WHSNs < 0 {
+			hold = append(hold, fmt.Sprintf("%v: WHS %.3f ns, %d failing endpoints", c.Name, c.WHSNs, c.THSFailingEndpoints))
+		}
+		if c.WPWSNs < 0 {
+			pulse = append(pulse, fmt.Sprintf("%v: WPWS %.3f ns", c.Name, c.WPWSNs))
+		}
+	}
+	return []Check{
+		checkSlack("setup", s.WNSNs, s.TNSNs, s.TNSFailingEndpoints, setup),
+		checkSlack("hold", s.WHSNs, s.THSNs, s.THSFailingEndpoints, hold),
+		checkSlack("pulse_width", s.WPWSNs, s.TPWSNs, 0, pulse),
+	}
+}
+
+// budget is the most of a site type that a design may use, in percent.
+type budget struct {
+	siteType string
+	maxPct   float64
+}
+
+func parseBudget(v string) (budget, error) {
+	i := strings.LastIndex(v, "=")
+	if i <= 0 {
+		return budget{}, fmt.Errorf("--max-utilization %q: want site_type=percent", v)
+	}
+	pct, err := strconv.ParseFloat(v[i+1:], 64)
+	if err != nil {
+		return budget{}, fmt.Errorf("--max-utilization %q: %w", v, err)
+	}
+	return budget{siteType: v[:i], maxPct: pct}, nil
+}
+
+// checkUtilization checks the use of each site type against its budget.
+func checkUtilization(u *report.Utilization, budgets []budget) []Check {
+	var cs []Check
+	for _, b := range budgets {
+		c := Check{Class: "utilization", Name: b.siteType}
+		r, ok := u.Resource(b.siteType)
+		switch {
+		case !ok:
+			c.Failure = fmt.Sprintf("no site type %q in the utilization report", b.siteType)
+		case r.UtilPct > b.maxPct:
+			c.Failure = fmt.Sprintf("%g of %g used, %.2f%% exceeds the budget of %g%%", r.Used, r.Available, r.UtilPct, b.maxPct)
+		default:
+			c.Details = fmt.Sprintf("%g of %g used, %.2f%% of the budget of %g%%", r.Used, r.Available, r.UtilPct, b.maxPct)
+		}
+		cs = append(cs, c)
+	}
+	return cs
+}
+
+// checkDRC checks the DRC violations of each rule.
+func checkDRC(vs []report.Violation) []Check {
+	if len(vs) == 0 {
+		return []Check{{Class: "drc", Name: "violations", Details: "no DRC violations"}}
+	}
+	var cs []Check
+	index := map[string]int{}
+	failing := map[string]int{}
+	for _, v := range vs {
+		i, ok := index[v.ID]
+		if !ok {
+			i = len(cs)
+			index[v.ID] = i
+			cs = append(cs, Check{Class: "drc", Name: v.ID})
+		}
+		line := fmt.Sprintf("%v: %v", v.Severity, v.Description)
+		if v.Waived() {
+			line += " (waived: " + v.WaiverReason + ")"
+		} else if v.Critical() {
+			failing[v.ID]++
+		}
+		if cs[i].Details != "" {
+			cs[i].Details += "\n"
+		}
+		cs[i].Details += line
+	}
+	for i, c := range cs {
+		if n := failing[c.Name]; n > 0 {
+			cs[i].Failure = fmt.Sprintf("%d unwaived %v violations", n, c.Name)
+		}
+	}
+	return cs
+}
+
+// checkLog checks the messages of the log of a step. Messages of the allowed
+// IDs are not counted.
+func checkLog(stage string, ms []report.LogMessage, allowed map[string]bool, maxWarnings int) Check {
+	var kept []report.LogMessage
+	for _, m := range ms {
+		if m.Severity != "INFO" && !allowed[m.ID] {
+			kept = append(kept, m)
+		}
+	}
+	c := Check{Class: "log_warnings", Name: stage}
+	var critical, warnings int
+	var lines []string
+	for _, mc := range report.CountMessages(kept) {
+		if mc.Severity == "WARNING" {
+			warnings += mc.Count
+		} else {
+			critical += mc.Count
+		}
+		lines = append(lines, fmt.Sprintf("%v [%v] x%d: %v", mc.Severity, mc.ID, mc.Count, mc.Example))
+	}
+	c.Details = strings.Join(lines, "\n")
+	switch {
+	case critical > 0:
+		c.Failure = fmt.Sprintf("%d errors and critical warnings", critical)
+	case maxWarnings >= 0 && warnings > maxWarnings:
+		c.Failure = fmt.Sprintf("%d warnings, at most %d allowed", warnings, maxWarnings)
+	}
+	return c
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("reportcheck", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var suite, timingFile, utilizationFile, drcFile, waiversFile, xmlFile string
+	var budgets, logs, allowedWarnings flags.Strings
+	var maxWarnings int
+	fs.StringVar(&suite, "suite", "reportcheck", "The name of the JUnit test suite")
+	fs.StringVar(&timingFile, "timing-summary", "", "The report_timing_summary text report to check")
+	fs.StringVar(&utilizationFile, "utilization", "", "The report_utilization text report to check")
+	fs.Var(&budgets, "max-utilization", "The utilization budget of a site type, as site_type=percent, e.g. \"Slice LUTs=80\", can be repeated")
+	fs.StringVar(&drcFile, "drc", "", "The report_drc text report to check")
+	fs.StringVar(&waiversFile, "drc-waivers", "", "The JSON waiver file to apply to the DRC violations")
+	fs.Var(&logs, "log", "A step log to check, as stage=file, can be repeated")
+	fs.Var(&allowedWarnings, "allow-warning", "A message ID that is not counted in the logs, e.g. \"Synth 8-7129\", can be repeated")
+	fs.IntVar(&maxWarnings, "max-warnings", -1, "The most warnings allowed in each log, -1 for any number")
+	fs.StringVar(&xmlFile, "xml-output", "", "The JUnit XML file to write, if set")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(budgets) > 0 && utilizationFile == "" {
+		return fmt.Errorf("param --max-utilization needs --utilization")
+	}
+	if waiversFile != "" && drcFile == "" {
+		return fmt.Errorf("param --drc-waivers needs --drc")
+	}
+
+	var checks []Check
+	if timingFile != "" {
+		s, err := report.ParseFile(timingFile, report.ParseTimingSummary)
+		if err != nil {
+			return err
+		}
+		checks = append(checks, checkTiming(s)...)
+	}
+	if utilizationFile != "" {
+		var bs []budget
+		for _, v := range budgets {
+			b, err := parseBudget(v)
+			if err != nil {
+				return err
+			}
+			bs = append(bs, b)
+		}
+		u, err := report.ParseFile(utilizationFile, report.ParseUtilization)
+		if err != nil {
+			return err
+		}
+		checks = append(checks, checkUtilization(u, bs)...)
+	}
+	if drcFile != "" {
+		vs, err := report.ParseFile(drcFile, report.ParseDRC)
+		if err != nil {
+			return err
+		}
+		if waiversFile != "" {
+			ws, err := report.ReadWaivers(waiversFile)
+			if err != nil {
+				return err
+			}
+			for _, w := range ws.Apply(vs) {
+				fmt.Fprintf(stderr, "WARNING: waiver matches no DRC violation: %v\n", w)
+			}
+		}
+		checks = append(checks, checkDRC(vs)...)
+	}
+	allowed := map[string]bool{}
+	for _, id := range allowedWarnings {
+		allowed[id] = true
+	}
+	for _, l := range logs {
+		stage, fn, ok := strings.Cut(l, "=")
+		if !ok || stage == "" || fn == "" {
+			return fmt.Errorf("--log %q: want stage=file", l)
+		}
+		ms, err := report.ParseFile(fn, report.ParseLogMessages)
+		if err != nil {
+			return err
+		}
+		checks = append(checks, checkLog(stage, ms, allowed, maxWarnings))
+	}
+
+	var failed int
+	for _, c := range checks {
+		if c.Failure == "" {
+			fmt.Fprintf(stdout, "PASS %v/%v\n", c.Class, c.Name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(stdout, "FAIL %v/%v: %v\n", c.Class, c.Name, c.Failure)
+		for _, l := range strings.Split(c.Details, "\n") {
+			if l != "" {
+				fmt.Fprintf(stdout, "    %v\n", l)
+			}
+		}
+	}
+	if xmlFile != "" {
+		if err := writeJUnitFile(xmlFile, suite, checks); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+func runCLI(osArgs []string, stdout, stderr io.Writer) error {
+	p := path.Base(osArgs[0])
+	log.SetPrefix(fmt.Sprintf("%v: ", p))
+
+	return run(osArgs[1:], stdout, stderr)
+}
+
+func main() {
+	if err := runCLI(os.Args, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const timingSummary = `| Design Timing Summary
+| ---------------------
+
+    WNS(ns)  TNS(ns)  TNS Failing Endpoints  WHS(ns)  THS(ns)  THS Failing Endpoints  WPWS(ns)  TPWS(ns)
+    -------  -------  ---------------------  -------  -------  ---------------------  --------  --------
+     -0.250   -1.000                      4    0.050    0.000                      0     1.750     0.000
+
+
+| Intra Clock Table
+| -----------------
+
+Clock  WNS(ns)  TNS(ns)  TNS Failing Endpoints  WHS(ns)  THS(ns)  THS Failing Endpoints  WPWS(ns)
+-----  -------  -------  ---------------------  -------  -------  ---------------------  --------
+clk     -0.250   -1.000                      4    0.050    0.000                      0     1.750
+`
+
+const utilization = `1. Slice Logic
+--------------
+
++-----------------+-------+-----------+-------+
+|    Site Type    |  Used | Available | Util% |
++-----------------+-------+-----------+-------+
+| Slice LUTs      | 60000 |     63400 | 94.64 |
+| Slice Registers |  2000 |    126800 |  1.58 |
++-----------------+-------+-----------+-------+
+`
+
+const drc = `2. REPORT DETAILS
+-----------------
+NSTD-1#1 Critical Warning
+Unspecified I/O Standard
+Problem ports: led[0].
+Related violations: <none>
+
+UCIO-1#1 Critical Warning
+Unconstrained Logical Port
+Problem ports: led[1].
+Related violations: <none>
+
+CKLD-2#1 Warning
+Clock Net has IO Driver, not a Clock Buf
+Clock net clk_IBUF is directly driven by an IO.
+Related violations: <none>
+`
+
+const routeLog = `WARNING: [Route 35-328] Router estimated timing not met.
+CRITICAL WARNING: [Timing 38-282] The design failed to meet the timing requirements.
+INFO: [Common 17-206] Exiting Vivado
+`
+
+const optLog = `WARNING: [Opt 31-35] Removing redundant IBUF.
+WARNING: [Opt 31-35] Removing redundant IBUF.
+`
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	timingFile := filepath.Join(tmpDir, "top.timing_summary.pnr.rpt")
+	utilizationFile := filepath.Join(tmpDir, "top.utilization.pnr.rpt")
+	drcFile := filepath.Join(tmpDir, "top.drc.rpt")
+	waiversFile := filepath.Join(tmpDir, "waivers.json")
+	routeLogFile := filepath.Join(tmpDir, "top.route.log")
+	optLogFile := filepath.Join(tmpDir, "top.opt.log")
+	for fn, content := range map[string]string{
+		timingFile:      timingSummary,
+		utilizationFile: utilization,
+		drcFile:         drc,
+		waiversFile:     `{"waivers": [{"id": "UCIO-1", "reason": "led[1] is a test point"}, {"id": "DPIP-1", "reason": "stale"}]}`,
+		routeLogFile:    routeLog,
+		optLogFile:      optLog,
+	} {
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		want       []string
+		wantStderr string
+		wantErr    string
+	}{
+		{
+			name: "Timing",
+			args: []string{"--timing-summary", timingFile},
+			want: []string{
+				"FAIL timing/setup: worst slack -0.250 ns, total -1.000 ns over 4 failing endpoints\n    clk: WNS -0.250 ns, 4 failing endpoints\n",
+				"PASS timing/hold\n",
+				"PASS timing/pulse_width\n",
+			},
+			wantErr: "1 of 3 checks failed",
+		},
+		{
+			name: "Utilization",
+			args: []string{"--utilization", utilizationFile,
+				"--max-utilization", "Slice LUTs=90", "--max-utilization", "Slice Registers=50", "--max-utilization", "DSPs=10"},
+			want: []string{
+				"FAIL utilization/Slice LUTs: 60000 of 63400 used, 94.64% exceeds the budget of 90%\n",
+				"PASS utilization/Slice Registers\n",
+				"FAIL utilization/DSPs: no site type \"DSPs\" in the utilization report\n",
+			},
+			wantErr: "2 of 3 checks failed",
+		},
+		{
+			name: "DRC",
+			args: []string{"--drc", drcFile, "--drc-waivers", waiversFile},
+			want: []string{
+				"FAIL drc/NSTD-1: 1 unwaived NSTD-1 violations\n",
+				"PASS drc/UCIO-1\n",
+				"PASS drc/CKLD-2\n",
+			},
+			wantStderr: "waiver matches no DRC violation: id=DPIP-1",
+			wantErr:    "1 of 3 checks failed",
+		},
+		{
+			name: "Logs",
+			args: []string{"--log", "opt=" + optLogFile, "--log", "route=" + routeLogFile, "--max-warnings", "1"},
+			want: []string{
+				"FAIL log_warnings/opt: 2 warnings, at most 1 allowed\n    WARNING [Opt 31-35] x2: Removing redundant IBUF.\n",
+				"FAIL log_warnings/route: 1 errors and critical warnings\n",
+			},
+			wantErr: "2 of 2 checks failed",
+		},
+		{
+			name: "Allowed warnings",
+			args: []string{"--log", "route=" + routeLogFile, "--allow-warning", "Timing 38-282"},
+			want: []string{"PASS log_warnings/route\n"},
+		},
+		{
+			name:    "Bad budget",
+			args:    []string{"--utilization", utilizationFile, "--max-utilization", "80"},
+			wantErr: "want site_type=percent",
+		},
+		{
+			name:    "Budget without report",
+			args:    []string{"--max-utilization", "Slice LUTs=80"},
+			wantErr: "param --max-utilization needs --utilization",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := run(tt.args, &stdout, &stderr)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("run() error = %v, want %q", err, tt.wantErr)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(stdout.String(), w) {
+					t.Errorf("run() stdout = %q, want it to contain %q", stdout.String(), w)
+				}
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("run() stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRunXMLOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	timingFile := filepath.Join(tmpDir, "top.timing_summary.pnr.rpt")
+	if err := os.WriteFile(timingFile, []byte(timingSummary), 0644); err != nil {
+		t.Fatal(err)
+	}
+	xmlFile := filepath.Join(tmpDir, "test.xml")
+
+	err := run([]string{"--suite", "//fpga:top_checks", "--timing-summary", timingFile, "--xml-output", xmlFile}, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil {
+		t.Fatalf("run() succeeded, want failing timing")
+	}
+	b, err := os.ReadFile(xmlFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got junitTestSuites
+	if err := xml.Unmarshal(b, &got); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v\n%s", err, b)
+	}
+	if len(got.Suites) != 1 {
+		t.Fatalf("suites = %+v, want one", got.Suites)
+	}
+	s := got.Suites[0]
+	if s.Name != "//fpga:top_checks" || s.Tests != 3 || s.Failures != 1 || len(s.Cases) != 3 {
+		t.Errorf("suite = %+v, want //fpga:top_checks with 3 tests and 1 failure", s)
+	}
+	setup := s.Cases[0]
+	if setup.ClassName != "timing" || setup.Name != "setup" || setup.Failure == nil ||
+		!strings.Contains(setup.Failure.Message, "worst slack -0.250 ns") {
+		t.Errorf("test case = %+v, want failing timing setup", setup)
+	}
+	if hold := s.Cases[1]; hold.Name != "hold" || hold.Failure != nil {
+		t.Errorf("test case = %+v, want passing timing hold", hold)
+	}
+}
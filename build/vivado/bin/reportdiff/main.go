@@ -0,0 +1,368 @@
+// reportdiff compares the reports of two `vivado_place_and_route2` runs of a
+// design, such as those of the current and the base revision, and prints
+// what regressed and what improved:
+//
+//   - the worst and total setup and hold slack;
+//   - the LUTs, FFs, block RAMs and DSPs of the design, and of each instance
+//     in its hierarchy whose use changed;
+//   - the on-chip power;
+//   - the DRC violations and clock domain crossings that are new or fixed;
+//   - the elapsed time of each implementation stage.
+//
+// Each run is a directory with the outputs of the rule, e.g. a copy of its
+// bazel-bin package. Reports that are missing in either run, such as the
+// power report of a run without `power_report`, are left out. To compare
+// against the base revision, build it, copy the outputs, then build the
+// current revision:
+//
+//	git checkout main && bazel build //fpga:top_pnr
+//	cp -r bazel-bin/fpga /tmp/base
+//	git checkout - && bazel build //fpga:top_pnr
+//	bazel run //build/vivado/bin/reportdiff -- --base=/tmp/base --head=$PWD/bazel-bin/fpga
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"cp/build/vivado/lib/report"
+)
+
+// stages are the implementation stages of vivado_place_and_route2, in the
+// order that they run.
+var stages = []string{"opt", "place", "phys_opt", "route", "bitstream"}
+
+// implRun are the reports of one run.
+type implRun struct {
+	timing      *report.TimingSummary
+	utilization *report.Utilization
+	hier        []report.InstanceUtilization
+	power       *report.Power
+	drc         []report.Violation
+	cdc         []report.Violation
+	// runtimes are the elapsed seconds of each stage that ran.
+	runtimes map[string]float64
+}
+
+// parseFile parses fn, or returns a nil T if fn does not exist.
+func parseFile[T any](fn string, parse func(io.Reader) (T, error)) (T, error) {
+	v, err := report.ParseFile(fn, parse)
+	if errors.Is(err, fs.ErrNotExist) {
+		var zero T
+		return zero, nil
+	}
+	return v, err
+}
+
+// load reads the reports of design name in dir.
+func load(dir, name string) (*implRun, error) {
+	fn := func(suffix string) string {
+		return filepath.Join(dir, name+suffix)
+	}
+	var r implRun
+	var err error
+	if r.timing, err = parseFile(fn(".timing_summary.pnr.rpt"), report.ParseTimingSummary); err != nil {
+		return nil, err
+	}
+	if r.utilization, err = parseFile(fn(".utilization.pnr.rpt"), report.ParseUtilization); err != nil {
+		return nil, err
+	}
+	if r.hier, err = parseFile(fn(".utilization_hier.pnr.rpt"), report.ParseHierUtilization); err != nil {
+		return nil, err
+	}
+	if r.power, err = parseFile(fn(".power.rpt"), report.ParsePower); err != nil {
+		return nil, err
+	}
+	if r.drc, err = parseFile(fn(".drc.rpt"), report.ParseDRC); err != nil {
+		return nil, err
+	}
+	if r.cdc, err = parseFile(fn(".cdc.pnr.rpt"), report.ParseCDC); err != nil {
+		return nil, err
+	}
+	r.runtimes = map[string]float64{}
+	for _, stage := range stages {
+		ts, err := parseFile(fn("."+stage+".log"), report.ParseLogTimes)
+		if err != nil {
+			return nil, err
+		}
+		if ts == nil {
+			continue
+		}
+		var secs float64
+		for _, t := range ts {
+			secs += t.ElapsedSeconds
+		}
+		r.runtimes[stage] = secs
+	}
+	if r.timing == nil && r.utilization == nil && len(r.runtimes) == 0 {
+		return nil, fmt.Errorf("no reports of %v in %v", name, dir)
+	}
+	return &r, nil
+}
+
+// designName returns the name of the only design with a timing summary in
+// dir.
+func designName(dir string) (string, error) {
+	const suffix = ".timing_summary.pnr.rpt"
+	fns, err := filepath.Glob(filepath.Join(dir, "*"+suffix))
+	if err != nil {
+		return "", err
+	}
+	switch len(fns) {
+	case 0:
+		return "", fmt.Errorf("no *%v in %v, set --name", suffix, dir)
+	case 1:
+		return strings.TrimSuffix(filepath.Base(fns[0]), suffix), nil
+	}
+	return "", fmt.Errorf("several designs in %v, set --name", dir)
+}
+
+// printTiming prints the slack of base and head.
+func printTiming(w io.Writer, base, head *report.TimingSummary) {
+	if base == nil || head == nil {
+		return
+	}
+	fmt.Fprintln(w, "Timing\tbase\thead\tdelta")
+	row := func(name string, b, h float64) {
+		fmt.Fprintf(w, "  %v\t%.3f\t%.3f\t%+.3f\n", name, b, h, h-b)
+	}
+	count := func(name string, b, h int) {
+		fmt.Fprintf(w, "  %v\t%d\t%d\t%+d\n", name, b, h, h-b)
+	}
+	row("WNS (ns)", base.WNSNs, head.WNSNs)
+	row("TNS (ns)", base.TNSNs, head.TNSNs)
+	count("Failing setup endpoints", base.TNSFailingEndpoints, head.TNSFailingEndpoints)
+	row("WHS (ns)", base.WHSNs, head.WHSNs)
+	row("THS (ns)", base.THSNs, head.THSNs)
+	count("Failing hold endpoints", base.THSFailingEndpoints, head.THSFailingEndpoints)
+	fmt.Fprintln(w)
+}
+
+// printUtilization prints the resources of base and head, and those of the
+// instances up to depth whose resources changed.
+func printUtilization(w io.Writer, base, head *implRun, depth int) {
+	row := func(name string, b, h float64) {
+		fmt.Fprintf(w, "  %v\t%g\t%g\t%+g\n", name, b, h, h-b)
+	}
+	if base.utilization != nil && head.utilization != nil {
+		b, h := base.utilization, head.utilization
+		fmt.Fprintln(w, "Utilization\tbase\thead\tdelta")
+		row("LUT", b.LUT, h.LUT)
+		row("FF", b.FF, h.FF)
+		row("BRAM", b.BRAM, h.BRAM)
+		row("DSP", b.DSP, h.DSP)
+		fmt.Fprintln(w)
+	}
+	if base.hier == nil || head.hier == nil {
+		return
+	}
+	baseInsts := map[string]report.InstanceUtilization{}
+	for _, u := range base.hier {
+		baseInsts[u.Instance] = u
+	}
+	headInsts := map[string]bool{}
+	var lines []string
+	// An instance that is not in a run is in it with no resources.
+	add := func(u, b, h report.InstanceUtilization, note string) {
+		if u.Depth == 0 || (depth > 0 && u.Depth > depth) {
+			return
+		}
+		if note == "" && h.LUT == b.LUT && h.FF == b.FF && h.BRAM == b.BRAM && h.DSP == b.DSP {
+			return
+		}
+		lines = append(lines, fmt.Sprintf("  %v (%v)%v\t%+g\t%+g\t%+g\t%+g",
+			u.Instance, u.Module, note, h.LUT-b.LUT, h.FF-b.FF, h.BRAM-b.BRAM, h.DSP-b.DSP))
+	}
+	for _, h := range head.hier {
+		headInsts[h.Instance] = true
+		b, ok := baseInsts[h.Instance]
+		note := ""
+		if !ok {
+			note = " new"
+		}
+		add(h, b, h, note)
+	}
+	for _, b := range base.hier {
+		if !headInsts[b.Instance] {
+			add(b, b, report.InstanceUtilization{}, " removed")
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Hierarchy\tLUT\tFF\tBRAM\tDSP")
+	for _, l := range lines {
+		fmt.Fprintln(w, l)
+	}
+	fmt.Fprintln(w)
+}
+
+// printPower prints the power of base and head.
+func printPower(w io.Writer, base, head *report.Power) {
+	if base == nil || head == nil {
+		return
+	}
+	fmt.Fprintln(w, "Power\tbase\thead\tdelta")
+	row := func(name string, b, h float64) {
+		fmt.Fprintf(w, "  %v\t%.3f\t%.3f\t%+.3f\n", name, b, h, h-b)
+	}
+	row("Total on-chip (W)", base.TotalOnChipW, head.TotalOnChipW)
+	row("Dynamic (W)", base.DynamicW, head.DynamicW)
+	row("Static (W)", base.StaticW, head.StaticW)
+	row("Junction temperature (C)", base.JunctionTempC, head.JunctionTempC)
+	fmt.Fprintln(w)
+}
+
+// violationKey identifies a violation across runs.
+func violationKey(v report.Violation) string {
+	return strings.Join([]string{v.ID, v.Description, v.FromClock, v.ToClock, v.Startpoint, v.Endpoint}, "\x00")
+}
+
+// diffViolations returns the violations of head that base does not have,
+// and those of base that head does not have.
+func diffViolations(base, head []report.Violation) (added, fixed []report.Violation) {
+	count := map[string]int{}
+	for _, v := range base {
+		count[violationKey(v)]++
+	}
+	for _, v := range head {
+		k := violationKey(v)
+		if count[k] > 0 {
+			count[k]--
+			continue
+		}
+		added = append(added, v)
+	}
+	for _, v := range base {
+		k := violationKey(v)
+		if count[k] > 0 {
+			count[k]--
+			fixed = append(fixed, v)
+		}
+	}
+	return added, fixed
+}
+
+// describe returns a one line description of a violation.
+func describe(v report.Violation) string {
+	s := fmt.Sprintf("%v %v", v.ID, v.Severity)
+	if v.FromClock != "" || v.ToClock != "" {
+		s += fmt.Sprintf(" %v -> %v", v.FromClock, v.ToClock)
+	}
+	if v.Endpoint != "" {
+		s += " at " + v.Endpoint
+	}
+	if v.Description != "" {
+		s += ": " + v.Description
+	}
+	return s
+}
+
+// printViolations prints the new and fixed violations of a check.
+func printViolations(w io.Writer, title string, base, head []report.Violation) {
+	if base == nil && head == nil {
+		return
+	}
+	added, fixed := diffViolations(base, head)
+	fmt.Fprintf(w, "%v: %d new, %d fixed\n", title, len(added), len(fixed))
+	for _, v := range added {
+		fmt.Fprintf(w, "  new:   %v\n", describe(v))
+	}
+	for _, v := range fixed {
+		fmt.Fprintf(w, "  fixed: %v\n", describe(v))
+	}
+	fmt.Fprintln(w)
+}
+
+// printRuntimes prints the elapsed time of the stages of base and head.
+func printRuntimes(w io.Writer, base, head map[string]float64) {
+	if len(base) == 0 || len(head) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Runtime\tbase\thead\tdelta")
+	var baseTotal, headTotal float64
+	for _, stage := range stages {
+		b, bok := base[stage]
+		h, hok := head[stage]
+		if !bok && !hok {
+			continue
+		}
+		baseTotal += b
+		headTotal += h
+		fmt.Fprintf(w, "  %v\t%v\t%v\t%+.0fs\n", stage, report.FormatSeconds(b), report.FormatSeconds(h), h-b)
+	}
+	fmt.Fprintf(w, "  total\t%v\t%v\t%+.0fs\n", report.FormatSeconds(baseTotal), report.FormatSeconds(headTotal), headTotal-baseTotal)
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("reportdiff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var baseDir, headDir, name string
+	var depth int
+	fs.StringVar(&baseDir, "base", "", "The directory with the reports of the base run")
+	fs.StringVar(&headDir, "head", "", "The directory with the reports of the run to compare")
+	fs.StringVar(&name, "name", "", "The name of the vivado_place_and_route2 target, if not given it is taken from the timing summary in --head")
+	fs.IntVar(&depth, "depth", 2, "The maximum depth of the hierarchy to list, 0 for all")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if baseDir == "" {
+		return fmt.Errorf("param --base is required")
+	}
+	if headDir == "" {
+		return fmt.Errorf("param --head is required")
+	}
+	if name == "" {
+		var err error
+		if name, err = designName(headDir); err != nil {
+			return err
+		}
+	}
+
+	base, err := load(baseDir, name)
+	if err != nil {
+		return err
+	}
+	head, err := load(headDir, name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "%v: %v -> %v\n\n", name, baseDir, headDir)
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	printTiming(w, base.timing, head.timing)
+	printUtilization(w, base, head, depth)
+	printPower(w, base.power, head.power)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	printViolations(stdout, "DRC", base.drc, head.drc)
+	printViolations(stdout, "CDC", base.cdc, head.cdc)
+	w = tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	printRuntimes(w, base.runtimes, head.runtimes)
+	return w.Flush()
+}
+
+func runCLI(osArgs []string, stdout, stderr io.Writer) error {
+	p := path.Base(osArgs[0])
+	log.SetPrefix(fmt.Sprintf("%v: ", p))
+
+	return run(osArgs[1:], stdout, stderr)
+}
+
+func main() {
+	if err := runCLI(os.Args, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func timingSummary(wns, whs string, failing int) string {
+	return fmt.Sprintf(`| Design Timing Summary
+| ---------------------
+
+    WNS(ns)  TNS(ns)  TNS Failing Endpoints  WHS(ns)  THS(ns)  THS Failing Endpoints
+    -------  -------  ---------------------  -------  -------  ---------------------
+    %7v   -1.000  %21d  %7v    0.000                      0
+`, wns, failing, whs)
+}
+
+func utilization(luts int) string {
+	return fmt.Sprintf(`1. Slice Logic
+--------------
+
++-----------------+------+-----------+-------+
+|    Site Type    | Used | Available | Util%% |
++-----------------+------+-----------+-------+
+| Slice LUTs      | %4d |     63400 |  1.00 |
+| Slice Registers | 2000 |    126800 |  1.58 |
++-----------------+------+-----------+-------+
+`, luts)
+}
+
+func hierUtilization(rows ...string) string {
+	return `1. Utilization by Hierarchy
+---------------------------
+
++-----------+--------+------------+------+--------+--------+--------------+
+|  Instance | Module | Total LUTs |  FFs | RAMB36 | RAMB18 | DSP48 Blocks |
++-----------+--------+------------+------+--------+--------+--------------+
+` + strings.Join(rows, "\n") + `
++-----------+--------+------------+------+--------+--------+--------------+
+`
+}
+
+func drc(ids ...string) string {
+	var s string
+	for i, id := range ids {
+		s += fmt.Sprintf("%v#%d Warning\nTitle of %v\nMessage of %v.\nRelated violations: <none>\n\n", id, i+1, id, id)
+	}
+	return "2. REPORT DETAILS\n-----------------\n" + s
+}
+
+func stageLog(elapsed string) string {
+	return "route_design: Time (s): cpu = 00:01:00 ; elapsed = " + elapsed + " . Memory (MB): peak = 2000.000 ; gain = 10.000\n"
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	base := filepath.Join(tmpDir, "base")
+	writeFiles(t, base, map[string]string{
+		"top.timing_summary.pnr.rpt": timingSummary("0.100", "0.050", 0),
+		"top.utilization.pnr.rpt":    utilization(1000),
+		"top.utilization_hier.pnr.rpt": hierUtilization(
+			"| top       |  (top) |       1000 | 2000 |      1 |      0 |            0 |",
+			"|   u_a     |      a |        600 | 1000 |      1 |      0 |            0 |",
+			"|   u_b     |      b |        400 | 1000 |      0 |      0 |            0 |",
+			"|   u_old   |    old |         50 |   10 |      0 |      0 |            0 |",
+		),
+		"top.drc.rpt":   drc("NSTD-1", "CKLD-2"),
+		"top.opt.log":   stageLog("00:00:30"),
+		"top.route.log": stageLog("00:02:00"),
+	})
+	head := filepath.Join(tmpDir, "head")
+	writeFiles(t, head, map[string]string{
+		"top.timing_summary.pnr.rpt": timingSummary("-0.200", "0.050", 3),
+		"top.utilization.pnr.rpt":    utilization(1100),
+		"top.utilization_hier.pnr.rpt": hierUtilization(
+			"| top       |  (top) |       1100 | 2000 |      1 |      1 |            0 |",
+			"|   u_a     |      a |        700 | 1000 |      1 |      1 |            0 |",
+			"|   u_b     |      b |        400 | 1000 |      0 |      0 |            0 |",
+		),
+		"top.drc.rpt":   drc("NSTD-1", "RTSTAT-10"),
+		"top.opt.log":   stageLog("00:00:30"),
+		"top.route.log": stageLog("00:03:05"),
+	})
+	other := filepath.Join(tmpDir, "other")
+	writeFiles(t, other, map[string]string{
+		"a.timing_summary.pnr.rpt": timingSummary("0.100", "0.050", 0),
+		"b.timing_summary.pnr.rpt": timingSummary("0.100", "0.050", 0),
+	})
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		notWant []string
+		wantErr string
+	}{
+		{
+			name: "Regression",
+			args: []string{"--base", base, "--head", head},
+			want: []string{
+				"WNS (ns)                 0.100   -0.200  -0.300",
+				"Failing setup endpoints  0       3       +3",
+				"LUT        1000  1100  +100",
+				"u_a (a)              +100  +0   +0.5  +0\n",
+				"u_old (old) removed  -50   -10  +0    +0\n",
+				"DRC: 1 new, 1 fixed",
+				"new:   RTSTAT-10 Warning: Title of RTSTAT-10: Message of RTSTAT-10.",
+				"fixed: CKLD-2 Warning: Title of CKLD-2: Message of CKLD-2.",
+				"route  0:02:00  0:03:05  +65s",
+				"total  0:02:30  0:03:35  +65s",
+			},
+			notWant: []string{"u_b", "Power", "CDC"},
+		},
+		{
+			name: "Explicit name",
+			args: []string{"--base", base, "--head", head, "--name", "top"},
+			want: []string{"top: "},
+		},
+		{
+			name:    "Missing design",
+			args:    []string{"--base", base, "--head", head, "--name", "other"},
+			wantErr: "no reports of other",
+		},
+		{
+			name:    "Several designs",
+			args:    []string{"--base", base, "--head", other},
+			wantErr: "several designs",
+		},
+		{
+			name:    "Missing base",
+			args:    []string{"--head", head},
+			wantErr: "param --base is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := run(tt.args, &stdout, &stderr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			got := stdout.String()
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("run() output does not contain %q:\n%v", w, got)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(got, w) {
+					t.Errorf("run() output contains %q:\n%v", w, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// timingreport converts the text output of Vivado's `report_timing` to JSON,
+// with the worst setup and hold paths, and the paths grouped by the module
+// that they end in.
+//
+// Each path has its startpoint, endpoint and clocks, its slack, the number
+// of logic levels, the split of the data path delay into logic and routing,
+// and the cells along the data path.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+
+	"cp/build/vivado/lib/report"
+)
+
+// Result is the JSON output.
+type Result struct {
+	Setup []report.TimingPath `json:"setup"`
+	Hold  []report.TimingPath `json:"hold"`
+	// SetupModules and HoldModules group the paths by module, worst first.
+	SetupModules []report.ModuleTiming `json:"setup_modules"`
+	HoldModules  []report.ModuleTiming `json:"hold_modules"`
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("timingreport", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var reportFile, outFile string
+	fs.StringVar(&reportFile, "report", "", "The report_timing text report to read")
+	fs.StringVar(&outFile, "out", "", "The JSON file to write, stdout if empty")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if reportFile == "" {
+		return fmt.Errorf("param --report is required")
+	}
+
+	f, err := os.Open(reportFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	paths, err := report.ParseTimingPaths(f)
+	if err != nil {
+		return fmt.Errorf("parse %v: %w", reportFile, err)
+	}
+
+	res := Result{Setup: []report.TimingPath{}, Hold: []report.TimingPath{}}
+	for _, p := range paths {
+		switch p.Type {
+		case "setup":
+			res.Setup = append(res.Setup, p)
+		case "hold":
+			res.Hold = append(res.Hold, p)
+		}
+	}
+	res.SetupModules = report.ByModule(res.Setup)
+	res.HoldModules = report.ByModule(res.Hold)
+
+	b, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if outFile == "" {
+		_, err := stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(outFile, b, 0644)
+}
+
+func runCLI(osArgs []string, stdout, stderr io.Writer) error {
+	p := path.Base(osArgs[0])
+	log.SetPrefix(fmt.Sprintf("%v: ", p))
+
+	return run(osArgs[1:], stdout, stderr)
+}
+
+func main() {
+	if err := runCLI(os.Args, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+}
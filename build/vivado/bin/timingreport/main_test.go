@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const timingReport = `Slack (VIOLATED) :        -0.512ns  (required time - arrival time)
+  Source:                 u_a/q_reg[3]/C
+                            (rising edge-triggered cell FDRE clocked by clk  {rise@0.000ns fall@2.500ns period=5.000ns})
+  Destination:            u_b/acc_reg[7]/D
+                            (rising edge-triggered cell FDRE clocked by clk  {rise@0.000ns fall@2.500ns period=5.000ns})
+  Path Group:             clk
+  Path Type:              Setup (Max at Slow Process Corner)
+  Requirement:            5.000ns  (clk rise@5.000ns - clk rise@0.000ns)
+  Data Path Delay:        5.321ns  (logic 2.110ns (39.654%)  route 3.211ns (60.346%))
+  Logic Levels:           1  (LUT3=1)
+
+Slack (MET) :             0.051ns  (arrival time - required time)
+  Source:                 u_a/q_reg[3]/C
+                            (rising edge-triggered cell FDRE clocked by clk  {rise@0.000ns fall@2.500ns period=5.000ns})
+  Destination:            u_a/r_reg[3]/D
+                            (rising edge-triggered cell FDRE clocked by clk  {rise@0.000ns fall@2.500ns period=5.000ns})
+  Path Group:             clk
+  Path Type:              Hold (Min at Fast Process Corner)
+  Requirement:            0.000ns  (clk rise@0.000ns - clk rise@0.000ns)
+  Data Path Delay:        0.200ns  (logic 0.100ns (50.000%)  route 0.100ns (50.000%))
+  Logic Levels:           0
+`
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	rptFile := filepath.Join(tmpDir, "top.timing_paths.rpt")
+	if err := os.WriteFile(rptFile, []byte(timingReport), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	if err := run([]string{"--report", rptFile}, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	var got Result
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %s: %v", stdout.Bytes(), err)
+	}
+	if len(got.Setup) != 1 || got.Setup[0].Endpoint != "u_b/acc_reg[7]/D" || got.Setup[0].RouteDelayNs != 3.211 {
+		t.Errorf("run() setup = %+v", got.Setup)
+	}
+	if len(got.Hold) != 1 || got.Hold[0].SlackNs != 0.051 {
+		t.Errorf("run() hold = %+v", got.Hold)
+	}
+	if len(got.SetupModules) != 1 || got.SetupModules[0].Module != "u_b" || got.SetupModules[0].Failing != 1 {
+		t.Errorf("run() setup modules = %+v", got.SetupModules)
+	}
+	if len(got.HoldModules) != 1 || got.HoldModules[0].Module != "u_a" {
+		t.Errorf("run() hold modules = %+v", got.HoldModules)
+	}
+}
+
+func TestRunMissingReport(t *testing.T) {
+	if err := run(nil, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Errorf("run() succeeded, want error")
+	}
+}
@@ -0,0 +1,169 @@
+// updatememgen generates the arguments for Vivado's updatemem, which patches
+// new block RAM contents, such as a soft CPU firmware, into a routed
+// bitstream without running synthesis or implementation again.
+//
+// The block RAM memory map (.mmi) is written by place and route with
+// write_mem_info. The generator reads it to check that every data file goes
+// to a processor that exists in the design, so that a typo in an instance
+// path shows up with the list of valid ones instead of as an updatemem error.
+//
+// The arguments are written one per line, as the argument file of the
+// updatemem action.
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"cp/build/vivado/lib/flags"
+)
+
+// dataExtensions are the data file types that updatemem accepts.
+var dataExtensions = []string{".elf", ".mem"}
+
+// MemInfo is the part of the .mmi memory map that the generator uses.
+type MemInfo struct {
+	Processors []struct {
+		InstPath string `xml:"InstPath,attr"`
+	} `xml:"Processor"`
+}
+
+// ProcessorNames returns the instance paths of the processors in the memory
+// map.
+func (m MemInfo) ProcessorNames() []string {
+	var ns []string
+	for _, p := range m.Processors {
+		ns = append(ns, p.InstPath)
+	}
+	return ns
+}
+
+// ReadMemInfo reads the .mmi file fn.
+func ReadMemInfo(fn string) (*MemInfo, error) {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, fmt.Errorf("read memory map: %w", err)
+	}
+	var m MemInfo
+	if err := xml.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("parse memory map %v: %w", fn, err)
+	}
+	return &m, nil
+}
+
+// DataFile is a file with the memory contents of one processor.
+type DataFile struct {
+	Proc string
+	File string
+}
+
+// resolve matches the data files, given as proc=file, to the processors of
+// the memory map. An empty proc stands for the only processor of the map.
+func resolve(m *MemInfo, data []string) ([]DataFile, error) {
+	procs := m.ProcessorNames()
+	if len(procs) == 0 {
+		return nil, fmt.Errorf("the memory map has no processors, was the design built with block RAM for a processor?")
+	}
+	var dfs []DataFile
+	seen := map[string]bool{}
+	for _, d := range data {
+		proc, file, ok := strings.Cut(d, "=")
+		if !ok || file == "" {
+			return nil, fmt.Errorf("invalid --data, want proc=file, got: %q", d)
+		}
+		ext := path.Ext(file)
+		if ext != dataExtensions[0] && ext != dataExtensions[1] {
+			return nil, fmt.Errorf("invalid --data %q: want one of %v, got: %q",
+				d, strings.Join(dataExtensions, ", "), ext)
+		}
+		if proc == "" {
+			if len(procs) != 1 {
+				return nil, fmt.Errorf("the memory map has %d processors, name one for %v: %v",
+					len(procs), file, strings.Join(procs, ", "))
+			}
+			proc = procs[0]
+		}
+		found := false
+		for _, p := range procs {
+			found = found || p == proc
+		}
+		if !found {
+			return nil, fmt.Errorf("no processor %q in the memory map, want one of: %v",
+				proc, strings.Join(procs, ", "))
+		}
+		if seen[proc] {
+			return nil, fmt.Errorf("more than one data file for processor %q", proc)
+		}
+		seen[proc] = true
+		dfs = append(dfs, DataFile{Proc: proc, File: file})
+	}
+	if len(dfs) == 0 {
+		return nil, fmt.Errorf("param --data is required")
+	}
+	return dfs, nil
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("updatememgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var outArgs, memInfo, bitFile, outBit string
+	fs.StringVar(&outArgs, "out-args", "", "The updatemem argument file to write")
+	fs.StringVar(&memInfo, "meminfo", "", "The block RAM memory map (.mmi) of the design")
+	fs.StringVar(&bitFile, "bitfile", "", "The routed bitstream to patch")
+	fs.StringVar(&outBit, "out-bit", "", "The patched bitstream to write")
+	var data flags.Strings
+	fs.Var(&data, "data", "each is: proc=file, the .elf or .mem contents of a processor; proc may be empty if there is only one")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	for _, p := range []struct{ name, value string }{
+		{"out-args", outArgs},
+		{"meminfo", memInfo},
+		{"bitfile", bitFile},
+		{"out-bit", outBit},
+	} {
+		if p.value == "" {
+			return fmt.Errorf("param --%v is required", p.name)
+		}
+	}
+
+	m, err := ReadMemInfo(memInfo)
+	if err != nil {
+		return err
+	}
+	dfs, err := resolve(m, data)
+	if err != nil {
+		return fmt.Errorf("%v: %w", memInfo, err)
+	}
+
+	lines := []string{"-force", "-meminfo", memInfo, "-bit", bitFile}
+	for _, df := range dfs {
+		lines = append(lines, "-data", df.File, "-proc", df.Proc)
+	}
+	lines = append(lines, "-out", outBit)
+	if err := os.WriteFile(outArgs, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("write %v: %w", outArgs, err)
+	}
+	return nil
+}
+
+func runCLI(osArgs []string, stdout, stderr io.Writer) error {
+	p := path.Base(osArgs[0])
+	log.SetPrefix(fmt.Sprintf("%v: ", p))
+
+	return run(osArgs[1:], stdout, stderr)
+}
+
+func main() {
+	if err := runCLI(os.Args, os.Stdout, os.Stderr); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+}
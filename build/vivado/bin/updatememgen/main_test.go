@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testMMI = `<?xml version="1.0" encoding="UTF-8"?>
+<MemInfo Version="1" Minor="0">
+  <Processor Endianness="Little" InstPath="design_1_i/microblaze_0">
+    <AddressSpace Name="design_1_i_microblaze_0.dlmb_bram_if_cntlr" Begin="0" End="8191">
+      <BusBlock>
+        <BitLane MemType="RAMB36" Placement="X0Y1">
+          <DataWidth MSB="31" LSB="0"/>
+          <AddressRange Begin="0" End="2047"/>
+          <Parity ON="false" NumBits="0"/>
+        </BitLane>
+      </BusBlock>
+    </AddressSpace>
+  </Processor>
+  <Processor Endianness="Little" InstPath="design_1_i/microblaze_1">
+  </Processor>
+  <Config>
+    <Option Name="Part" Val="xc7a35ticsg324-1L"/>
+  </Config>
+</MemInfo>
+`
+
+func TestRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	mmi := filepath.Join(tmpDir, "top.mmi")
+	if err := os.WriteFile(mmi, []byte(testMMI), 0644); err != nil {
+		t.Fatal(err)
+	}
+	single := filepath.Join(tmpDir, "single.mmi")
+	if err := os.WriteFile(single, []byte(`<MemInfo><Processor InstPath="cpu"/></MemInfo>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(tmpDir, "empty.mmi")
+	if err := os.WriteFile(empty, []byte(`<MemInfo></MemInfo>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outArgs := filepath.Join(tmpDir, "updatemem.args")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Two processors",
+			args: []string{"--meminfo", mmi, "--bitfile", "top.bit", "--out-bit", "fw.bit",
+				"--data", "design_1_i/microblaze_0=fw0.elf",
+				"--data", "design_1_i/microblaze_1=fw1.mem"},
+			want: "-force\n-meminfo\n" + mmi + "\n-bit\ntop.bit\n" +
+				"-data\nfw0.elf\n-proc\ndesign_1_i/microblaze_0\n" +
+				"-data\nfw1.mem\n-proc\ndesign_1_i/microblaze_1\n" +
+				"-out\nfw.bit\n",
+		},
+		{
+			name: "Only processor",
+			args: []string{"--meminfo", single, "--bitfile", "top.bit", "--out-bit", "fw.bit",
+				"--data", "=fw.elf"},
+			want: "-force\n-meminfo\n" + single + "\n-bit\ntop.bit\n" +
+				"-data\nfw.elf\n-proc\ncpu\n-out\nfw.bit\n",
+		},
+		{
+			name: "Processor must be named",
+			args: []string{"--meminfo", mmi, "--bitfile", "top.bit", "--out-bit", "fw.bit",
+				"--data", "=fw.elf"},
+			wantErr: true,
+		},
+		{
+			name: "Unknown processor",
+			args: []string{"--meminfo", mmi, "--bitfile", "top.bit", "--out-bit", "fw.bit",
+				"--data", "design_1_i/microblaze_2=fw.elf"},
+			wantErr: true,
+		},
+		{
+			name: "Same processor twice",
+			args: []string{"--meminfo", single, "--bitfile", "top.bit", "--out-bit", "fw.bit",
+				"--data", "=fw.elf", "--data", "cpu=fw.mem"},
+			wantErr: true,
+		},
+		{
+			name: "Bad data file type",
+			args: []string{"--meminfo", single, "--bitfile", "top.bit", "--out-bit", "fw.bit",
+				"--data", "=fw.hex"},
+			wantErr: true,
+		},
+		{
+			name: "No processors",
+			args: []string{"--meminfo", empty, "--bitfile", "top.bit", "--out-bit", "fw.bit",
+				"--data", "=fw.elf"},
+			wantErr: true,
+		},
+		{
+			name:    "No data",
+			args:    []string{"--meminfo", single, "--bitfile", "top.bit", "--out-bit", "fw.bit"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing bitfile",
+			args:    []string{"--meminfo", single, "--out-bit", "fw.bit", "--data", "=fw.elf"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(outArgs)
+			args := append([]string{"--out-args", outArgs}, tt.args...)
+			err := run(args, &bytes.Buffer{}, &bytes.Buffer{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			b, err := os.ReadFile(outArgs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("run() wrote %q, want %q", string(b), tt.want)
+			}
+		})
+	}
+}
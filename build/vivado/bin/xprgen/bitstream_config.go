@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cp/build/vivado/lib/bitstream"
+)
+
+// Property is a single design property, set with `set_property` on the
+// current design before the bitstream is written.
+type Property struct {
+	Name  string
+	Value string
+}
+
+// BitstreamConfig holds the device configuration options that end up in the
+// bitstream. The zero value leaves all of them at the Vivado defaults.
+type BitstreamConfig struct {
+	// Compress enables bitstream compression.
+	Compress bool
+	// ConfigVoltage is the configuration bank voltage, e.g. "3.3".
+	ConfigVoltage string
+	// CFGBVS is the configuration bank voltage select, "VCCO" or "GND".
+	CFGBVS string
+	// SPIBusWidth is the SPI flash bus width, 1, 2, 4 or 8. 0 means unset.
+	SPIBusWidth int
+	// ConfigRate is the master configuration clock rate in MHz, e.g. "33".
+	ConfigRate string
+	// UserID is a 32-bit value readable over JTAG, e.g. "0xDEADBEEF".
+	UserID string
+	// UsrAccess is a 32-bit value readable from the fabric through the
+	// USR_ACCESS primitive, or "TIMESTAMP".
+	UsrAccess string
+}
+
+// IsSet returns true if any of the options differs from its default.
+func (c BitstreamConfig) IsSet() bool {
+	return c != BitstreamConfig{}
+}
+
+var (
+	configVoltages = map[bitstream.Family][]string{
+		bitstream.Family7Series:        {"1.5", "1.8", "2.5", "3.3"},
+		bitstream.FamilyUltraScale:     {"1.5", "1.8", "2.5", "3.3"},
+		bitstream.FamilyUltraScalePlus: {"1.5", "1.8"},
+	}
+	spiBusWidths = map[bitstream.Family][]int{
+		bitstream.Family7Series:        {1, 2, 4},
+		bitstream.FamilyUltraScale:     {1, 2, 4, 8},
+		bitstream.FamilyUltraScalePlus: {1, 2, 4, 8},
+	}
+	// The 7-series master configuration clock only comes in these rates.
+	// UltraScale devices accept a finer grid, which Vivado checks itself.
+	configRates7Series = []string{"3", "6", "9", "12", "16", "22", "26", "33", "40", "50", "66"}
+	maxConfigRate      = map[bitstream.Family]float64{
+		bitstream.Family7Series:        66,
+		bitstream.FamilyUltraScale:     150,
+		bitstream.FamilyUltraScalePlus: 150,
+	}
+)
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// parseWord parses a 32-bit value given in hex, e.g. "0xDEADBEEF", and returns
+// it formatted the way Vivado expects it.
+func parseWord(v string) (string, error) {
+	h := strings.TrimPrefix(strings.TrimPrefix(v, "0x"), "0X")
+	if h == v || h == "" {
+		return "", fmt.Errorf("want a hex value like 0x0123ABCD, got: %q", v)
+	}
+	n, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return "", fmt.Errorf("want a 32-bit hex value, got %q: %w", v, err)
+	}
+	return fmt.Sprintf("0x%08X", n), nil
+}
+
+// Validate checks that the options are valid for the family of part.
+func (c BitstreamConfig) Validate(part string) error {
+	if !c.IsSet() {
+		return nil
+	}
+	if part == "" {
+		return fmt.Errorf("param --part is required for bitstream options")
+	}
+	family := bitstream.PartFamily(part)
+	if family == bitstream.FamilyUnknown {
+		return fmt.Errorf("bitstream options are not supported for part %q", part)
+	}
+
+	if c.ConfigVoltage != "" && !contains(configVoltages[family], c.ConfigVoltage) {
+		return fmt.Errorf("config voltage %q is not valid for %v part %q, want one of: %v",
+			c.ConfigVoltage, family, part, strings.Join(configVoltages[family], ", "))
+	}
+	switch c.CFGBVS {
+	case "":
+	case "VCCO", "GND":
+		if family == bitstream.FamilyUltraScalePlus {
+			return fmt.Errorf("CFGBVS is not available on %v part %q", family, part)
+		}
+		// A GND bank voltage select only supports the low voltages, and
+		// VCCO only the high ones. Vivado flags the mismatch as CFGBVS-1.
+		switch c.ConfigVoltage {
+		case "1.5", "1.8":
+			if c.CFGBVS == "VCCO" {
+				return fmt.Errorf("CFGBVS VCCO requires a config voltage of 2.5 or 3.3, got: %v", c.ConfigVoltage)
+			}
+		case "2.5", "3.3":
+			if c.CFGBVS == "GND" {
+				return fmt.Errorf("CFGBVS GND requires a config voltage of 1.5 or 1.8, got: %v", c.ConfigVoltage)
+			}
+		}
+	default:
+		return fmt.Errorf("CFGBVS must be VCCO or GND, got: %q", c.CFGBVS)
+	}
+	if c.SPIBusWidth != 0 {
+		ok := false
+		for _, w := range spiBusWidths[family] {
+			ok = ok || w == c.SPIBusWidth
+		}
+		if !ok {
+			return fmt.Errorf("SPI bus width %d is not valid for %v part %q, want one of: %v",
+				c.SPIBusWidth, family, part, spiBusWidths[family])
+		}
+	}
+	if c.ConfigRate != "" {
+		r, err := strconv.ParseFloat(c.ConfigRate, 64)
+		if err != nil || r <= 0 || r > maxConfigRate[family] {
+			return fmt.Errorf("config rate %q is not valid for %v part %q, want a rate in MHz up to %v",
+				c.ConfigRate, family, part, maxConfigRate[family])
+		}
+		if family == bitstream.Family7Series && !contains(configRates7Series, c.ConfigRate) {
+			return fmt.Errorf("config rate %q is not valid for %v part %q, want one of: %v",
+				c.ConfigRate, family, part, strings.Join(configRates7Series, ", "))
+		}
+	}
+	if c.UserID != "" {
+		if _, err := parseWord(c.UserID); err != nil {
+			return fmt.Errorf("USERID: %w", err)
+		}
+	}
+	if c.UsrAccess != "" && c.UsrAccess != "TIMESTAMP" {
+		if _, err := parseWord(c.UsrAccess); err != nil {
+			return fmt.Errorf("USR_ACCESS: %w", err)
+		}
+	}
+	return nil
+}
+
+// Properties returns the design properties to set for the options. The
+// options must have been validated first.
+func (c BitstreamConfig) Properties() []Property {
+	var ps []Property
+	if c.Compress {
+		ps = append(ps, Property{"BITSTREAM.GENERAL.COMPRESS", "TRUE"})
+	}
+	if c.ConfigVoltage != "" {
+		ps = append(ps, Property{"CONFIG_VOLTAGE", c.ConfigVoltage})
+	}
+	if c.CFGBVS != "" {
+		ps = append(ps, Property{"CFGBVS", c.CFGBVS})
+	}
+	if c.SPIBusWidth != 0 {
+		ps = append(ps, Property{"BITSTREAM.CONFIG.SPI_BUSWIDTH", strconv.Itoa(c.SPIBusWidth)})
+	}
+	if c.ConfigRate != "" {
+		ps = append(ps, Property{"BITSTREAM.CONFIG.CONFIGRATE", c.ConfigRate})
+	}
+	if c.UserID != "" {
+		v, _ := parseWord(c.UserID)
+		ps = append(ps, Property{"BITSTREAM.CONFIG.USERID", v})
+	}
+	if c.UsrAccess == "TIMESTAMP" {
+		ps = append(ps, Property{"BITSTREAM.CONFIG.USR_ACCESS", c.UsrAccess})
+	} else if c.UsrAccess != "" {
+		v, _ := parseWord(c.UsrAccess)
+		ps = append(ps, Property{"BITSTREAM.CONFIG.USR_ACCESS", v})
+	}
+	return ps
+}
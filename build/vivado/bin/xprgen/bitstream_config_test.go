@@ -0,0 +1,142 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBitstreamConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		part    string
+		c       BitstreamConfig
+		wantErr bool
+	}{
+		{
+			name: "Defaults need no part",
+		},
+		{
+			name: "7-series",
+			part: "xc7a200tfbg484-2",
+			c: BitstreamConfig{
+				Compress:      true,
+				ConfigVoltage: "3.3",
+				CFGBVS:        "VCCO",
+				SPIBusWidth:   4,
+				ConfigRate:    "33",
+				UserID:        "0xdeadbeef",
+				UsrAccess:     "TIMESTAMP",
+			},
+		},
+		{
+			name: "UltraScale+",
+			part: "xczu3eg-sbva484-1-e",
+			c: BitstreamConfig{
+				ConfigVoltage: "1.8",
+				SPIBusWidth:   8,
+				ConfigRate:    "102",
+				UsrAccess:     "0x12345678",
+			},
+		},
+		{
+			name:    "Missing part",
+			c:       BitstreamConfig{Compress: true},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown family",
+			part:    "xc3s500e",
+			c:       BitstreamConfig{Compress: true},
+			wantErr: true,
+		},
+		{
+			name:    "3.3V on UltraScale+",
+			part:    "xczu3eg-sbva484-1-e",
+			c:       BitstreamConfig{ConfigVoltage: "3.3"},
+			wantErr: true,
+		},
+		{
+			name:    "CFGBVS on UltraScale+",
+			part:    "xcvu9p-flga2104-2L-e",
+			c:       BitstreamConfig{CFGBVS: "GND"},
+			wantErr: true,
+		},
+		{
+			name:    "CFGBVS GND at 3.3V",
+			part:    "xc7a200tfbg484-2",
+			c:       BitstreamConfig{ConfigVoltage: "3.3", CFGBVS: "GND"},
+			wantErr: true,
+		},
+		{
+			name:    "Bad CFGBVS",
+			part:    "xc7a200tfbg484-2",
+			c:       BitstreamConfig{CFGBVS: "VCC"},
+			wantErr: true,
+		},
+		{
+			name:    "SPIx8 on 7-series",
+			part:    "xc7a200tfbg484-2",
+			c:       BitstreamConfig{SPIBusWidth: 8},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported 7-series rate",
+			part:    "xc7a200tfbg484-2",
+			c:       BitstreamConfig{ConfigRate: "34"},
+			wantErr: true,
+		},
+		{
+			name:    "Rate too high",
+			part:    "xcku040-ffva1156-2-e",
+			c:       BitstreamConfig{ConfigRate: "200"},
+			wantErr: true,
+		},
+		{
+			name:    "USERID not hex",
+			part:    "xc7a200tfbg484-2",
+			c:       BitstreamConfig{UserID: "1234"},
+			wantErr: true,
+		},
+		{
+			name:    "USR_ACCESS too wide",
+			part:    "xc7a200tfbg484-2",
+			c:       BitstreamConfig{UsrAccess: "0x123456789"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.c.Validate(tt.part)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBitstreamConfigProperties(t *testing.T) {
+	c := BitstreamConfig{
+		Compress:      true,
+		ConfigVoltage: "1.8",
+		CFGBVS:        "GND",
+		SPIBusWidth:   4,
+		ConfigRate:    "50",
+		UserID:        "0xbeef",
+		UsrAccess:     "TIMESTAMP",
+	}
+	want := []Property{
+		{"BITSTREAM.GENERAL.COMPRESS", "TRUE"},
+		{"CONFIG_VOLTAGE", "1.8"},
+		{"CFGBVS", "GND"},
+		{"BITSTREAM.CONFIG.SPI_BUSWIDTH", "4"},
+		{"BITSTREAM.CONFIG.CONFIGRATE", "50"},
+		{"BITSTREAM.CONFIG.USERID", "0x0000BEEF"},
+		{"BITSTREAM.CONFIG.USR_ACCESS", "TIMESTAMP"},
+	}
+	if got := c.Properties(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Properties() = %v, want %v", got, want)
+	}
+	if got := (BitstreamConfig{}).Properties(); len(got) != 0 {
+		t.Errorf("Properties() of defaults = %v, want none", got)
+	}
+}
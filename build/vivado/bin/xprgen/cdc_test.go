@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunCDCReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		args     []string
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "Synth",
+			template: "../../synth_batch.tcl.template",
+			args:     []string{"--cdc-report", "top.cdc.rpt", "--clock-interaction-report", "top.ci.rpt"},
+			want:     []string{"report_cdc -details -file top.cdc.rpt\nreport_clock_interaction -file top.ci.rpt"},
+		},
+		{
+			name:     "Synth without reports",
+			template: "../../synth_batch.tcl.template",
+			notWant:  []string{"report_cdc", "report_clock_interaction"},
+		},
+		{
+			name:     "PnR",
+			template: "../../pnr_batch.tcl.template",
+			args:     []string{"--cdc-report", "top.cdc.rpt", "--clock-interaction-report", "top.ci.rpt"},
+			want:     []string{"report_cdc -details -file top.cdc.rpt\nreport_clock_interaction -file top.ci.rpt"},
+		},
+		{
+			name:     "PnR without reports",
+			template: "../../pnr_batch.tcl.template",
+			notWant:  []string{"report_cdc", "report_clock_interaction"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderTemplate(t, tt.template, tt.args...)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("file content = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("file content = %q, want no %q", got, notWant)
+				}
+			}
+		})
+	}
+}
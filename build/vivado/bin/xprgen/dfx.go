@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"cp/build/vivado/lib/bitstream"
+)
+
+// DfxCell is a reconfigurable partition of a dynamic function exchange (DFX)
+// configuration, and the reconfigurable module that fills it.
+type DfxCell struct {
+	// Cell is the hierarchical instance path of the partition.
+	Cell string
+	// DcpFile is the out-of-context checkpoint of the reconfigurable module.
+	// If empty, the partition is left as a black box, with buffered ports.
+	DcpFile string
+	// PartialBitstream is the partial bitstream of the partition to write, if
+	// set.
+	PartialBitstream string
+}
+
+// parseDfxCell parses a cell=dcp[=partial.bit] flag value.
+func parseDfxCell(v string) (DfxCell, error) {
+	s := strings.Split(v, "=")
+	if len(s) < 2 || len(s) > 3 || s[0] == "" {
+		return DfxCell{}, fmt.Errorf("invalid format for dfx-cell, expected cell=dcp[=partial.bit], got: %v", v)
+	}
+	c := DfxCell{Cell: s[0], DcpFile: s[1]}
+	if len(s) == 3 {
+		c.PartialBitstream = s[2]
+	}
+	if c.DcpFile == "" && c.PartialBitstream != "" {
+		return DfxCell{}, fmt.Errorf("dfx-cell %v: a black box has no partial bitstream", c.Cell)
+	}
+	return c, nil
+}
+
+// validateDfx checks that part supports the DFX flow that the templates
+// implement.
+func validateDfx(part string) error {
+	if part == "" {
+		return fmt.Errorf("param --part is required for DFX")
+	}
+	switch family := bitstream.PartFamily(part); family {
+	case bitstream.Family7Series, bitstream.FamilyUltraScalePlus:
+		return nil
+	case bitstream.FamilyUltraScale:
+		// UltraScale needs a clearing bitstream loaded before each partial
+		// bitstream, which the flow does not write.
+		return fmt.Errorf("DFX is not supported on %v part %q, want 7-series or UltraScale+", family, part)
+	default:
+		return fmt.Errorf("DFX is not supported for part %q, want 7-series or UltraScale+", part)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDfxCell(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       string
+		want    DfxCell
+		wantErr bool
+	}{
+		{"Module", "u_rp=rm_a.dcp=rm_a.bit", DfxCell{"u_rp", "rm_a.dcp", "rm_a.bit"}, false},
+		{"No partial", "u_rp=rm_a.dcp", DfxCell{"u_rp", "rm_a.dcp", ""}, false},
+		{"Black box", "u_rp=", DfxCell{"u_rp", "", ""}, false},
+		{"No module", "u_rp", DfxCell{}, true},
+		{"No cell", "=rm_a.dcp", DfxCell{}, true},
+		{"Black box partial", "u_rp==rm_a.bit", DfxCell{}, true},
+		{"Too many fields", "u_rp=a=b=c", DfxCell{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDfxCell(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDfxCell() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseDfxCell() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateDfx(t *testing.T) {
+	tests := []struct {
+		part    string
+		wantErr bool
+	}{
+		{"xc7a200tfbg484-2", false},
+		{"xczu3eg-sbva484-1-e", false},
+		{"xcau15p-ffvb676-2-e", false},
+		{"xcku040-ffva1156-2-e", true},
+		{"xc3s500e", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		if err := validateDfx(tt.part); (err != nil) != tt.wantErr {
+			t.Errorf("validateDfx(%q) error = %v, wantErr %v", tt.part, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRunDfx(t *testing.T) {
+	tmpDir := t.TempDir()
+	outFile := filepath.Join(tmpDir, "dfx.tcl")
+	touchFiles(t, tmpDir, "floorplan.xdc", "static_synth.dcp", "rm_a.dcp", "rm_x.dcp", "static.dcp", "rm_b.dcp")
+
+	// The first configuration defines the partitions and writes the static
+	// design.
+	args := []string{
+		"--custom-template", absPath(t, "../../dfx_batch.tcl.template"),
+		"--custom-filename", outFile,
+		"--exec-root", tmpDir,
+		"--part", "xc7a200tfbg484-2",
+		"--load-dcp", "static_synth.dcp",
+		"--save-dcp", "config0.dcp",
+		"--bitstream", "config0.bit",
+		"--constraints", "floorplan.xdc",
+		"--dfx-cell", "u_rp0=rm_a.dcp=rm_a.partial.bit",
+		"--dfx-cell", "u_rp1=rm_x.dcp=rm_x.partial.bit",
+		"--dfx-static-dcp", "static.dcp",
+	}
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	b, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	for _, want := range []string{
+		"read_checkpoint -cell u_rp0 rm_a.dcp\nread_checkpoint -cell u_rp1 rm_x.dcp\n",
+		"set_property HD.RECONFIGURABLE 1 [get_cells u_rp0]\nset_property HD.RECONFIGURABLE 1 [get_cells u_rp1]\n",
+		"read_xdc {floorplan.xdc}",
+		"write_bitstream -force -no_partial_bitfile config0.bit",
+		"write_bitstream -force -cell u_rp0 rm_a.partial.bit",
+		"write_bitstream -force -cell u_rp1 rm_x.partial.bit",
+		"update_design -cell u_rp0 -black_box\nupdate_design -cell u_rp1 -black_box\nlock_design -level routing\nwrite_checkpoint -force static.dcp",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("file content = %q, want it to contain %q", string(b), want)
+		}
+	}
+
+	// Another configuration builds on the locked static design, and may
+	// leave a partition empty.
+	args = []string{
+		"--custom-template", absPath(t, "../../dfx_batch.tcl.template"),
+		"--custom-filename", outFile,
+		"--exec-root", tmpDir,
+		"--part", "xczu3eg-sbva484-1-e",
+		"--load-dcp", "static.dcp",
+		"--save-dcp", "config1.dcp",
+		"--bitstream", "config1.bit",
+		"--dfx-cell", "u_rp0=rm_b.dcp=rm_b.partial.bit",
+		"--dfx-cell", "u_rp1=",
+	}
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	b, err = os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	for _, want := range []string{
+		"read_checkpoint -cell u_rp0 rm_b.dcp\nupdate_design -cell u_rp1 -buffer_ports\n",
+		"write_bitstream -force -cell u_rp0 rm_b.partial.bit",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("file content = %q, want it to contain %q", string(b), want)
+		}
+	}
+	for _, notWant := range []string{"HD.RECONFIGURABLE", "lock_design", "write_bitstream -force -cell u_rp1"} {
+		if strings.Contains(string(b), notWant) {
+			t.Errorf("file content = %q, want no %q", string(b), notWant)
+		}
+	}
+
+	args = append(args, "--part", "xcku040-ffva1156-2-e")
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Errorf("run() for an UltraScale part succeeded, want error")
+	}
+}
+
+func TestRunPrVerify(t *testing.T) {
+	tmpDir := t.TempDir()
+	outFile := filepath.Join(tmpDir, "pr_verify.tcl")
+	touchFiles(t, tmpDir, "config0.dcp", "config1.dcp", "config2.dcp")
+	args := []string{
+		"--custom-template", absPath(t, "../../pr_verify_batch.tcl.template"),
+		"--custom-filename", outFile,
+		"--exec-root", tmpDir,
+		"--load-dcp", "config0.dcp",
+		"--pr-verify-dcp", "config1.dcp",
+		"--pr-verify-dcp", "config2.dcp",
+		"--pr-verify-report", "pr_verify.rpt",
+	}
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	b, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	want := "pr_verify -full_check -file pr_verify.rpt \\\n    -initial config0.dcp \\\n    -additional { config1.dcp config2.dcp }"
+	if !strings.Contains(string(b), want) {
+		t.Errorf("file content = %q, want it to contain %q", string(b), want)
+	}
+}
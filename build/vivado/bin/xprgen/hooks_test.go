@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	touchFiles(t, tmpDir, "hooks/a.tcl", "hooks/b.tcl", "hooks/c.tcl", "pre.tcl", "post.tcl")
+
+	tests := []struct {
+		name     string
+		template string
+		args     []string
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "Synth",
+			template: "../../synth_batch.tcl.template",
+			args: []string{
+				"--pre-synth-hook", "hooks/a.tcl",
+				"--pre-synth-hook", "hooks/b.tcl",
+				"--post-synth-hook", "hooks/c.tcl",
+				"--post-synth-design", "puts done",
+			},
+			want: []string{
+				"source {hooks/a.tcl}\nsource {hooks/b.tcl}\nsynth_design -top top",
+				"puts done\nsource {hooks/c.tcl}\n",
+			},
+		},
+		{
+			name:     "Opt",
+			template: "../../opt_batch.tcl.template",
+			args:     []string{"--pre-opt-hook", "pre.tcl", "--post-opt-hook", "post.tcl"},
+			want:     []string{"source {pre.tcl}\nopt_design \nsource {post.tcl}\n"},
+		},
+		{
+			name:     "Place",
+			template: "../../place_batch.tcl.template",
+			args:     []string{"--pre-place-hook", "pre.tcl", "--post-place-hook", "post.tcl"},
+			want:     []string{"source {pre.tcl}\nplace_design \nsource {post.tcl}\n"},
+		},
+		{
+			name:     "Phys opt",
+			template: "../../phys_opt_batch.tcl.template",
+			args:     []string{"--pre-phys-opt-hook", "pre.tcl", "--post-phys-opt-hook", "post.tcl"},
+			want:     []string{"source {pre.tcl}\nphys_opt_design \nsource {post.tcl}\n"},
+		},
+		{
+			name:     "Route",
+			template: "../../route_batch.tcl.template",
+			args:     []string{"--pre-route-hook", "pre.tcl", "--post-route-hook", "post.tcl"},
+			want:     []string{"source {pre.tcl}\nroute_design \nsource {post.tcl}\n"},
+		},
+		{
+			name:     "Bitstream",
+			template: "../../pnr_batch.tcl.template",
+			args:     []string{"--pre-bitstream-hook", "pre.tcl", "--post-bitstream-hook", "post.tcl"},
+			want:     []string{"source {pre.tcl}\nif { [catch { write_bitstream", "}\nsource {post.tcl}\n"},
+		},
+		{
+			name:     "No hooks",
+			template: "../../pnr_batch.tcl.template",
+			notWant:  []string{"source "},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderTemplate(t, tt.template, append([]string{"--exec-root", tmpDir}, tt.args...)...)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("file content = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("file content = %q, want no %q", got, notWant)
+				}
+			}
+		})
+	}
+}
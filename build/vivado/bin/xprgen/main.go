@@ -3,6 +3,12 @@
 // The project file is the basis on which Vivado tools operate,
 // so though it's weird from the perspective of software compilation
 // tools, we're pretty much stuck with it.
+//
+// The project, synthesis and implementation scripts are rendered from the
+// templates in templates/, which are built into the binary. --template-dir
+// overrides them, and --custom-template renders any other template, such
+// as the batch templates of the non-project flow. All templates render an
+// XPRBinding, with the functions in templateFuncs.
 package main
 
 import (
@@ -14,6 +20,8 @@ import (
 	"path"
 	"strings"
 	"text/template"
+
+	"cp/build/vivado/lib/buildstamp"
 )
 
 const (
@@ -23,6 +31,9 @@ const (
 	VHDLExtension2         = ".vhdl"
 )
 
+// XPRBinding is the data model of the templates. Every template, built-in
+// or custom, executes with one XPRBinding as its dot, e.g. `{{ .Top }}` is
+// the name of the top entity, and can call the functions in templateFuncs.
 type XPRBinding struct {
 	// Project name.
 	Project string
@@ -39,7 +50,7 @@ type XPRBinding struct {
 	// The list of SystemVerilog files to load.
 	SystemVerilogFiles []FileLib
 	// The list of SystemVerilog headers to load.
-	VerilogHeaders []string
+	VerilogHeaders []FileLib
 	// VerilogIncludeDirs is a list of include dirs to load.
 	VerilogIncludeDirs []string
 	// VHDLFiles is a list of VHDL files to load.
@@ -50,6 +61,10 @@ type XPRBinding struct {
 	XDCFiles []string
 	// PWD is the working directory.
 	PWD string
+	// WorkDir is the directory that the script changes to before it runs
+	// any Vivado command, relative to the exec root that Vivado starts in.
+	// Empty if Vivado stays in the exec root.
+	WorkDir string
 	// OutXpr is the name of the Vivado project file (.xpr) that will be generated.
 	OutXpr string
 	// Part is the designator of the FPGA part to be programmed.
@@ -65,14 +80,64 @@ type XPRBinding struct {
 	// BistreamName is an optional name of the bitstream to generate.
 	BitstreamName string
 
+	// AllowDummyOutputs, if set, makes the batch scripts write placeholder
+	// bitstream and probes files when Vivado fails to produce them, instead
+	// of failing the build.
+	AllowDummyOutputs bool
+
+	// BitstreamConfig holds the configuration options to set before
+	// `write_bitstream`.
+	BitstreamConfig BitstreamConfig
+	// BuildStamp describes the build provenance encoded into the bitstream
+	// configuration, if stamping is enabled.
+	BuildStamp string
+
 	TimingSummaryFile, UtilizationFile, DRCFile string
 	SynthFileName, PnrFileName, CustomFileName  string
 	ProbesFile                                  string
-
+	// HierUtilizationFile is the file to write the
+	// `report_utilization -hierarchical` report to, if set.
+	HierUtilizationFile string
+	// CDCFile is the file to write the `report_cdc -details` report to, if
+	// set.
+	CDCFile string
+	// ClockInteractionFile is the file to write the
+	// `report_clock_interaction` report to, if set.
+	ClockInteractionFile string
+	// CheckTimingFile is the file to write the `check_timing -verbose`
+	// report to, if set.
+	CheckTimingFile string
+	// MethodologyFile is the file to write the `report_methodology` report
+	// to, if set.
+	MethodologyFile string
+	// TimingPathsFile is the file to write the worst setup and hold paths
+	// of `report_timing` to, if set.
+	TimingPathsFile string
+	// TimingMaxPaths is the number of paths per path group, and
+	// TimingNworst the number of paths per endpoint, to report.
+	TimingMaxPaths, TimingNworst int
+	// PowerReportFile is the file to write the `report_power` report to, if
+	// set.
+	PowerReportFile string
+	// PowerAnalysis is the switching activity for `report_power`.
+	PowerAnalysis PowerAnalysis
+	// MemInfoFile is the memory map (.mmi) of the block RAMs to write, if
+	// set. updatemem uses it to place new memory contents into the
+	// bitstream.
+	MemInfoFile string
+
+	// OptDesignOptions are appended to `opt_design` line.
+	OptDesignOptions string
+	// PostOptDesign are appended after `opt_design` line.
+	PostOptDesign []string
 	// PlaceDesignOptions are appended to `place_design` line.
 	PlaceDesignOptions string
 	// PostPlaceDesignOptions are appended after `place_design` line.
 	PostPlaceDesign []string
+	// PhysOptDesignOptions are appended to `phys_opt_design` line.
+	PhysOptDesignOptions string
+	// PostPhysOptDesign are appended after `phys_opt_design` line.
+	PostPhysOptDesign []string
 	// RouteDesignOptions are appended to `route_design` line.
 	RouteDesignOptions string
 	// PostRouteDesign are appended after `route_design` line.
@@ -82,6 +147,87 @@ type XPRBinding struct {
 	SynthDesignOptions string
 	// PostSynthDesign are appended after `synth_design` line.
 	PostSynthDesign []string
+
+	// SynthHooks are the TCL files to source around `synth_design`.
+	SynthHooks Hooks
+	// OptHooks are the TCL files to source around `opt_design`.
+	OptHooks Hooks
+	// PlaceHooks are the TCL files to source around `place_design`.
+	PlaceHooks Hooks
+	// PhysOptHooks are the TCL files to source around `phys_opt_design`.
+	PhysOptHooks Hooks
+	// RouteHooks are the TCL files to source around `route_design`.
+	RouteHooks Hooks
+	// BitstreamHooks are the TCL files to source around `write_bitstream`.
+	BitstreamHooks Hooks
+
+	// OutOfContext, if set, synthesizes the top as an out-of-context
+	// partition, without I/O buffers, to be linked into another design.
+	OutOfContext bool
+	// StubFile is the synthesis stub of the top to write, if set. The
+	// language follows the file extension.
+	StubFile string
+	// OOCCells are the out-of-context checkpoints to link into black box
+	// cells of the design after synthesis.
+	OOCCells []OOCCell
+
+	// FuncsimFile is the functional simulation netlist to write, if set.
+	// The language follows the file extension.
+	FuncsimFile string
+	// TimesimFile is the timing simulation netlist to write, if set. It is
+	// always Verilog, and annotates the delays in SdfFile.
+	TimesimFile string
+	// SdfFile is the standard delay format (SDF) file to write, if set.
+	SdfFile string
+
+	// DfxCells are the reconfigurable partitions of a dynamic function
+	// exchange (DFX) configuration.
+	DfxCells []DfxCell
+	// DfxStaticFile, if set, marks the first DFX configuration, which
+	// defines the partitions. The locked static design, with all partitions
+	// as black boxes, is written to it for the other configurations.
+	DfxStaticFile string
+	// PrVerifyDcps are the routed checkpoints of the DFX configurations
+	// that `pr_verify` checks against the one in LoadDcpFile.
+	PrVerifyDcps []string
+	// PrVerifyFile is the file to write the `pr_verify` report to.
+	PrVerifyFile string
+}
+
+// Hooks are the TCL files that a step sources, in order, before and after
+// its main command.
+type Hooks struct {
+	Pre  []string
+	Post []string
+}
+
+// OOCCell is a black box cell that is filled from an out-of-context
+// checkpoint.
+type OOCCell struct {
+	// Cell is the hierarchical instance path of the cell.
+	Cell string
+	// DcpFile is the out-of-context checkpoint.
+	DcpFile string
+}
+
+// netlistWriter returns the Vivado command that writes the netlist fn, in
+// the language of its extension.
+func netlistWriter(fn string) string {
+	switch path.Ext(fn) {
+	case VHDLExtension1, VHDLExtension2:
+		return "write_vhdl"
+	}
+	return "write_verilog"
+}
+
+// StubWriter returns the Vivado command that writes StubFile.
+func (x XPRBinding) StubWriter() string {
+	return netlistWriter(x.StubFile)
+}
+
+// FuncsimWriter returns the Vivado command that writes FuncsimFile.
+func (x XPRBinding) FuncsimWriter() string {
+	return netlistWriter(x.FuncsimFile)
 }
 
 var _ flag.Value = (*RepeatedString)(nil)
@@ -158,9 +304,14 @@ func run(args []string, stdout, stderr io.Writer) error {
 	fs.SetOutput(stderr)
 
 	// Vivado is unable to create a project in any directory other than its
-	// PWD. So we need to account for that when generating.
-	var dirDepth int
-	fs.IntVar(&dirDepth, "dir-depth", 0, "The depth of the directory structure")
+	// PWD. Vivado starts in the exec root, and the scripts change to the
+	// work dir first. So the input and output paths, which are relative to
+	// the exec root, are rewritten relative to the work dir, and the inputs
+	// must exist. The templates xprgen reads are resolved against the exec
+	// root.
+	var execRoot, workDir string
+	fs.StringVar(&execRoot, "exec-root", ".", "The directory that the input and output paths are relative to, and that Vivado starts in")
+	fs.StringVar(&workDir, "work-dir", "", "The directory that Vivado runs in, relative to --exec-root; defaults to --exec-root")
 
 	fs.StringVar(&xpr.OutXpr, "out-xpr", "", "output XPR file")
 	fs.StringVar(&xpr.SynthFileName, "out-synth", "", "output synth file")
@@ -192,14 +343,44 @@ func run(args []string, stdout, stderr io.Writer) error {
 
 	var customTemplateFileName string
 	fs.StringVar(&customTemplateFileName, "custom-template", "", "Custom file template")
+	var templateDir string
+	fs.StringVar(&templateDir, "template-dir", "", "A directory with templates to use instead of the built-in "+
+		xprTemplate+", "+synthTemplate+" and "+pnrTemplate)
 
 	fs.StringVar(&xpr.LoadDcpFile, "load-dcp", "", "Input snapshot file")
 	fs.StringVar(&xpr.SaveDcpFile, "save-dcp", "", "Output snapshot file")
 	fs.StringVar(&xpr.BitstreamName, "bitstream", "", "Output bitstream file")
 	fs.StringVar(&xpr.TimingSummaryFile, "timing-report", "", "The file to write the timing report to")
 	fs.StringVar(&xpr.UtilizationFile, "utilization-report", "", "The file to write the utilization report to")
+	fs.StringVar(&xpr.HierUtilizationFile, "hier-utilization-report", "", "The file to write the utilization report by hierarchy to")
 	fs.StringVar(&xpr.DRCFile, "drc-report", "", "The file to write the desitn rule check report to")
 	fs.StringVar(&xpr.ProbesFile, "probes-file", "", "The file to write the debug probes to")
+	fs.StringVar(&xpr.CDCFile, "cdc-report", "", "The file to write the clock domain crossing report to")
+	fs.StringVar(&xpr.ClockInteractionFile, "clock-interaction-report", "", "The file to write the clock interaction report to")
+	fs.StringVar(&xpr.CheckTimingFile, "check-timing-report", "", "The file to write the check_timing report to")
+	fs.StringVar(&xpr.MethodologyFile, "methodology-report", "", "The file to write the methodology report to")
+	fs.StringVar(&xpr.TimingPathsFile, "timing-paths-report", "", "The file to write the worst setup and hold paths to")
+	fs.IntVar(&xpr.TimingMaxPaths, "timing-max-paths", 10, "The number of paths per path group to report")
+	fs.IntVar(&xpr.TimingNworst, "timing-nworst", 1, "The number of paths per endpoint to report")
+	fs.StringVar(&xpr.PowerReportFile, "power-report", "", "The file to write the power report to")
+	fs.StringVar(&xpr.PowerAnalysis.ToggleRate, "toggle-rate", "", "The default toggle rate in percent for the power report, e.g. 12.5")
+	fs.StringVar(&xpr.PowerAnalysis.StaticProbability, "static-probability", "", "The default static probability for the power report, e.g. 0.5")
+	fs.StringVar(&xpr.PowerAnalysis.SaifFile, "saif-file", "", "The simulation switching activity (.saif) file for the power report")
+	fs.StringVar(&xpr.PowerAnalysis.SaifStripPath, "saif-strip-path", "", "The instance path of the design in the SAIF file, e.g. tb/dut")
+	fs.StringVar(&xpr.MemInfoFile, "mem-info-file", "", "The file to write the block RAM memory map (.mmi) to")
+	fs.BoolVar(&xpr.AllowDummyOutputs, "allow-dummy-outputs", false,
+		"Write placeholder bitstream and probes files instead of failing when they can not be generated")
+
+	fs.BoolVar(&xpr.BitstreamConfig.Compress, "bitstream-compress", false, "Compress the bitstream")
+	fs.StringVar(&xpr.BitstreamConfig.ConfigVoltage, "config-voltage", "", "The configuration bank voltage, e.g. 3.3")
+	fs.StringVar(&xpr.BitstreamConfig.CFGBVS, "cfgbvs", "", "The configuration bank voltage select, VCCO or GND")
+	fs.IntVar(&xpr.BitstreamConfig.SPIBusWidth, "spi-buswidth", 0, "The SPI flash bus width: 1, 2, 4 or 8")
+	fs.StringVar(&xpr.BitstreamConfig.ConfigRate, "config-rate", "", "The master configuration clock rate in MHz")
+	fs.StringVar(&xpr.BitstreamConfig.UserID, "userid", "", "The 32-bit USERID value, in hex")
+	fs.StringVar(&xpr.BitstreamConfig.UsrAccess, "usr-access", "", "The 32-bit USR_ACCESS value in hex, or TIMESTAMP")
+
+	var stampFiles RepeatedString
+	fs.Var(&stampFiles, "stamp-file", "Workspace status files to encode the build stamp from into USERID and USR_ACCESS")
 
 	var defines RepeatedString
 	fs.Var(&defines, "define", "list of (System)Verilog defines")
@@ -207,9 +388,15 @@ func run(args []string, stdout, stderr io.Writer) error {
 	var generics RepeatedString
 	fs.Var(&generics, "generic", "a VHDL generic in KEY=VALUE format")
 
+	fs.StringVar(&xpr.OptDesignOptions, "opt-design-options", "", "Options to append to opt_design")
+	var postOptDesign RepeatedString
+	fs.Var(&postOptDesign, "post-opt-design", "Commands to run after opt_design")
 	fs.StringVar(&xpr.PlaceDesignOptions, "place-design-options", "", "Options to append to place_design")
 	var postPlaceDesign RepeatedString
 	fs.Var(&postPlaceDesign, "post-place-design", "Commands to run after place_design")
+	fs.StringVar(&xpr.PhysOptDesignOptions, "phys-opt-design-options", "", "Options to append to phys_opt_design")
+	var postPhysOptDesign RepeatedString
+	fs.Var(&postPhysOptDesign, "post-phys-opt-design", "Commands to run after phys_opt_design")
 	fs.StringVar(&xpr.RouteDesignOptions, "route-design-options", "", "Options to append to route_design")
 	var postRouteDesign RepeatedString
 	fs.Var(&postRouteDesign, "post-route-design", "Commands to run after route_design")
@@ -218,28 +405,160 @@ func run(args []string, stdout, stderr io.Writer) error {
 	var postSynthDesign RepeatedString
 	fs.Var(&postSynthDesign, "post-synth-design", "Commands to run after synth_design")
 
+	hooks := map[string]*Hooks{
+		"synth":     &xpr.SynthHooks,
+		"opt":       &xpr.OptHooks,
+		"place":     &xpr.PlaceHooks,
+		"phys-opt":  &xpr.PhysOptHooks,
+		"route":     &xpr.RouteHooks,
+		"bitstream": &xpr.BitstreamHooks,
+	}
+	preHooks := map[string]*RepeatedString{}
+	postHooks := map[string]*RepeatedString{}
+	for stage := range hooks {
+		preHooks[stage] = &RepeatedString{}
+		fs.Var(preHooks[stage], fmt.Sprintf("pre-%s-hook", stage),
+			fmt.Sprintf("A TCL file to source before the %s step, repeated in order", stage))
+		postHooks[stage] = &RepeatedString{}
+		fs.Var(postHooks[stage], fmt.Sprintf("post-%s-hook", stage),
+			fmt.Sprintf("A TCL file to source after the %s step, repeated in order", stage))
+	}
+
+	fs.BoolVar(&xpr.OutOfContext, "ooc", false, "Synthesize the top as an out-of-context partition")
+	fs.StringVar(&xpr.StubFile, "stub-file", "", "The synthesis stub (.v or .vhd) of the top to write")
+	var oocCells RepeatedString
+	fs.Var(&oocCells, "ooc-cell", "each is: cell=dcp, an out-of-context checkpoint to link into a black box cell")
+
+	fs.StringVar(&xpr.FuncsimFile, "funcsim-netlist", "", "The functional simulation netlist (.v or .vhd) to write")
+	fs.StringVar(&xpr.TimesimFile, "timesim-netlist", "", "The timing simulation netlist (.v) to write, requires --sdf-file")
+	fs.StringVar(&xpr.SdfFile, "sdf-file", "", "The standard delay format (SDF) file to write")
+
+	var dfxCells RepeatedString
+	fs.Var(&dfxCells, "dfx-cell", "each is: cell=dcp[=partial.bit], a reconfigurable partition and its module; an empty dcp leaves a black box")
+	fs.StringVar(&xpr.DfxStaticFile, "dfx-static-dcp", "", "The file to write the locked static DFX design to")
+	var prVerifyDcps RepeatedString
+	fs.Var(&prVerifyDcps, "pr-verify-dcp", "A routed DFX configuration checkpoint to check against --load-dcp")
+	fs.StringVar(&xpr.PrVerifyFile, "pr-verify-report", "", "The file to write the pr_verify report to")
+
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
-	// Load a custom template if specified.
-	var customTemplate *template.Template
+	if xpr.TimesimFile != "" {
+		if path.Ext(xpr.TimesimFile) != VerilogExtension {
+			return fmt.Errorf("timing simulation netlists are Verilog only, got: %v", xpr.TimesimFile)
+		}
+		if xpr.SdfFile == "" {
+			return fmt.Errorf("param --sdf-file is required with --timesim-netlist")
+		}
+	}
+
+	if xpr.TimingMaxPaths < 1 || xpr.TimingNworst < 1 {
+		return fmt.Errorf("params --timing-max-paths and --timing-nworst must be at least 1")
+	}
+
+	if err := xpr.PowerAnalysis.Validate(); err != nil {
+		return err
+	}
+	if !xpr.PowerAnalysis.Empty() && xpr.PowerReportFile == "" {
+		return fmt.Errorf("switching activity params require --power-report")
+	}
 
-	if customTemplateFileName != "" {
-		b, err := os.ReadFile(customTemplateFileName)
+	if len(dfxCells.values) > 0 {
+		if err := validateDfx(xpr.Part); err != nil {
+			return err
+		}
+	}
+
+	if !stampFiles.Empty() {
+		if xpr.BitstreamConfig.UserID != "" || xpr.BitstreamConfig.UsrAccess != "" {
+			return fmt.Errorf("params --userid and --usr-access can not be used with --stamp-file")
+		}
+		status, err := buildstamp.ReadStatusFiles(stampFiles.values...)
+		if err != nil {
+			return fmt.Errorf("read build stamp: %w", err)
+		}
+		stamp, err := buildstamp.FromStatus(status)
 		if err != nil {
-			return fmt.Errorf("read template %s: %w", customTemplateFileName, err)
+			return fmt.Errorf("build stamp: %w", err)
 		}
-		s := string(b)
-		customTemplate = template.Must(template.New("custom").Parse(s))
+		// Both are checked by FromStatus.
+		userID, _ := stamp.UserID()
+		usrAccess, _ := stamp.UsrAccess()
+		xpr.BitstreamConfig.UserID = fmt.Sprintf("0x%08X", userID)
+		xpr.BitstreamConfig.UsrAccess = fmt.Sprintf("0x%08X", usrAccess)
+		xpr.BuildStamp = stamp.String()
+	}
+
+	if err := xpr.BitstreamConfig.Validate(xpr.Part); err != nil {
+		return fmt.Errorf("bitstream options: %w", err)
 	}
 
 	// Build the data model.
-	pPaths := make([]string, 0, dirDepth)
-	for i := 0; i < dirDepth; i++ {
-		pPaths = append(pPaths, "..")
+	paths, err := newPathResolver(execRoot, workDir)
+	if err != nil {
+		return err
+	}
+
+	if xpr.LoadDcpFile != "" {
+		if xpr.LoadDcpFile, err = paths.file(xpr.LoadDcpFile); err != nil {
+			return fmt.Errorf("load-dcp: %w", err)
+		}
+	}
+	for _, v := range oocCells.values {
+		cell, dcp, ok := strings.Cut(v, "=")
+		if !ok || cell == "" || dcp == "" {
+			return fmt.Errorf("invalid format for ooc-cell, expected cell=dcp, got: %v", v)
+		}
+		if dcp, err = paths.file(dcp); err != nil {
+			return fmt.Errorf("ooc-cell %s: %w", v, err)
+		}
+		xpr.OOCCells = append(xpr.OOCCells, OOCCell{Cell: cell, DcpFile: dcp})
+	}
+	for _, v := range dfxCells.values {
+		c, err := parseDfxCell(v)
+		if err != nil {
+			return err
+		}
+		// A black box has no checkpoint.
+		if c.DcpFile != "" {
+			if c.DcpFile, err = paths.file(c.DcpFile); err != nil {
+				return fmt.Errorf("dfx-cell %s: %w", v, err)
+			}
+		}
+		xpr.DfxCells = append(xpr.DfxCells, c)
+	}
+	// The outputs do not exist yet, so they are only rewritten.
+	outputs := []*string{
+		&xpr.SaveDcpFile, &xpr.BitstreamName, &xpr.TimingSummaryFile,
+		&xpr.UtilizationFile, &xpr.HierUtilizationFile, &xpr.DRCFile,
+		&xpr.ProbesFile, &xpr.CDCFile, &xpr.ClockInteractionFile,
+		&xpr.CheckTimingFile, &xpr.MethodologyFile, &xpr.TimingPathsFile,
+		&xpr.PowerReportFile, &xpr.MemInfoFile, &xpr.StubFile,
+		&xpr.FuncsimFile, &xpr.TimesimFile, &xpr.SdfFile,
+		&xpr.DfxStaticFile, &xpr.PrVerifyFile,
+	}
+	for i := range xpr.DfxCells {
+		outputs = append(outputs, &xpr.DfxCells[i].PartialBitstream)
+	}
+	for _, out := range outputs {
+		if *out == "" {
+			continue
+		}
+		fn, err := paths.rel(*out)
+		if err != nil {
+			return fmt.Errorf("output %s: %w", *out, err)
+		}
+		*out = fn
+	}
+	if xpr.PrVerifyDcps, err = paths.files(prVerifyDcps.values); err != nil {
+		return fmt.Errorf("pr-verify-dcp: %w", err)
+	}
+	if xpr.PowerAnalysis.SaifFile != "" {
+		if xpr.PowerAnalysis.SaifFile, err = paths.file(xpr.PowerAnalysis.SaifFile); err != nil {
+			return fmt.Errorf("saif-file: %w", err)
+		}
 	}
-	ppath := strings.Join(pPaths, "/")
 
 	var verilogFiles, systemVerilogFiles, VHDLFiles, OtherFiles []FileLib
 
@@ -248,8 +567,12 @@ func run(args []string, stdout, stderr io.Writer) error {
 		if len(s) < 2 {
 			return fmt.Errorf("invalid format for library-file, expected library=file, got: %v", v)
 		}
+		name, err := paths.file(s[1])
+		if err != nil {
+			return fmt.Errorf("library-file %s: %w", v, err)
+		}
 		if err := AppendTo(&systemVerilogFiles, &verilogFiles, &VHDLFiles,
-			&OtherFiles, FileLib{Name: s[1], Library: s[0]}); err != nil {
+			&OtherFiles, FileLib{Name: name, Library: s[0]}); err != nil {
 			return fmt.Errorf("classify %s: %w", v, err)
 		}
 	}
@@ -257,18 +580,36 @@ func run(args []string, stdout, stderr io.Writer) error {
 	// Sort the different program files into their own file type lists. Order
 	// is significant.
 	for _, v := range sources.values {
+		name, err := paths.file(v)
+		if err != nil {
+			return fmt.Errorf("source %s: %w", v, err)
+		}
 		if err := AppendTo(&systemVerilogFiles, &verilogFiles, &VHDLFiles,
-			&OtherFiles, FileLib{Name: v}); err != nil {
+			&OtherFiles, FileLib{Name: name}); err != nil {
 			return fmt.Errorf("classify %s: %w", v, err)
 		}
 	}
 
 	var vDirs []string
 	for _, v := range includeDirs.values {
-		vDirs = append(vDirs, path.Join(ppath, v))
+		d, err := paths.dir(v)
+		if err != nil {
+			return fmt.Errorf("include-dir %s: %w", v, err)
+		}
+		vDirs = append(vDirs, d)
+	}
+	vHeaders, err := paths.files(headers.values)
+	if err != nil {
+		return fmt.Errorf("header: %w", err)
 	}
-	for _, f := range headers.values {
-		vDirs = append(vDirs, path.Join(ppath, path.Dir(path.Clean(f))))
+	var verilogHeaders []FileLib
+	for _, f := range vHeaders {
+		vDirs = append(vDirs, path.Dir(f))
+		verilogHeaders = append(verilogHeaders, FileLib{Name: f})
+	}
+	vXDCFiles, err := paths.files(xdcFiles.values)
+	if err != nil {
+		return fmt.Errorf("constraints: %w", err)
 	}
 
 	pwd, err := os.Getwd()
@@ -282,31 +623,63 @@ func run(args []string, stdout, stderr io.Writer) error {
 	xpr.VerilogFiles = verilogFiles
 	xpr.VHDLFiles = VHDLFiles
 	xpr.OtherFiles = OtherFiles
-	xpr.VerilogHeaders = headers.values
+	xpr.VerilogHeaders = verilogHeaders
 	xpr.VerilogIncludeDirs = vDirs
-	xpr.XDCFiles = xdcFiles.values
+	xpr.XDCFiles = vXDCFiles
 	xpr.PWD = pwd
+	if xpr.WorkDir, err = paths.workDirFromRoot(); err != nil {
+		return err
+	}
 	xpr.VHDLGenerics = generics.values
 	xpr.PostRouteDesign = postRouteDesign.values
 	xpr.PostPlaceDesign = postPlaceDesign.values
+	xpr.PostOptDesign = postOptDesign.values
+	xpr.PostPhysOptDesign = postPhysOptDesign.values
 	xpr.PostSynthDesign = postSynthDesign.values
-
-	if xpr.OutXpr != "" {
-		if err := WriteFile(xpr.OutXpr, xprTpl, &xpr); err != nil {
-			return fmt.Errorf("write XPR %s: %w", xpr.OutXpr, err)
+	for stage, h := range hooks {
+		if h.Pre, err = paths.files(preHooks[stage].values); err != nil {
+			return fmt.Errorf("pre-%s-hook: %w", stage, err)
 		}
-	}
-	if xpr.SynthFileName != "" {
-		if err := WriteFile(xpr.SynthFileName, syntTpl, &xpr); err != nil {
-			return fmt.Errorf("write synth %s: %w", xpr.SynthFileName, err)
+		if h.Post, err = paths.files(postHooks[stage].values); err != nil {
+			return fmt.Errorf("post-%s-hook: %w", stage, err)
 		}
 	}
-	if xpr.PnrFileName != "" {
-		if err := WriteFile(xpr.PnrFileName, pnrTpl, &xpr); err != nil {
-			return fmt.Errorf("write PNR %s: %w", xpr.PnrFileName, err)
+
+	for _, out := range []struct {
+		kind, fn, template string
+	}{
+		{"XPR", xpr.OutXpr, xprTemplate},
+		{"synth", xpr.SynthFileName, synthTemplate},
+		{"PNR", xpr.PnrFileName, pnrTemplate},
+	} {
+		if out.fn == "" {
+			continue
+		}
+		dir := templateDir
+		if dir != "" {
+			dir = paths.abs(dir)
+		}
+		tpl, err := loadTemplate(dir, out.template)
+		if err != nil {
+			return fmt.Errorf("load %s template: %w", out.kind, err)
+		}
+		if err := WriteFile(out.fn, tpl, &xpr); err != nil {
+			return fmt.Errorf("write %s %s: %w", out.kind, out.fn, err)
 		}
 	}
 	if xpr.CustomFileName != "" {
+		if customTemplateFileName == "" {
+			return fmt.Errorf("param --custom-template is required with --custom-filename")
+		}
+		// xprgen reads the template itself, so it is not rewritten for
+		// Vivado.
+		if _, err := paths.file(customTemplateFileName); err != nil {
+			return fmt.Errorf("custom-template: %w", err)
+		}
+		customTemplate, err := readTemplate(paths.abs(customTemplateFileName))
+		if err != nil {
+			return err
+		}
 		if err := WriteFile(xpr.CustomFileName, customTemplate, &xpr); err != nil {
 			return fmt.Errorf("write %s: %w", xpr.CustomFileName, err)
 		}
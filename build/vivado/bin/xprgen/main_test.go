@@ -307,3 +307,326 @@ func TestRunCLI(t *testing.T) {
 		})
 	}
 }
+
+func TestRunStageOptions(t *testing.T) {
+	tmpDir := t.TempDir()
+	tplFile := filepath.Join(tmpDir, "stage.tcl.template")
+	tpl := `opt_design {{ .OptDesignOptions }}
+{{- range .PostOptDesign}}
+{{ . }}
+{{- end}}
+phys_opt_design {{ .PhysOptDesignOptions }}
+{{- range .PostPhysOptDesign}}
+{{ . }}
+{{- end}}
+`
+	if err := os.WriteFile(tplFile, []byte(tpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outFile := filepath.Join(tmpDir, "stage.tcl")
+
+	args := []string{
+		"--custom-template", tplFile,
+		"--custom-filename", outFile,
+		"--opt-design-options", "-directive Explore",
+		"--post-opt-design", "puts opt1",
+		"--post-opt-design", "puts opt2",
+		"--phys-opt-design-options", "-directive AggressiveExplore",
+		"--post-phys-opt-design", "puts physopt",
+	}
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	b, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	want := `opt_design -directive Explore
+puts opt1
+puts opt2
+phys_opt_design -directive AggressiveExplore
+puts physopt
+`
+	if string(b) != want {
+		t.Errorf("file content = %q, want %q", string(b), want)
+	}
+}
+
+func TestRunBitstreamOptions(t *testing.T) {
+	tmpDir := t.TempDir()
+	outFile := filepath.Join(tmpDir, "bitstream.tcl")
+	args := []string{
+		"--custom-template", "../../pnr_batch.tcl.template",
+		"--custom-filename", outFile,
+		"--bitstream", "top.bit",
+		"--part", "xc7a200tfbg484-2",
+		"--bitstream-compress",
+		"--config-voltage", "3.3",
+		"--cfgbvs", "VCCO",
+		"--spi-buswidth", "4",
+		"--mem-info-file", "top.mmi",
+	}
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	b, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	want := `set_property BITSTREAM.GENERAL.COMPRESS TRUE [current_design]
+set_property CONFIG_VOLTAGE 3.3 [current_design]
+set_property CFGBVS VCCO [current_design]
+set_property BITSTREAM.CONFIG.SPI_BUSWIDTH 4 [current_design]
+if { [catch { write_bitstream -force top.bit } err] } {`
+	if !strings.Contains(string(b), want) {
+		t.Errorf("file content = %q, want it to contain %q", string(b), want)
+	}
+	if want := "write_mem_info -force top.mmi"; !strings.Contains(string(b), want) {
+		t.Errorf("file content = %q, want it to contain %q", string(b), want)
+	}
+
+	args = []string{
+		"--custom-template", "../../pnr_batch.tcl.template",
+		"--custom-filename", outFile,
+		"--part", "xczu3eg-sbva484-1-e",
+		"--cfgbvs", "VCCO",
+	}
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Errorf("run() with CFGBVS on UltraScale+ succeeded, want error")
+	}
+}
+
+func TestRunStamp(t *testing.T) {
+	tmpDir := t.TempDir()
+	stable := filepath.Join(tmpDir, "stable-status.txt")
+	volatile := filepath.Join(tmpDir, "volatile-status.txt")
+	if err := os.WriteFile(stable, []byte("STABLE_GIT_COMMIT 1a2b3c4d5e6f\nSTABLE_GIT_DIRTY 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(volatile, []byte("BUILD_TIMESTAMP 1792415655\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outFile := filepath.Join(tmpDir, "bitstream.tcl")
+	args := []string{
+		"--custom-template", "../../pnr_batch.tcl.template",
+		"--custom-filename", outFile,
+		"--bitstream", "top.bit",
+		"--part", "xc7a200tfbg484-2",
+		"--stamp-file", stable,
+		"--stamp-file", volatile,
+	}
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	b, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	for _, want := range []string{
+		"# Build stamp: 1a2b3c4d5e6f-dirty built 2026-10-19 ",
+		"set_property BITSTREAM.CONFIG.USERID 0x1A2B3C43 [current_design]",
+		"set_property BITSTREAM.CONFIG.USR_ACCESS 0x",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("file content = %q, want it to contain %q", string(b), want)
+		}
+	}
+
+	args = append(args, "--userid", "0x12345678")
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Errorf("run() with --userid and --stamp-file succeeded, want error")
+	}
+}
+
+func TestRunOutOfContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	outFile := filepath.Join(tmpDir, "synth.tcl")
+
+	args := []string{
+		"--custom-template", "../../synth_batch.tcl.template",
+		"--custom-filename", outFile,
+		"--top-name", "pcie_top",
+		"--part", "xc7a200tfbg484-2",
+		"--save-dcp", "pcie.dcp",
+		"--ooc",
+		"--stub-file", "pcie_stub.vhdl",
+	}
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	b, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	for _, want := range []string{
+		"-mode out_of_context",
+		"write_vhdl -force -mode synth_stub pcie_stub.vhdl",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("file content = %q, want it to contain %q", string(b), want)
+		}
+	}
+	if strings.Contains(string(b), "read_checkpoint") {
+		t.Errorf("file content = %q, want no read_checkpoint", string(b))
+	}
+
+	touchFiles(t, tmpDir, "pcie.dcp", "eth.dcp")
+	args = []string{
+		"--custom-template", absPath(t, "../../synth_batch.tcl.template"),
+		"--custom-filename", outFile,
+		"--exec-root", tmpDir,
+		"--top-name", "top",
+		"--part", "xc7a200tfbg484-2",
+		"--save-dcp", "top.dcp",
+		"--ooc-cell", "u_pcie=pcie.dcp",
+		"--ooc-cell", "u_eth/mac=eth.dcp",
+	}
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	b, err = os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	want := "read_checkpoint -cell u_pcie pcie.dcp\nread_checkpoint -cell u_eth/mac eth.dcp\n"
+	if !strings.Contains(string(b), want) {
+		t.Errorf("file content = %q, want it to contain %q", string(b), want)
+	}
+	if strings.Contains(string(b), "out_of_context") || strings.Contains(string(b), "synth_stub") {
+		t.Errorf("file content = %q, want no out-of-context synthesis", string(b))
+	}
+
+	for _, bad := range []string{"u_bad", "u_missing=missing.dcp"} {
+		if err := run(append(args, "--ooc-cell", bad), &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+			t.Errorf("run() with --ooc-cell %v succeeded, want error", bad)
+		}
+	}
+}
+
+func TestRunSimNetlists(t *testing.T) {
+	tmpDir := t.TempDir()
+	outFile := filepath.Join(tmpDir, "out.tcl")
+
+	tests := []struct {
+		name     string
+		template string
+		args     []string
+		want     []string
+		notWant  []string
+		wantErr  bool
+	}{
+		{
+			name:     "Post-route Verilog",
+			template: "../../pnr_batch.tcl.template",
+			args: []string{
+				"--funcsim-netlist", "top.funcsim.v",
+				"--timesim-netlist", "top.timesim.v",
+				"--sdf-file", "top.timesim.sdf",
+			},
+			want: []string{
+				"write_verilog -force -mode funcsim top.funcsim.v",
+				"write_sdf -force -mode timesim top.timesim.sdf\nwrite_verilog -force -mode timesim -sdf_anno true -sdf_file top.timesim.sdf top.timesim.v",
+			},
+		},
+		{
+			name:     "Post-synthesis VHDL",
+			template: "../../synth_batch.tcl.template",
+			args:     []string{"--funcsim-netlist", "top.funcsim.vhd"},
+			want:     []string{"write_vhdl -force -mode funcsim top.funcsim.vhd"},
+			notWant:  []string{"write_sdf", "-mode timesim"},
+		},
+		{
+			name:     "No netlists",
+			template: "../../pnr_batch.tcl.template",
+			notWant:  []string{"-mode funcsim", "write_sdf", "-mode timesim"},
+		},
+		{
+			name:     "VHDL timesim",
+			template: "../../pnr_batch.tcl.template",
+			args:     []string{"--timesim-netlist", "top.timesim.vhd", "--sdf-file", "top.sdf"},
+			wantErr:  true,
+		},
+		{
+			name:     "Timesim without SDF",
+			template: "../../pnr_batch.tcl.template",
+			args:     []string{"--timesim-netlist", "top.timesim.v"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{
+				"--custom-template", tt.template,
+				"--custom-filename", outFile,
+				"--top-name", "top",
+			}, tt.args...)
+			err := run(args, &bytes.Buffer{}, &bytes.Buffer{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			b, err := os.ReadFile(outFile)
+			if err != nil {
+				t.Fatalf("failed to read file: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(b), want) {
+					t.Errorf("file content = %q, want it to contain %q", string(b), want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(string(b), notWant) {
+					t.Errorf("file content = %q, want no %q", string(b), notWant)
+				}
+			}
+		})
+	}
+}
+
+// touchFiles creates the empty files names, and their directories, in dir.
+func touchFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, n := range names {
+		fn := filepath.Join(dir, n)
+		if err := os.MkdirAll(filepath.Dir(fn), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fn, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// absPath returns the absolute path of fn, so that it does not depend on
+// --exec-root.
+func absPath(t *testing.T, fn string) string {
+	t.Helper()
+	p, err := filepath.Abs(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// renderTemplate runs xprgen with the template and args for the top "top",
+// and returns the TCL it writes.
+func renderTemplate(t *testing.T, template string, args ...string) string {
+	t.Helper()
+	outFile := filepath.Join(t.TempDir(), "out.tcl")
+	args = append([]string{
+		"--custom-template", absPath(t, template),
+		"--custom-filename", outFile,
+		"--top-name", "top",
+	}, args...)
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run(%v) error = %v", args, err)
+	}
+	b, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	return string(b)
+}
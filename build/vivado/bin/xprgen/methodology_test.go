@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunMethodologyReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		args     []string
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "Synth",
+			template: "../../synth_batch.tcl.template",
+			args:     []string{"--check-timing-report", "top.check_timing.rpt", "--methodology-report", "top.methodology.rpt"},
+			want:     []string{"check_timing -verbose -file top.check_timing.rpt\nreport_methodology -file top.methodology.rpt"},
+		},
+		{
+			name:     "Synth without reports",
+			template: "../../synth_batch.tcl.template",
+			notWant:  []string{"check_timing", "report_methodology"},
+		},
+		{
+			name:     "PnR",
+			template: "../../pnr_batch.tcl.template",
+			args:     []string{"--check-timing-report", "top.check_timing.rpt", "--methodology-report", "top.methodology.rpt"},
+			want:     []string{"check_timing -verbose -file top.check_timing.rpt\nreport_methodology -file top.methodology.rpt"},
+		},
+		{
+			name:     "PnR without reports",
+			template: "../../pnr_batch.tcl.template",
+			notWant:  []string{"check_timing", "report_methodology"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderTemplate(t, tt.template, tt.args...)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("file content = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("file content = %q, want no %q", got, notWant)
+				}
+			}
+		})
+	}
+}
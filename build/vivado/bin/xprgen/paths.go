@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// pathResolver rewrites the input paths, which Bazel gives relative to the
+// exec root, so that they are relative to the directory that Vivado runs in.
+type pathResolver struct {
+	// execRoot is the absolute directory that the input paths are relative
+	// to.
+	execRoot string
+	// workDir is the absolute directory that Vivado runs in.
+	workDir string
+}
+
+// newPathResolver returns a resolver for Vivado running in workDir. A
+// relative execRoot is relative to the current directory, and a relative
+// workDir to execRoot.
+func newPathResolver(execRoot, workDir string) (pathResolver, error) {
+	root, err := filepath.Abs(execRoot)
+	if err != nil {
+		return pathResolver{}, fmt.Errorf("exec root %s: %w", execRoot, err)
+	}
+	r := pathResolver{execRoot: root}
+	r.workDir = r.abs(workDir)
+	return r, nil
+}
+
+// abs returns the absolute path of p.
+func (r pathResolver) abs(p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(r.execRoot, p)
+}
+
+// rel returns the path p as Vivado sees it. Absolute paths are kept.
+func (r pathResolver) rel(p string) (string, error) {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p), nil
+	}
+	return relPath(r.workDir, r.abs(p))
+}
+
+// workDirFromRoot returns the work dir relative to the exec root, or ""
+// if they are the same.
+func (r pathResolver) workDirFromRoot() (string, error) {
+	d, err := relPath(r.execRoot, r.workDir)
+	if err != nil || d == "." {
+		return "", err
+	}
+	return d, nil
+}
+
+// file returns the path of the input file p as Vivado sees it, or an error
+// if there is no such file.
+func (r pathResolver) file(p string) (string, error) {
+	if _, err := os.Stat(r.abs(p)); err != nil {
+		return "", fmt.Errorf("input file: %w", err)
+	}
+	return r.rel(p)
+}
+
+// dir returns the path of the input directory p as Vivado sees it, or an
+// error if there is no such directory.
+func (r pathResolver) dir(p string) (string, error) {
+	fi, err := os.Stat(r.abs(p))
+	if err != nil {
+		return "", fmt.Errorf("input directory: %w", err)
+	}
+	if !fi.IsDir() {
+		return "", fmt.Errorf("input directory: %s is not a directory", p)
+	}
+	return r.rel(p)
+}
+
+// files returns the paths of the input files ps as Vivado sees them.
+func (r pathResolver) files(ps []string) ([]string, error) {
+	var out []string
+	for _, p := range ps {
+		f, err := r.file(p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunPaths(t *testing.T) {
+	execRoot := t.TempDir()
+	outFile := filepath.Join(t.TempDir(), "out.tcl")
+	touchFiles(t, execRoot, "rtl/top.sv", "rtl/inc/defs.svh", "lib/fifo.vhd", "top.xdc", "synth.dcp", "sim.saif")
+	if err := os.WriteFile(filepath.Join(execRoot, "out.tcl.template"),
+		[]byte("open_checkpoint {{ .LoadDcpFile }}\nread_saif {{ .PowerAnalysis.SaifFile }}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{
+		"--custom-template", absPath(t, "../../synth_batch.tcl.template"),
+		"--custom-filename", outFile,
+		"--top-name", "top",
+		"--exec-root", execRoot,
+		"--source", "rtl/top.sv",
+		"--library-file", "fifo_lib=lib/fifo.vhd",
+		"--header", "rtl/inc/defs.svh",
+		"--include-dir", "rtl",
+		"--constraints", "top.xdc",
+		"--save-dcp", "bazel-out/bin/top.dcp",
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "Exec root",
+			want: []string{
+				"-sv {rtl/top.sv}",
+				"-library fifo_lib {lib/fifo.vhd}",
+				"-sv {rtl/inc/defs.svh}",
+				"set_property include_dirs [list  rtl rtl/inc]",
+				"read_xdc {top.xdc}",
+				"write_checkpoint -force bazel-out/bin/top.dcp",
+			},
+		},
+		{
+			name: "Work dir",
+			args: []string{"--work-dir", "bazel-out/bin/_work"},
+			want: []string{
+				"-sv {../../../rtl/top.sv}",
+				"-library fifo_lib {../../../lib/fifo.vhd}",
+				"-sv {../../../rtl/inc/defs.svh}",
+				"set_property include_dirs [list  ../../../rtl ../../../rtl/inc]",
+				"read_xdc {../../../top.xdc}",
+				"\ncd bazel-out/bin/_work\n",
+				"write_checkpoint -force ../top.dcp",
+			},
+		},
+		{
+			name:    "Missing include dir",
+			args:    []string{"--include-dir", "rtl/missing"},
+			wantErr: "include-dir rtl/missing: input directory:",
+		},
+		{
+			name:    "Include dir is a file",
+			args:    []string{"--include-dir", "top.xdc"},
+			wantErr: "include-dir top.xdc: input directory: top.xdc is not a directory",
+		},
+		{
+			name:    "Missing source",
+			args:    []string{"--source", "rtl/missing.sv"},
+			wantErr: "source rtl/missing.sv: input file:",
+		},
+		{
+			name:    "Missing header",
+			args:    []string{"--header", "rtl/missing.svh"},
+			wantErr: "header: input file:",
+		},
+		{
+			name: "Checkpoint and SAIF",
+			args: []string{
+				"--work-dir", "work",
+				"--custom-template", "out.tcl.template",
+				"--load-dcp", "synth.dcp",
+				"--power-report", "top.power.rpt",
+				"--saif-file", "sim.saif",
+			},
+			want: []string{"open_checkpoint ../synth.dcp\nread_saif ../sim.saif\n"},
+		},
+		{
+			name:    "Missing checkpoint",
+			args:    []string{"--load-dcp", "missing.dcp"},
+			wantErr: "load-dcp: input file:",
+		},
+		{
+			name:    "Missing SAIF",
+			args:    []string{"--power-report", "top.power.rpt", "--saif-file", "missing.saif"},
+			wantErr: "saif-file: input file:",
+		},
+		{
+			name:    "Missing custom template",
+			args:    []string{"--custom-template", "missing.tcl.template"},
+			wantErr: "custom-template: input file:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := run(append(append([]string{}, args...), tt.args...), &bytes.Buffer{}, &bytes.Buffer{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("run() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			b, err := os.ReadFile(outFile)
+			if err != nil {
+				t.Fatalf("failed to read file: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(b), want) {
+					t.Errorf("file content = %q, want it to contain %q", string(b), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// PowerAnalysis holds the switching activity that `report_power` estimates
+// the dynamic power from.
+type PowerAnalysis struct {
+	// ToggleRate is the default toggle rate, in percent of the clock
+	// frequency, of the nets without simulated activity, e.g. "12.5".
+	ToggleRate string
+	// StaticProbability is the default probability, 0 to 1, of a net
+	// without simulated activity being high, e.g. "0.5".
+	StaticProbability string
+	// SaifFile is the switching activity interchange format (SAIF) file of a
+	// simulation run to read, if set.
+	SaifFile string
+	// SaifStripPath is the instance path of the design in the simulation,
+	// e.g. "tb/dut", stripped from the SAIF net names.
+	SaifStripPath string
+}
+
+// Validate checks that the switching activity options are in range.
+func (p PowerAnalysis) Validate() error {
+	if p.ToggleRate != "" {
+		// Vivado takes toggle rates below 200%, a net can not toggle more
+		// than twice per clock.
+		if v, err := strconv.ParseFloat(p.ToggleRate, 64); err != nil || v < 0 || v >= 200 {
+			return fmt.Errorf("toggle rate must be a percentage from 0 to below 200, got: %v", p.ToggleRate)
+		}
+	}
+	if p.StaticProbability != "" {
+		if v, err := strconv.ParseFloat(p.StaticProbability, 64); err != nil || v < 0 || v > 1 {
+			return fmt.Errorf("static probability must be from 0 to 1, got: %v", p.StaticProbability)
+		}
+	}
+	if p.SaifStripPath != "" && p.SaifFile == "" {
+		return fmt.Errorf("param --saif-strip-path requires --saif-file")
+	}
+	return nil
+}
+
+// Empty returns true if no switching activity is set, and Vivado uses its
+// defaults.
+func (p PowerAnalysis) Empty() bool {
+	return p == PowerAnalysis{}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunPowerReport(t *testing.T) {
+	execRoot := t.TempDir()
+	touchFiles(t, execRoot, "sim.saif")
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "Vectorless",
+			args:    []string{"--power-report", "top.power.rpt"},
+			want:    []string{"report_power -file top.power.rpt"},
+			notWant: []string{"read_saif", "set_switching_activity"},
+		},
+		{
+			name: "Toggle rate and SAIF",
+			args: []string{
+				"--exec-root", execRoot,
+				"--power-report", "top.power.rpt",
+				"--toggle-rate", "25",
+				"--static-probability", "0.4",
+				"--saif-file", "sim.saif",
+				"--saif-strip-path", "tb/dut",
+			},
+			want: []string{
+				"read_saif -strip_path tb/dut sim.saif\n" +
+					"set_switching_activity -default_toggle_rate 25\n" +
+					"set_switching_activity -default_static_probability 0.4\n" +
+					"report_power -file top.power.rpt",
+			},
+		},
+		{
+			name:    "No power report",
+			notWant: []string{"report_power"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderTemplate(t, "../../pnr_batch.tcl.template", tt.args...)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("file content = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("file content = %q, want no %q", got, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestRunPowerReportErrors(t *testing.T) {
+	outFile := filepath.Join(t.TempDir(), "out.tcl")
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "Activity without report",
+			args: []string{"--toggle-rate", "25"},
+		},
+		{
+			name: "Toggle rate out of range",
+			args: []string{"--power-report", "top.power.rpt", "--toggle-rate", "200"},
+		},
+		{
+			name: "Static probability out of range",
+			args: []string{"--power-report", "top.power.rpt", "--static-probability", "1.5"},
+		},
+		{
+			name: "Strip path without SAIF",
+			args: []string{"--power-report", "top.power.rpt", "--saif-strip-path", "tb/dut"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{
+				"--custom-template", "../../pnr_batch.tcl.template",
+				"--custom-filename", outFile,
+				"--top-name", "top",
+			}, tt.args...)
+			if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+				t.Errorf("run(%v) succeeded, want error", args)
+			}
+		})
+	}
+}
@@ -1,129 +1,132 @@
 package main
 
-import "text/template"
-
-var (
-	// The synthesis TCL script.
-	syntTpl = template.Must(template.New("synth").Parse(
-		`# GENERATED FILE, DO NOT EDIT
-# Project synthesis script
-# Project name: "{{.Project}}"
-# PWD:          "{{ .PWD }}"
-# XPR path:     "{{ .OutXpr }}"
-
-launch_runs synth_1
-wait_on_run synth_1
-exit [regexp -nocase -- {synth_design (error|failed)} [get_property STATUS [get_runs synth_1]] match]
-
-# end
-`))
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
 
+// The default templates of the project flow. --template-dir overrides any
+// of them with a file of the same name.
+const (
 	// The TCL script generating project file.
-	xprTpl = template.Must(template.New("xpr").Parse(
-		`# GENERATED FILE, DO NOT EDIT
-# Project TCL file
-# Project name: "{{ .Project }}"
-# PWD:          "{{ .PWD }}"
-# XPR path:     "{{ .OutXpr }}"
-
-create_project {{.Project}} -force
-
-# Verilog Properties
-{{$fileset := .Fileset -}}
-{{- range .VerilogProperties }}
-set_property verilog_define {{"{"}} {{- . -}} {{"}"}} [get_filesets {{ $fileset }} ]
-{{end}}
-
-# SystemVerilog files
-# Ordering is important.
-{{- range .SystemVerilogFiles}}
-read_verilog {{with .Library }} -library {{ . }} {{- end}}  -sv {{"{"}} {{- .Name -}} {{"}"}}
-{{- end}}
-# end: verilog files
-
-# Verilog files
-# Ordering is important.
-{{- range .VerilogFiles}}
-read_verilog {{with .Library }} -library {{ . }} {{- end}} {{"{"}} {{- .Name -}} {{"}"}}
-{{- end}}
-# end: verilog files
-
-# Verilog headers
-# Ordering is important.
-{{- range .VerilogHeaders}}
-read_verilog {{with .Library }} -library {{ . }} {{- end}} -sv {{"{"}} {{- .Name -}} {{"}"}}
-{{- end}}
-
-# VHDL files
-# Ordering is important.
-{{- range .VHDLFiles}}
-read_vhdl -vhdl2008 {{with .Library }} -library {{ . }} {{- end}} {{"{"}} {{- .Name -}} {{"}"}}
-{{- end}}
-# end: VHDL files
-
-# Verilog includes
-set_property include_dirs [list {{range .VerilogIncludeDirs}} {{ . }} {{- end -}}] [get_filesets {{ $fileset }}]
-
-# Constraints files
-# Ordering is important here, too.
-{{- range .XDCFiles}}
-read_xdc {{"{"}} {{- . -}} {{"}"}}
-{{- end}}
-# end: constraints files
-
-# Other files.
-{{- range .OtherFiles}}
-{{- if .IsIPGen}}
-file mkdir ip_cores
-exec cp -RL {{ .Name }} ip_cores/
-catch { exec chmod -R +w ip_cores/ }
-read_ip ip_cores/{{ .Library }}.ip_gen/{{ .Library }}.xci
-synth_ip [get_ips {{ .Library }}]
-{{- else}}
-add_files -norecurse {{ .Name }}
-{{- end}}
-{{- end}}
-# end: constraints files
-
-{{- if .Part}}
-set_property part {{ .Part }} [current_project]
-{{- end}}
-
-set_property top {{ .Top }} [current_fileset]
-set_property source_mgmt_mode None [current_project]
-
-# end
-`))
-
+	xprTemplate = "xpr.tcl.tmpl"
+	// The synthesis TCL script.
+	synthTemplate = "synth.tcl.tmpl"
 	// The TCL script for bitstream generation.
-	pnrTpl = template.Must(template.New("pnr").Parse(
-		`# GENERATED FILE, DO NOT EDIT
-# Project TCL file
-# Project name: "{{ .Project }}"
-# PWD:          "{{ .PWD }}"
-# XPR path:     "{{ .OutXpr }}"
+	pnrTemplate = "pnr.tcl.tmpl"
+)
 
-set_property STEPS.WRITE_BITSTREAM.ARGS.BIN_FILE true [get_runs impl_1]
+//go:embed templates/*.tcl.tmpl
+var defaultTemplates embed.FS
+
+// templateFuncs are the functions that every template can call, besides the
+// text/template builtins. All of them take their subject last, so that they
+// work at the end of a pipeline, e.g. `{{ .XDCFiles | join " " }}`.
+//
+//	tclquote S          S as a single TCL word, e.g. {a b} for "a b".
+//	join SEP LIST       The elements of LIST, separated by SEP.
+//	relpath BASE TARGET The path of TARGET relative to the directory BASE.
+//	hasPrefix PREFIX S  Whether S starts with PREFIX.
+var templateFuncs = template.FuncMap{
+	"tclquote":  tclQuote,
+	"join":      func(sep string, elems []string) string { return strings.Join(elems, sep) },
+	"relpath":   relPath,
+	"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+}
 
-if { [get_property PROGRESS [get_runs impl_1]] != "100%"} {
-  launch_runs synth_1 -quiet
+// tclQuote quotes s as a single TCL word. Words without special characters
+// are left as is. Others are put in braces, which keep the text verbatim,
+// unless the braces in s are unbalanced or s has backslashes, which braces
+// can not hold; these are backslash-escaped instead.
+func tclQuote(s string) string {
+	const special = " \t\r\n;\"$[]{}\\#"
+	if s != "" && !strings.ContainsAny(s, special) {
+		return s
+	}
+	if bracesBalanced(s) && !strings.Contains(s, `\`) {
+		return "{" + s + "}"
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case strings.ContainsRune(special, r):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
 
-  launch_runs impl_1 -to_step write_bitstream
-  wait_on_run impl_1
-  puts "Bitstream generation completed"
-} else {
-  puts "Bitstream generation already complete"
+// bracesBalanced reports whether every closing brace in s closes an earlier
+// opening brace, and all of them are closed.
+func bracesBalanced(s string) bool {
+	depth := 0
+	for _, r := range s {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
 }
 
-if { [get_property PROGRESS [get_runs impl_1]] != "100%"} {
-   puts "ERROR: Implementation and bitstream generation step failed."
-   exit 1
+// relPath returns the path of target relative to the directory base.
+func relPath(base, target string) (string, error) {
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return "", fmt.Errorf("relpath: %w", err)
+	}
+	return filepath.ToSlash(rel), nil
 }
 
-set vivadoDefaultBitstreamFile [ get_property DIRECTORY [current_run] ]/[ get_property top [current_fileset] ].bit
-file copy -force $vivadoDefaultBitstreamFile [pwd]/[current_project].bit
+// parseTemplate parses text as the template name, with templateFuncs. The
+// errors have the template name and line, e.g.
+// `template: pnr.tcl.tmpl:12: function "x" not defined`.
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
 
+// readTemplate parses the template file fn.
+func readTemplate(fn string) (*template.Template, error) {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, fmt.Errorf("read template %s: %w", fn, err)
+	}
+	return parseTemplate(filepath.Base(fn), string(b))
+}
 
-# end
-`))
-)
+// loadTemplate parses the default template name, from dir if it is set and
+// has a file of that name, or else from the embedded templates.
+func loadTemplate(dir, name string) (*template.Template, error) {
+	if dir != "" {
+		t, err := readTemplate(filepath.Join(dir, name))
+		if !errors.Is(err, fs.ErrNotExist) {
+			return t, err
+		}
+	}
+	b, err := defaultTemplates.ReadFile(path.Join("templates", name))
+	if err != nil {
+		return nil, fmt.Errorf("read template %s: %w", name, err)
+	}
+	return parseTemplate(name, string(b))
+}
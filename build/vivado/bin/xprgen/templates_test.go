@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTclQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "top.v", want: "top.v"},
+		{in: "", want: "{}"},
+		{in: "my file.v", want: "{my file.v}"},
+		{in: "a[0]", want: "{a[0]}"},
+		{in: "$HOME/x", want: "{$HOME/x}"},
+		{in: "a}b", want: `a\}b`},
+		{in: `C:\x y`, want: `C:\\x\ y`},
+		{in: "a\nb}", want: `a\nb\}`},
+	}
+	for _, tt := range tests {
+		if got := tclQuote(tt.in); got != tt.want {
+			t.Errorf("tclQuote(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	tpl, err := parseTemplate("funcs",
+		`{{ tclquote "a b" }}|{{ .XDCFiles | join "," }}|{{ relpath "x/y" "x/z/f.v" }}|`+
+			`{{ range .XDCFiles }}{{ if hasPrefix "pins" . }}{{ . }}{{ end }}{{ end }}`)
+	if err != nil {
+		t.Fatalf("parseTemplate() error = %v", err)
+	}
+	var b bytes.Buffer
+	if err := tpl.Execute(&b, &XPRBinding{XDCFiles: []string{"timing.xdc", "pins.xdc"}}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if want := "{a b}|timing.xdc,pins.xdc|../z/f.v|pins.xdc"; b.String() != want {
+		t.Errorf("Execute() = %q, want %q", b.String(), want)
+	}
+}
+
+func TestRunTemplateDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	outXpr := filepath.Join(tmpDir, "out.xpr.tcl")
+	outSynth := filepath.Join(tmpDir, "out.synth.tcl")
+	templateDir := filepath.Join(tmpDir, "templates")
+	if err := os.Mkdir(templateDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "xpr.tcl.tmpl"),
+		[]byte("create_project {{ tclquote .Project }}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{
+		"--template-dir", templateDir,
+		"--out-xpr", outXpr,
+		"--out-synth", outSynth,
+		"--project-name", "my project",
+	}
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	b, err := os.ReadFile(outXpr)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if want := "create_project {my project}\n"; string(b) != want {
+		t.Errorf("XPR content = %q, want %q", string(b), want)
+	}
+	// The synthesis template is not in the directory, so it is the built-in
+	// one.
+	b, err = os.ReadFile(outSynth)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if want := "launch_runs synth_1"; !strings.Contains(string(b), want) {
+		t.Errorf("synth content = %q, want it to contain %q", string(b), want)
+	}
+}
+
+func TestRunTemplateErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	outFile := filepath.Join(tmpDir, "out.tcl")
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "Parse",
+			template: "# line 1\n{{ .Top }\n",
+			want:     "broken.tcl.template:2:",
+		},
+		{
+			name:     "Unknown function",
+			template: "# line 1\n# line 2\n{{ nosuchfunc .Top }}\n",
+			want:     `broken.tcl.template:3: function "nosuchfunc" not defined`,
+		},
+		{
+			name:     "Execute",
+			template: "# line 1\n{{ .NoSuchField }}\n",
+			want:     "broken.tcl.template:2:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tplFile := filepath.Join(tmpDir, "broken.tcl.template")
+			if err := os.WriteFile(tplFile, []byte(tt.template), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			args := []string{
+				"--custom-template", tplFile,
+				"--custom-filename", outFile,
+				"--top-name", "top",
+			}
+			err := run(args, &bytes.Buffer{}, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("run() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunTimingPathsReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "Defaults",
+			args: []string{"--timing-paths-report", "top.timing_paths.rpt"},
+			want: []string{
+				"report_timing -delay_type max -max_paths 10 -nworst 1 -sort_by slack -file top.timing_paths.rpt\n" +
+					"report_timing -delay_type min -max_paths 10 -nworst 1 -sort_by slack -append -file top.timing_paths.rpt",
+			},
+		},
+		{
+			name: "Top N",
+			args: []string{"--timing-paths-report", "top.timing_paths.rpt", "--timing-max-paths", "50", "--timing-nworst", "3"},
+			want: []string{"-delay_type max -max_paths 50 -nworst 3", "-delay_type min -max_paths 50 -nworst 3"},
+		},
+		{
+			name:    "No report",
+			notWant: []string{"report_timing "},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderTemplate(t, "../../pnr_batch.tcl.template", tt.args...)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("file content = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("file content = %q, want no %q", got, notWant)
+				}
+			}
+		})
+	}
+
+	args := []string{
+		"--custom-template", "../../pnr_batch.tcl.template",
+		"--custom-filename", filepath.Join(t.TempDir(), "out.tcl"),
+		"--top-name", "top",
+		"--timing-paths-report", "top.timing_paths.rpt",
+		"--timing-max-paths", "0",
+	}
+	if err := run(args, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Errorf("run() with --timing-max-paths 0 succeeded, want error")
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunHierUtilizationReport(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{name: "Report", args: []string{"--hier-utilization-report", "top.utilization_hier.pnr.rpt"}, want: true},
+		{name: "No report"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderTemplate(t, "../../pnr_batch.tcl.template", tt.args...)
+			has := strings.Contains(got, "report_utilization -hierarchical -file top.utilization_hier.pnr.rpt\n")
+			if has != tt.want {
+				t.Errorf("file content = %q, want hierarchical report %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package bitstream
+
+import (
+	"strings"
+)
+
+// Frame is one configuration frame written through FDRI.
+type Frame struct {
+	// FAR is the frame address that the write containing this frame started
+	// at. The device increments the address for each frame, following its
+	// own geometry, so Index is kept rather than the absolute address.
+	FAR uint32
+	// Index is the position of the frame within the write that started at
+	// FAR.
+	Index int
+	// Words is the frame content.
+	Words []uint32
+}
+
+// RegisterWrite is a write of a single value to a register other than FDRI.
+type RegisterWrite struct {
+	Reg   Register
+	Value uint32
+}
+
+// Family is an FPGA device family, as far as the configuration logic is
+// concerned.
+type Family int
+
+const (
+	FamilyUnknown Family = iota
+	// Family7Series covers Artix-7, Kintex-7, Virtex-7, Spartan-7 and Zynq-7000.
+	Family7Series
+	// FamilyUltraScale covers Kintex and Virtex UltraScale.
+	FamilyUltraScale
+	// FamilyUltraScalePlus covers Artix, Kintex, Virtex and Zynq UltraScale+.
+	FamilyUltraScalePlus
+)
+
+func (f Family) String() string {
+	switch f {
+	case Family7Series:
+		return "7-series"
+	case FamilyUltraScale:
+		return "UltraScale"
+	case FamilyUltraScalePlus:
+		return "UltraScale+"
+	}
+	return "unknown"
+}
+
+// PartFamily returns the device family of the part, e.g. "xc7a200tfbg484-2"
+// or "xczu3eg-sbva484-1-e".
+func PartFamily(part string) Family {
+	p := DevicePrefix(part)
+	switch {
+	case strings.HasPrefix(p, "7"):
+		return Family7Series
+	case strings.HasPrefix(p, "zu"):
+		return FamilyUltraScalePlus
+	case strings.HasPrefix(p, "au"), strings.HasPrefix(p, "ku"), strings.HasPrefix(p, "vu"):
+		// UltraScale+ parts have a "p" after the size, e.g. "ku5p" or
+		// "au15p".
+		rest := strings.TrimLeft(p[2:], "0123456789")
+		if strings.HasPrefix(rest, "p") {
+			return FamilyUltraScalePlus
+		}
+		return FamilyUltraScale
+	}
+	return FamilyUnknown
+}
+
+// FrameWords returns the number of 32-bit words in a configuration frame for
+// the part, e.g. "7a200tfbg484" or "xczu3eg", or 0 if the family is unknown.
+func FrameWords(part string) int {
+	switch PartFamily(part) {
+	case Family7Series:
+		return 101
+	case FamilyUltraScale:
+		return 123
+	case FamilyUltraScalePlus:
+		return 93
+	}
+	return 0
+}
+
+// Frames splits the FDRI writes in ps into frames of frameWords words each,
+// and returns them together with the remaining register writes.
+func Frames(ps []Packet, frameWords int) ([]Frame, []RegisterWrite) {
+	var frames []Frame
+	var writes []RegisterWrite
+	var far uint32
+	index := 0
+	for _, p := range ps {
+		if p.Op != OpWrite {
+			continue
+		}
+		switch p.Reg {
+		case RegFDRI:
+			for i := 0; i < len(p.Words); i += frameWords {
+				end := i + frameWords
+				if end > len(p.Words) {
+					end = len(p.Words)
+				}
+				frames = append(frames, Frame{FAR: far, Index: index, Words: p.Words[i:end]})
+				index++
+			}
+		case RegFAR:
+			for _, w := range p.Words {
+				far = w
+			}
+			index = 0
+			writes = append(writes, RegisterWrite{Reg: p.Reg, Value: far})
+		default:
+			for _, w := range p.Words {
+				writes = append(writes, RegisterWrite{Reg: p.Reg, Value: w})
+			}
+		}
+	}
+	return frames, writes
+}
@@ -0,0 +1,158 @@
+// Package bitstream reads Xilinx FPGA bitstream (.bit) files.
+//
+// A .bit file starts with a header that carries the design metadata,
+// followed by the raw configuration data that is shifted into the device.
+// The header is a fixed preamble followed by a sequence of tagged fields:
+//
+//	'a': design name, with optional ";PARTIAL=TRUE;UserID=...;Version=..."
+//	     suffixes
+//	'b': part name, e.g. "7a200tfbg484"
+//	'c': build date, e.g. "2025/11/20"
+//	'd': build time, e.g. "10:42:17"
+//	'e': length of the configuration data that follows
+package bitstream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// preamble is the fixed start of every .bit file header.
+var preamble = []byte{
+	0x00, 0x09, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x00, 0x00, 0x01,
+}
+
+// ErrNoHeader is returned when the input does not start with a .bit header.
+// This is the case, for example, for raw .bin files.
+var ErrNoHeader = errors.New("no bitstream header")
+
+// Header is the metadata embedded in a .bit file.
+type Header struct {
+	// DesignName is the name of the design, e.g. "top".
+	DesignName string
+	// UserID is the value of the USERID bitstream property, if present.
+	UserID string
+	// Partial is set for the partial bitstream of a reconfigurable
+	// partition, which can only be loaded over a configured device.
+	Partial bool
+	// ToolVersion is the version of the tool that wrote the bitstream,
+	// e.g. "2025.2".
+	ToolVersion string
+	// Part is the part name without the family prefix and speed grade,
+	// e.g. "7a200tfbg484".
+	Part string
+	// Date and Time are the build date and time, as written by the tool.
+	Date, Time string
+	// DataLength is the length in bytes of the configuration data that
+	// follows the header.
+	DataLength uint32
+}
+
+// DevicePrefix returns the part name as written into a .bit header, with the
+// "xc"-style family prefix removed and in lower case.
+func DevicePrefix(part string) string {
+	part = strings.ToLower(part)
+	if len(part) > 2 && part[0] == 'x' && part[1] >= 'a' && part[1] <= 'z' {
+		part = part[2:]
+	}
+	return part
+}
+
+// ParseHeader reads the header from r. On success, r is positioned at the
+// start of the configuration data.
+func ParseHeader(r io.Reader) (*Header, error) {
+	p := make([]byte, len(preamble))
+	if _, err := io.ReadFull(r, p); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrNoHeader
+		}
+		return nil, fmt.Errorf("read preamble: %w", err)
+	}
+	if !bytes.Equal(p, preamble) {
+		return nil, ErrNoHeader
+	}
+
+	var h Header
+	for {
+		var key [1]byte
+		if _, err := io.ReadFull(r, key[:]); err != nil {
+			return nil, fmt.Errorf("read field key: %w", err)
+		}
+		if key[0] == 'e' {
+			if err := binary.Read(r, binary.BigEndian, &h.DataLength); err != nil {
+				return nil, fmt.Errorf("read data length: %w", err)
+			}
+			return &h, nil
+		}
+		var n uint16
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return nil, fmt.Errorf("read length of field %q: %w", key[0], err)
+		}
+		v := make([]byte, n)
+		if _, err := io.ReadFull(r, v); err != nil {
+			return nil, fmt.Errorf("read field %q: %w", key[0], err)
+		}
+		s := strings.TrimRight(string(v), "\x00")
+		switch key[0] {
+		case 'a':
+			h.setDesign(s)
+		case 'b':
+			h.Part = s
+		case 'c':
+			h.Date = s
+		case 'd':
+			h.Time = s
+		default:
+			return nil, fmt.Errorf("unknown header field: %q", key[0])
+		}
+	}
+}
+
+// setDesign parses the design field, e.g. "top;UserID=0XFFFFFFFF;Version=2025.2",
+// or "top;PARTIAL=TRUE;UserID=0XFFFFFFFF;Version=2025.2" for partial
+// bitstreams.
+func (h *Header) setDesign(s string) {
+	parts := strings.Split(s, ";")
+	h.DesignName = parts[0]
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		switch k {
+		case "PARTIAL":
+			h.Partial = strings.EqualFold(v, "TRUE")
+		case "UserID":
+			h.UserID = v
+		case "Version":
+			h.ToolVersion = v
+		}
+	}
+}
+
+// ReadFile reads the .bit file fn and returns its header and configuration
+// data.
+func ReadFile(fn string) (*Header, []byte, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open: %w", err)
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	h, err := ParseHeader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse header of %v: %w", fn, err)
+	}
+	d, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read data of %v: %w", fn, err)
+	}
+	if uint32(len(d)) < h.DataLength {
+		return nil, nil, fmt.Errorf("truncated data in %v: want %d bytes, got %d",
+			fn, h.DataLength, len(d))
+	}
+	return h, d[:h.DataLength], nil
+}
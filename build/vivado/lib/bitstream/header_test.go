@@ -0,0 +1,152 @@
+package bitstream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// makeBit assembles a .bit file from its header fields and data.
+func makeBit(design, part, date, tm string, data []byte) []byte {
+	var b bytes.Buffer
+	b.Write(preamble)
+	for _, f := range []struct {
+		key byte
+		val string
+	}{{'a', design}, {'b', part}, {'c', date}, {'d', tm}} {
+		b.WriteByte(f.key)
+		binary.Write(&b, binary.BigEndian, uint16(len(f.val)+1))
+		b.WriteString(f.val)
+		b.WriteByte(0)
+	}
+	b.WriteByte('e')
+	binary.Write(&b, binary.BigEndian, uint32(len(data)))
+	b.Write(data)
+	return b.Bytes()
+}
+
+func TestParseHeader(t *testing.T) {
+	data := []byte{0xff, 0xff, 0xff, 0xff, 0xaa, 0x99, 0x55, 0x66}
+	bit := makeBit("top;UserID=0XFFFFFFFF;Version=2025.2", "7a200tfbg484", "2025/11/20", "10:42:17", data)
+
+	r := bytes.NewReader(bit)
+	h, err := ParseHeader(r)
+	if err != nil {
+		t.Fatalf("ParseHeader() error = %v", err)
+	}
+	want := &Header{
+		DesignName:  "top",
+		UserID:      "0XFFFFFFFF",
+		ToolVersion: "2025.2",
+		Part:        "7a200tfbg484",
+		Date:        "2025/11/20",
+		Time:        "10:42:17",
+		DataLength:  uint32(len(data)),
+	}
+	if !reflect.DeepEqual(h, want) {
+		t.Errorf("ParseHeader() = %+v, want %+v", h, want)
+	}
+	if r.Len() != len(data) {
+		t.Errorf("ParseHeader() left %d bytes, want %d", r.Len(), len(data))
+	}
+}
+
+func TestParseHeaderPartial(t *testing.T) {
+	data := []byte{0xaa, 0x99, 0x55, 0x66}
+	bit := makeBit("top;PARTIAL=TRUE;UserID=0XFFFFFFFF;Version=2025.2", "7a200tfbg484", "2025/11/20", "10:42:17", data)
+	h, err := ParseHeader(bytes.NewReader(bit))
+	if err != nil {
+		t.Fatalf("ParseHeader() error = %v", err)
+	}
+	if !h.Partial || h.DesignName != "top" || h.UserID != "0XFFFFFFFF" {
+		t.Errorf("ParseHeader() = %+v, want a partial bitstream of top", h)
+	}
+}
+
+func TestParseHeaderErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     []byte
+		wantNoHdr bool
+	}{
+		{
+			name:      "empty",
+			input:     nil,
+			wantNoHdr: true,
+		},
+		{
+			name:      "raw bin file",
+			input:     []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xaa, 0x99, 0x55, 0x66},
+			wantNoHdr: true,
+		},
+		{
+			name:  "truncated field",
+			input: append(append([]byte{}, preamble...), 'a', 0x00, 0x10, 't'),
+		},
+		{
+			name:  "unknown field",
+			input: append(append([]byte{}, preamble...), 'z', 0x00, 0x01, 0x00),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseHeader(bytes.NewReader(tt.input))
+			if err == nil {
+				t.Fatalf("ParseHeader() want error")
+			}
+			if got := errors.Is(err, ErrNoHeader); got != tt.wantNoHdr {
+				t.Errorf("ParseHeader() error = %v, want ErrNoHeader: %v", err, tt.wantNoHdr)
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	data := []byte{0xaa, 0x99, 0x55, 0x66, 0x20, 0x00, 0x00, 0x00}
+
+	fn := filepath.Join(tmpDir, "top.bit")
+	if err := os.WriteFile(fn, makeBit("top", "7a35tcsg324", "2025/01/01", "00:00:00", data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h, d, err := ReadFile(fn)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if h.Part != "7a35tcsg324" {
+		t.Errorf("ReadFile() part = %q", h.Part)
+	}
+	if !bytes.Equal(d, data) {
+		t.Errorf("ReadFile() data = %x, want %x", d, data)
+	}
+
+	truncated := filepath.Join(tmpDir, "truncated.bit")
+	b := makeBit("top", "7a35tcsg324", "2025/01/01", "00:00:00", data)
+	if err := os.WriteFile(truncated, b[:len(b)-2], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ReadFile(truncated); err == nil {
+		t.Errorf("ReadFile() on a truncated file: want error")
+	}
+}
+
+func TestDevicePrefix(t *testing.T) {
+	tests := []struct {
+		part, want string
+	}{
+		{"xc7a200tfbg484-2", "7a200tfbg484-2"},
+		{"XC7A200T", "7a200t"},
+		{"xczu3eg-sbva484-1-e", "zu3eg-sbva484-1-e"},
+		{"zu3egsbva484", "zu3egsbva484"},
+		{"x", "x"},
+	}
+	for _, tt := range tests {
+		if got := DevicePrefix(tt.part); got != tt.want {
+			t.Errorf("DevicePrefix(%q) = %q, want %q", tt.part, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package bitstream
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// SyncWord marks the start of the configuration packet stream.
+const SyncWord = 0xaa995566
+
+// Opcode is the operation of a configuration packet.
+type Opcode int
+
+const (
+	OpNOP Opcode = iota
+	OpRead
+	OpWrite
+	OpReserved
+)
+
+func (o Opcode) String() string {
+	switch o {
+	case OpNOP:
+		return "NOP"
+	case OpRead:
+		return "READ"
+	case OpWrite:
+		return "WRITE"
+	default:
+		return "RESERVED"
+	}
+}
+
+// Register is a 7-series/UltraScale configuration register address.
+type Register int
+
+const (
+	RegCRC     Register = 0x00
+	RegFAR     Register = 0x01
+	RegFDRI    Register = 0x02
+	RegFDRO    Register = 0x03
+	RegCMD     Register = 0x04
+	RegCTL0    Register = 0x05
+	RegMASK    Register = 0x06
+	RegSTAT    Register = 0x07
+	RegLOUT    Register = 0x08
+	RegCOR0    Register = 0x09
+	RegMFWR    Register = 0x0a
+	RegCBC     Register = 0x0b
+	RegIDCODE  Register = 0x0c
+	RegAXSS    Register = 0x0d
+	RegCOR1    Register = 0x0e
+	RegWBSTAR  Register = 0x10
+	RegTIMER   Register = 0x11
+	RegRBCRCSW Register = 0x13
+	RegBOOTSTS Register = 0x16
+	RegCTL1    Register = 0x18
+	RegBSPI    Register = 0x1f
+)
+
+var registerNames = map[Register]string{
+	RegCRC:     "CRC",
+	RegFAR:     "FAR",
+	RegFDRI:    "FDRI",
+	RegFDRO:    "FDRO",
+	RegCMD:     "CMD",
+	RegCTL0:    "CTL0",
+	RegMASK:    "MASK",
+	RegSTAT:    "STAT",
+	RegLOUT:    "LOUT",
+	RegCOR0:    "COR0",
+	RegMFWR:    "MFWR",
+	RegCBC:     "CBC",
+	RegIDCODE:  "IDCODE",
+	RegAXSS:    "AXSS",
+	RegCOR1:    "COR1",
+	RegWBSTAR:  "WBSTAR",
+	RegTIMER:   "TIMER",
+	RegRBCRCSW: "RBCRC_SW",
+	RegBOOTSTS: "BOOTSTS",
+	RegCTL1:    "CTL1",
+	RegBSPI:    "BSPI",
+}
+
+func (r Register) String() string {
+	if n, ok := registerNames[r]; ok {
+		return n
+	}
+	return fmt.Sprintf("REG(0x%02x)", int(r))
+}
+
+// Command is a value written to the CMD register.
+type Command uint32
+
+var commandNames = []string{
+	"NULL", "WCFG", "MFW", "LFRM", "RCFG", "START", "RCAP", "RCRC",
+	"AGHIGH", "SWITCH", "GRESTORE", "SHUTDOWN", "GCAPTURE", "DESYNC",
+	"RESERVED", "IPROG", "CRCC", "LTIMER", "BSPI_READ", "FALL_EDGE",
+}
+
+// CmdDESYNC ends the configuration packet stream.
+const CmdDESYNC Command = 13
+
+func (c Command) String() string {
+	if int(c) < len(commandNames) {
+		return commandNames[c]
+	}
+	return fmt.Sprintf("CMD(0x%x)", uint32(c))
+}
+
+// Packet is a single configuration packet.
+type Packet struct {
+	// Offset is the byte offset of the packet header in the data.
+	Offset int
+	// Type is the packet type, 1 or 2. Type 2 packets carry large payloads
+	// for the register addressed by the preceding type 1 packet.
+	Type int
+	// Op is the packet operation.
+	Op Opcode
+	// Reg is the addressed register.
+	Reg Register
+	// Words is the packet payload.
+	Words []uint32
+}
+
+// Decode decodes the configuration packet stream in data, which is the
+// configuration data of a .bit file, or a .bin file. Everything before the
+// sync word is skipped. After a DESYNC command, decoding resumes at the next
+// sync word, if any.
+func Decode(data []byte) ([]Packet, error) {
+	var ps []Packet
+	pos := findSync(data, 0)
+	if pos < 0 {
+		return nil, fmt.Errorf("no sync word found")
+	}
+	var reg Register
+	for pos >= 0 && pos+4 <= len(data) {
+		off := pos
+		h := binary.BigEndian.Uint32(data[pos:])
+		pos += 4
+		if h == SyncWord {
+			continue
+		}
+		var p Packet
+		var n int
+		switch h >> 29 {
+		case 1:
+			reg = Register((h >> 13) & 0x3fff)
+			p = Packet{Offset: off, Type: 1, Op: Opcode((h >> 27) & 0x3), Reg: reg}
+			n = int(h & 0x7ff)
+		case 2:
+			p = Packet{Offset: off, Type: 2, Op: Opcode((h >> 27) & 0x3), Reg: reg}
+			n = int(h & 0x7ffffff)
+		default:
+			return ps, fmt.Errorf("invalid packet header 0x%08x at offset 0x%x", h, off)
+		}
+		if pos+4*n > len(data) {
+			return ps, fmt.Errorf("truncated packet at offset 0x%x: want %d words", off, n)
+		}
+		p.Words = make([]uint32, n)
+		for i := range p.Words {
+			p.Words[i] = binary.BigEndian.Uint32(data[pos:])
+			pos += 4
+		}
+		ps = append(ps, p)
+		if p.Op == OpWrite && p.Reg == RegCMD && len(p.Words) > 0 && Command(p.Words[0]) == CmdDESYNC {
+			pos = findSync(data, pos)
+		}
+	}
+	return ps, nil
+}
+
+// findSync returns the offset of the first word after the sync word at or
+// after start, or -1 if there is none.
+func findSync(data []byte, start int) int {
+	for i := start; i+4 <= len(data); i++ {
+		if binary.BigEndian.Uint32(data[i:]) == SyncWord {
+			return i + 4
+		}
+	}
+	return -1
+}
+
+// FAR is a decoded 7-series frame address.
+type FAR struct {
+	Block, Row, Column, Minor int
+	Bottom                    bool
+}
+
+// DecodeFAR decodes a 7-series frame address register value.
+func DecodeFAR(v uint32) FAR {
+	return FAR{
+		Block:  int((v >> 23) & 0x7),
+		Bottom: (v>>22)&0x1 == 1,
+		Row:    int((v >> 17) & 0x1f),
+		Column: int((v >> 7) & 0x3ff),
+		Minor:  int(v & 0x7f),
+	}
+}
+
+func (f FAR) String() string {
+	half := "top"
+	if f.Bottom {
+		half = "bottom"
+	}
+	return fmt.Sprintf("block %d, %s, row %d, column %d, minor %d",
+		f.Block, half, f.Row, f.Column, f.Minor)
+}
+
+// describe returns a human readable description of the packet payload.
+func (p Packet) describe() string {
+	if len(p.Words) == 0 {
+		return ""
+	}
+	if len(p.Words) > 1 {
+		switch p.Reg {
+		case RegFDRI, RegFDRO:
+			return fmt.Sprintf("%d words", len(p.Words))
+		}
+	}
+	v := p.Words[0]
+	switch p.Reg {
+	case RegCMD:
+		return Command(v).String()
+	case RegFAR:
+		return fmt.Sprintf("0x%08x (%v)", v, DecodeFAR(v))
+	case RegCOR0:
+		return fmt.Sprintf("0x%08x (OSCFSEL %d, DONE_CYCLE %d, GTS_CYCLE %d, GWE_CYCLE %d, DRIVE_DONE %d)",
+			v, (v>>17)&0x3f, (v>>12)&0x7, (v>>3)&0x7, v&0x7, (v>>24)&0x1)
+	case RegMFWR:
+		return fmt.Sprintf("0x%08x (compression: multi-frame write)", v)
+	}
+	if len(p.Words) == 1 {
+		return fmt.Sprintf("0x%08x", v)
+	}
+	return fmt.Sprintf("%d words", len(p.Words))
+}
+
+func (p Packet) String() string {
+	if p.Op == OpNOP {
+		return "NOP"
+	}
+	s := fmt.Sprintf("%v %v", p.Op, p.Reg)
+	if p.Type == 2 {
+		s = "TYPE2 " + s
+	}
+	if d := p.describe(); d != "" {
+		s += " " + d
+	}
+	return s
+}
+
+// Dump writes a human readable listing of the packets to w. Runs of NOP
+// packets are listed once, with their count.
+func Dump(w io.Writer, ps []Packet) error {
+	for i := 0; i < len(ps); i++ {
+		p := ps[i]
+		s := p.String()
+		if p.Op == OpNOP {
+			n := 1
+			for i+1 < len(ps) && ps[i+1].Op == OpNOP {
+				n++
+				i++
+			}
+			if n > 1 {
+				s = fmt.Sprintf("%v x%d", s, n)
+			}
+		}
+		if _, err := fmt.Fprintf(w, "0x%08x: %v\n", p.Offset, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SwapBits reverses the bit order within every byte of b, as needed for
+// SelectMAP configuration where D0 carries the most significant bit.
+func SwapBits(b []byte) {
+	for i, v := range b {
+		b[i] = bits.Reverse8(v)
+	}
+}
+
+// SwapBytes reverses the byte order within every width-byte word of b.
+func SwapBytes(b []byte, width int) error {
+	if width <= 1 {
+		return nil
+	}
+	if len(b)%width != 0 {
+		return fmt.Errorf("length %d is not a multiple of the word width %d", len(b), width)
+	}
+	for i := 0; i < len(b); i += width {
+		for j, k := i, i+width-1; j < k; j, k = j+1, k-1 {
+			b[j], b[k] = b[k], b[j]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package bitstream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// words encodes 32-bit words in big-endian byte order.
+func words(ws ...uint32) []byte {
+	b := make([]byte, 4*len(ws))
+	for i, w := range ws {
+		binary.BigEndian.PutUint32(b[4*i:], w)
+	}
+	return b
+}
+
+// testStream is a minimal configuration packet stream.
+var testStream = words(
+	0xffffffff, 0x000000bb, 0x11220044, 0xffffffff,
+	SyncWord,
+	0x20000000,             // NOP
+	0x30008001, 0x00000007, // WRITE CMD RCRC
+	0x20000000, 0x20000000, // NOP x2
+	0x30018001, 0x03636093, // WRITE IDCODE
+	0x30012001, 0x02003fe5, // WRITE COR0
+	0x30002001, 0x00400000, // WRITE FAR
+	0x30004000, 0x50000003, // WRITE FDRI, then TYPE2 with 3 words
+	0x11111111, 0x22222222, 0x33333333,
+	0x30000001, 0x12345678, // WRITE CRC
+	0x30008001, 0x0000000d, // WRITE CMD DESYNC
+	0xffffffff,
+)
+
+func TestDecode(t *testing.T) {
+	ps, err := Decode(testStream)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	var got []string
+	for _, p := range ps {
+		got = append(got, p.String())
+	}
+	want := []string{
+		"NOP",
+		"WRITE CMD RCRC",
+		"NOP",
+		"NOP",
+		"WRITE IDCODE 0x03636093",
+		"WRITE COR0 0x02003fe5 (OSCFSEL 0, DONE_CYCLE 3, GTS_CYCLE 4, GWE_CYCLE 5, DRIVE_DONE 0)",
+		"WRITE FAR 0x00400000 (block 0, bottom, row 0, column 0, minor 0)",
+		"WRITE FDRI",
+		"TYPE2 WRITE FDRI 3 words",
+		"WRITE CRC 0x12345678",
+		"WRITE CMD DESYNC",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Decode() =\n%v\nwant:\n%v", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if ps[0].Offset != 20 {
+		t.Errorf("Decode() first offset = %d, want 20", ps[0].Offset)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "no sync word",
+			data: words(0xffffffff, 0x20000000),
+		},
+		{
+			name: "invalid header",
+			data: words(SyncWord, 0x80000000),
+		},
+		{
+			name: "truncated packet",
+			data: words(SyncWord, 0x30004002, 0x00000000),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.data); err == nil {
+				t.Errorf("Decode() want error")
+			}
+		})
+	}
+}
+
+func TestDump(t *testing.T) {
+	ps, err := Decode(testStream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := Dump(&b, ps[:4]); err != nil {
+		t.Fatal(err)
+	}
+	want := "0x00000014: NOP\n0x00000018: WRITE CMD RCRC\n0x00000020: NOP x2\n"
+	if b.String() != want {
+		t.Errorf("Dump() = %q, want %q", b.String(), want)
+	}
+}
+
+func TestSwap(t *testing.T) {
+	b := []byte{0x01, 0x80, 0xaa, 0x0f}
+	SwapBits(b)
+	if want := []byte{0x80, 0x01, 0x55, 0xf0}; !bytes.Equal(b, want) {
+		t.Errorf("SwapBits() = %x, want %x", b, want)
+	}
+
+	b = []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	if err := SwapBytes(b, 4); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x04, 0x03, 0x02, 0x01, 0x08, 0x07, 0x06, 0x05}; !bytes.Equal(b, want) {
+		t.Errorf("SwapBytes(4) = %x, want %x", b, want)
+	}
+	if err := SwapBytes([]byte{1, 2, 3}, 2); err == nil {
+		t.Errorf("SwapBytes() on a partial word: want error")
+	}
+}
+
+func TestFrames(t *testing.T) {
+	ps, err := Decode(testStream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames, writes := Frames(ps, 2)
+	if len(frames) != 2 {
+		t.Fatalf("Frames() = %d frames, want 2", len(frames))
+	}
+	if f := frames[1]; f.FAR != 0x00400000 || f.Index != 1 || len(f.Words) != 1 || f.Words[0] != 0x33333333 {
+		t.Errorf("Frames() second frame = %+v", f)
+	}
+	var regs []string
+	for _, w := range writes {
+		regs = append(regs, w.Reg.String())
+	}
+	if got, want := strings.Join(regs, ","), "CMD,IDCODE,COR0,FAR,CRC,CMD"; got != want {
+		t.Errorf("Frames() writes = %v, want %v", got, want)
+	}
+}
+
+func TestFrameWords(t *testing.T) {
+	tests := []struct {
+		part string
+		want int
+	}{
+		{"7a200tfbg484", 101},
+		{"xc7z020", 101},
+		{"zu3egsbva484", 93},
+		{"xcku040", 123},
+		{"ku5pffvb676", 93},
+		{"vu9pflga2104", 93},
+		{"xc3s500e", 0},
+	}
+	for _, tt := range tests {
+		if got := FrameWords(tt.part); got != tt.want {
+			t.Errorf("FrameWords(%q) = %v, want %v", tt.part, got, tt.want)
+		}
+	}
+}
+
+func TestPartFamily(t *testing.T) {
+	tests := []struct {
+		part string
+		want Family
+	}{
+		{"xc7a200tfbg484-2", Family7Series},
+		{"xa7s25csga225", Family7Series},
+		{"xczu3eg-sbva484-1-e", FamilyUltraScalePlus},
+		{"xcku040-ffva1156-2-e", FamilyUltraScale},
+		{"xcvu9p-flga2104-2L-e", FamilyUltraScalePlus},
+		{"xcau15p-ffvb676-2-e", FamilyUltraScalePlus},
+		{"xc3s500e", FamilyUnknown},
+		{"", FamilyUnknown},
+	}
+	for _, tt := range tests {
+		if got := PartFamily(tt.part); got != tt.want {
+			t.Errorf("PartFamily(%q) = %v, want %v", tt.part, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Package buildstamp encodes build provenance into the two 32-bit values
+// that a bitstream carries for the user, and decodes it back.
+//
+// USERID holds the short (7 hex digit) git commit and a flags nibble:
+//
+//	bits 31..4: commit
+//	bits  3..1: 0b001, marks the value as a build stamp
+//	bit      0: set if the working tree was dirty
+//
+// USR_ACCESS holds the build time in UTC, in the layout that Vivado uses for
+// `BITSTREAM.CONFIG.USR_ACCESS TIMESTAMP`, so that existing tools read it
+// the same way:
+//
+//	bits 31..27: day
+//	bits 26..23: month
+//	bits 22..17: year - 2000
+//	bits 16..12: hour
+//	bits 11..6:  minute
+//	bits  5..0:  second
+package buildstamp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"cp/build/vivado/lib/bitstream"
+)
+
+// Workspace status keys that the stamp is made of. The commit and dirty
+// keys are expected from the `--workspace_status_command`; Bazel writes
+// the build timestamp itself.
+const (
+	CommitKey    = "STABLE_GIT_COMMIT"
+	DirtyKey     = "STABLE_GIT_DIRTY"
+	TimestampKey = "BUILD_TIMESTAMP"
+)
+
+// CommitDigits is the number of commit hex digits that fit into USERID.
+const CommitDigits = 7
+
+const (
+	flagMask   = 0xe
+	flagMarker = 0x2
+	flagDirty  = 0x1
+)
+
+// ErrNotStamped is returned when a USERID value does not hold a build stamp,
+// e.g. the default 0xFFFFFFFF.
+var ErrNotStamped = errors.New("no build stamp")
+
+// Stamp is the build provenance of a bitstream.
+type Stamp struct {
+	// Commit is the short git commit, in lower case hex.
+	Commit string
+	// Dirty is set if the working tree had uncommitted changes.
+	Dirty bool
+	// Time is the build time, with a resolution of one second.
+	Time time.Time
+}
+
+func (s Stamp) String() string {
+	c := s.Commit
+	if s.Dirty {
+		c += "-dirty"
+	}
+	return fmt.Sprintf("%v built %v", c, s.Time.UTC().Format("2006-01-02 15:04:05 MST"))
+}
+
+// UserID returns the USERID value of the stamp.
+func (s Stamp) UserID() (uint32, error) {
+	if len(s.Commit) < CommitDigits {
+		return 0, fmt.Errorf("want at least %d commit hex digits, got: %q", CommitDigits, s.Commit)
+	}
+	c, err := strconv.ParseUint(s.Commit[:CommitDigits], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid commit %q: %w", s.Commit, err)
+	}
+	v := uint32(c)<<4 | flagMarker
+	if s.Dirty {
+		v |= flagDirty
+	}
+	return v, nil
+}
+
+// UsrAccess returns the USR_ACCESS value of the stamp.
+func (s Stamp) UsrAccess() (uint32, error) {
+	t := s.Time.UTC()
+	if t.Year() < 2000 || t.Year() > 2063 {
+		return 0, fmt.Errorf("build year %d does not fit, want 2000 to 2063", t.Year())
+	}
+	return uint32(t.Day())<<27 |
+		uint32(t.Month())<<23 |
+		uint32(t.Year()-2000)<<17 |
+		uint32(t.Hour())<<12 |
+		uint32(t.Minute())<<6 |
+		uint32(t.Second()), nil
+}
+
+// DecodeTime decodes a USR_ACCESS value in the Vivado TIMESTAMP layout.
+func DecodeTime(usrAccess uint32) time.Time {
+	return time.Date(
+		2000+int(usrAccess>>17&0x3f),
+		time.Month(usrAccess>>23&0xf),
+		int(usrAccess>>27&0x1f),
+		int(usrAccess>>12&0x1f),
+		int(usrAccess>>6&0x3f),
+		int(usrAccess&0x3f),
+		0, time.UTC)
+}
+
+// Decode decodes the stamp from the USERID and USR_ACCESS values.
+func Decode(userID, usrAccess uint32) (Stamp, error) {
+	if userID&flagMask != flagMarker {
+		return Stamp{}, fmt.Errorf("USERID 0x%08X: %w", userID, ErrNotStamped)
+	}
+	return Stamp{
+		Commit: fmt.Sprintf("%0*x", CommitDigits, userID>>4),
+		Dirty:  userID&flagDirty != 0,
+		Time:   DecodeTime(usrAccess),
+	}, nil
+}
+
+// ParseWord parses a 32-bit hex value such as "0XDEADBEEF", the way Vivado
+// reports USERID and USR_ACCESS.
+func ParseWord(s string) (uint32, error) {
+	h := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "0x"), "0X")
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("want a 32-bit hex value, got %q: %w", s, err)
+	}
+	return uint32(v), nil
+}
+
+// ReadStatusFiles reads Bazel workspace status files, such as
+// stable-status.txt and volatile-status.txt. Each line is a key, a space,
+// and a value.
+func ReadStatusFiles(fns ...string) (map[string]string, error) {
+	status := map[string]string{}
+	for _, fn := range fns {
+		f, err := os.Open(fn)
+		if err != nil {
+			return nil, fmt.Errorf("open status file: %w", err)
+		}
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			k, v, _ := strings.Cut(s.Text(), " ")
+			if k != "" {
+				status[k] = strings.TrimSpace(v)
+			}
+		}
+		err = s.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read status file %v: %w", fn, err)
+		}
+	}
+	return status, nil
+}
+
+// FromStatus makes a stamp from workspace status values.
+func FromStatus(status map[string]string) (Stamp, error) {
+	var s Stamp
+	s.Commit = strings.ToLower(status[CommitKey])
+	if s.Commit == "" {
+		return Stamp{}, fmt.Errorf("no %v in the workspace status, is --workspace_status_command set?", CommitKey)
+	}
+	switch strings.ToLower(status[DirtyKey]) {
+	case "", "0", "false", "clean":
+	default:
+		s.Dirty = true
+	}
+	ts, ok := status[TimestampKey]
+	if !ok {
+		return Stamp{}, fmt.Errorf("no %v in the workspace status", TimestampKey)
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return Stamp{}, fmt.Errorf("invalid %v %q: %w", TimestampKey, ts, err)
+	}
+	s.Time = time.Unix(sec, 0).UTC()
+	// Check that the stamp can be encoded at all.
+	if _, err := s.UserID(); err != nil {
+		return Stamp{}, err
+	}
+	if _, err := s.UsrAccess(); err != nil {
+		return Stamp{}, err
+	}
+	return s, nil
+}
+
+// FromBitFile reads the stamp from the .bit file fn. USERID comes from the
+// header, USR_ACCESS from the write to the AXSS configuration register.
+func FromBitFile(fn string) (Stamp, error) {
+	h, d, err := bitstream.ReadFile(fn)
+	if err != nil {
+		return Stamp{}, err
+	}
+	return FromBitstream(h, d)
+}
+
+// FromBitstream reads the stamp from the header and configuration data of a
+// .bit file.
+func FromBitstream(h *bitstream.Header, data []byte) (Stamp, error) {
+	if h.UserID == "" {
+		return Stamp{}, fmt.Errorf("no USERID in the bitstream header: %w", ErrNotStamped)
+	}
+	userID, err := ParseWord(h.UserID)
+	if err != nil {
+		return Stamp{}, fmt.Errorf("USERID: %w", err)
+	}
+	ps, err := bitstream.Decode(data)
+	if err != nil {
+		return Stamp{}, fmt.Errorf("decode bitstream: %w", err)
+	}
+	var usrAccess uint32
+	found := false
+	for _, p := range ps {
+		if p.Op == bitstream.OpWrite && p.Reg == bitstream.RegAXSS && len(p.Words) == 1 {
+			usrAccess = p.Words[0]
+			found = true
+		}
+	}
+	if !found {
+		return Stamp{}, fmt.Errorf("no USR_ACCESS in the bitstream: %w", ErrNotStamped)
+	}
+	return Decode(userID, usrAccess)
+}
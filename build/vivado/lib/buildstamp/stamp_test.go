@@ -0,0 +1,142 @@
+package buildstamp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// makeBit assembles a .bit file with the given design field and
+// configuration words.
+func makeBit(design string, ws ...uint32) []byte {
+	var b bytes.Buffer
+	b.Write([]byte{0x00, 0x09, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x0f, 0xf0, 0x00, 0x00, 0x01})
+	for _, f := range []struct {
+		key byte
+		val string
+	}{{'a', design}, {'b', "7a35tcsg324"}, {'c', "2026/10/19"}, {'d', "12:00:00"}} {
+		b.WriteByte(f.key)
+		binary.Write(&b, binary.BigEndian, uint16(len(f.val)+1))
+		b.WriteString(f.val)
+		b.WriteByte(0)
+	}
+	b.WriteByte('e')
+	binary.Write(&b, binary.BigEndian, uint32(4*len(ws)))
+	for _, w := range ws {
+		binary.Write(&b, binary.BigEndian, w)
+	}
+	return b.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	s := Stamp{
+		Commit: "1a2b3c4",
+		Dirty:  true,
+		Time:   time.Date(2026, 10, 19, 13, 14, 15, 0, time.UTC),
+	}
+	u, err := s.UserID()
+	if err != nil {
+		t.Fatalf("UserID() error = %v", err)
+	}
+	if u != 0x1a2b3c43 {
+		t.Errorf("UserID() = 0x%08x, want 0x1a2b3c43", u)
+	}
+	a, err := s.UsrAccess()
+	if err != nil {
+		t.Fatalf("UsrAccess() error = %v", err)
+	}
+	got, err := Decode(u, a)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got != s {
+		t.Errorf("Decode() = %v, want %v", got, s)
+	}
+	if got, want := got.String(), "1a2b3c4-dirty built 2026-10-19 13:14:15 UTC"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestDecodeNotStamped(t *testing.T) {
+	for _, u := range []uint32{0xffffffff, 0} {
+		if _, err := Decode(u, 0); !errors.Is(err, ErrNotStamped) {
+			t.Errorf("Decode(0x%08x) error = %v, want ErrNotStamped", u, err)
+		}
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	tmpDir := t.TempDir()
+	stable := filepath.Join(tmpDir, "stable-status.txt")
+	volatile := filepath.Join(tmpDir, "volatile-status.txt")
+	if err := os.WriteFile(stable, []byte("BUILD_EMBED_LABEL \nSTABLE_GIT_COMMIT 0123456789ABCDEF\nSTABLE_GIT_DIRTY 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(volatile, []byte("BUILD_TIMESTAMP 1792415655\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	status, err := ReadStatusFiles(stable, volatile)
+	if err != nil {
+		t.Fatalf("ReadStatusFiles() error = %v", err)
+	}
+	s, err := FromStatus(status)
+	if err != nil {
+		t.Fatalf("FromStatus() error = %v", err)
+	}
+	want := Stamp{Commit: "0123456789abcdef", Time: time.Unix(1792415655, 0).UTC()}
+	if s != want {
+		t.Errorf("FromStatus() = %v, want %v", s, want)
+	}
+
+	tests := []struct {
+		name   string
+		status map[string]string
+	}{
+		{"No commit", map[string]string{TimestampKey: "1792415655"}},
+		{"Short commit", map[string]string{CommitKey: "abc", TimestampKey: "1792415655"}},
+		{"Not hex", map[string]string{CommitKey: "release1", TimestampKey: "1792415655"}},
+		{"No timestamp", map[string]string{CommitKey: "0123456"}},
+		{"Bad timestamp", map[string]string{CommitKey: "0123456", TimestampKey: "now"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := FromStatus(tt.status); err == nil {
+				t.Errorf("FromStatus() want error")
+			}
+		})
+	}
+}
+
+func TestFromBitFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	fn := filepath.Join(tmpDir, "top.bit")
+	b := makeBit("top;UserID=0X1A2B3C42;Version=2025.2",
+		0xffffffff, 0xaa995566,
+		0x3001a001, 0x9d34d38f, // WRITE AXSS
+		0x30008001, 0x0000000d, // WRITE CMD DESYNC
+	)
+	if err := os.WriteFile(fn, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := FromBitFile(fn)
+	if err != nil {
+		t.Fatalf("FromBitFile() error = %v", err)
+	}
+	want := Stamp{Commit: "1a2b3c4", Time: DecodeTime(0x9d34d38f)}
+	if s != want {
+		t.Errorf("FromBitFile() = %v, want %v", s, want)
+	}
+
+	plain := filepath.Join(tmpDir, "plain.bit")
+	b = makeBit("top;UserID=0XFFFFFFFF;Version=2025.2", 0xaa995566, 0x20000000)
+	if err := os.WriteFile(plain, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FromBitFile(plain); !errors.Is(err, ErrNotStamped) {
+		t.Errorf("FromBitFile() error = %v, want ErrNotStamped", err)
+	}
+}
@@ -0,0 +1,17 @@
+// Package flags has the flag values shared by the tools.
+package flags
+
+import "strings"
+
+// Strings is a flag that can be given several times. It keeps the values in
+// the order they are given.
+type Strings []string
+
+func (s *Strings) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func (s *Strings) String() string {
+	return strings.Join(*s, ",")
+}
@@ -0,0 +1,24 @@
+package flags
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestStrings(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var logs Strings
+	fs.Var(&logs, "log", "")
+	if err := fs.Parse([]string{"--log", "synth.log", "--log", "pnr.log"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if want := (Strings{"synth.log", "pnr.log"}); !reflect.DeepEqual(logs, want) {
+		t.Errorf("logs = %q, want %q", logs, want)
+	}
+	if got, want := logs.String(), "synth.log,pnr.log"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
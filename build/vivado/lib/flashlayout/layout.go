@@ -0,0 +1,204 @@
+// Package flashlayout describes how MultiBoot images are laid out in a
+// configuration flash.
+//
+// A layout is a JSON file such as:
+//
+//	{
+//	  "flash_size_mb": 16,
+//	  "sector_size": "0x10000",
+//	  "interface": "SPIx4",
+//	  "images": [
+//	    {"name": "golden", "address": "0x0", "size": "0x400000"},
+//	    {"name": "update", "address": "0x400000"}
+//	  ]
+//	}
+//
+// Images are listed in ascending address order. The first one, at address 0,
+// is the golden image that the FPGA loads at power-up. It jumps to the second
+// image, the update image. If the update image fails to load, the FPGA falls
+// back to the golden image. An image without a size extends up to the next
+// image, or the end of the flash.
+package flashlayout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Address is a flash address or size. In JSON, it is either a number or a
+// string such as "0x400000".
+type Address uint64
+
+func (a *Address) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	v, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return fmt.Errorf("invalid address: %v", string(b))
+	}
+	*a = Address(v)
+	return nil
+}
+
+func (a Address) String() string {
+	return fmt.Sprintf("0x%08x", uint64(a))
+}
+
+// Layout is a flash layout.
+type Layout struct {
+	// FlashSizeMB is the flash size in megabytes.
+	FlashSizeMB int `json:"flash_size_mb"`
+	// SectorSize is the erase sector size of the flash in bytes. Images must
+	// start at sector boundaries, so that erasing one never touches another.
+	SectorSize Address `json:"sector_size"`
+	// Interface is the flash interface, e.g. "SPIx4", the default.
+	Interface string `json:"interface"`
+	// Images are the images in the flash, in ascending address order.
+	Images []Image `json:"images"`
+}
+
+// Image is a region of the flash holding one bitstream.
+type Image struct {
+	Name    string  `json:"name"`
+	Address Address `json:"address"`
+	// Size is the size reserved for the image. If zero, the image extends up
+	// to the next image, or the end of the flash.
+	Size Address `json:"size"`
+}
+
+// End returns the address just past the region of the image.
+func (i Image) End() Address {
+	return i.Address + i.Size
+}
+
+// Property is a bitstream property, set with set_property on the design.
+type Property struct {
+	Name, Value string
+}
+
+// Read reads and validates the layout from the file fn.
+func Read(fn string) (*Layout, error) {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	l, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", fn, err)
+	}
+	return l, nil
+}
+
+// Parse parses and validates a layout.
+func Parse(b []byte) (*Layout, error) {
+	var l Layout
+	if err := json.Unmarshal(b, &l); err != nil {
+		return nil, err
+	}
+	if err := l.validate(); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// FlashSize returns the flash size in bytes.
+func (l *Layout) FlashSize() Address {
+	return Address(l.FlashSizeMB) << 20
+}
+
+// validate checks the layout, and fills in the image sizes.
+func (l *Layout) validate() error {
+	if l.FlashSizeMB <= 0 {
+		return fmt.Errorf("flash_size_mb must be positive, got: %v", l.FlashSizeMB)
+	}
+	if l.SectorSize == 0 {
+		return fmt.Errorf("sector_size is required")
+	}
+	if l.Interface == "" {
+		l.Interface = "SPIx4"
+	}
+	if len(l.Images) == 0 {
+		return fmt.Errorf("no images")
+	}
+	if l.Images[0].Address != 0 {
+		return fmt.Errorf("the golden image %q must be at address 0, got: %v",
+			l.Images[0].Name, l.Images[0].Address)
+	}
+	names := map[string]bool{}
+	for i := range l.Images {
+		im := &l.Images[i]
+		if im.Name == "" {
+			return fmt.Errorf("image at %v has no name", im.Address)
+		}
+		if names[im.Name] {
+			return fmt.Errorf("duplicate image name: %q", im.Name)
+		}
+		names[im.Name] = true
+		if im.Address%l.SectorSize != 0 {
+			return fmt.Errorf("image %q at %v is not aligned to the %v sector size",
+				im.Name, im.Address, l.SectorSize)
+		}
+		end := l.FlashSize()
+		if i+1 < len(l.Images) {
+			next := l.Images[i+1]
+			if next.Address <= im.Address {
+				return fmt.Errorf("images must be in ascending address order, %q at %v follows %q at %v",
+					next.Name, next.Address, im.Name, im.Address)
+			}
+			end = next.Address
+		}
+		if im.Size == 0 {
+			im.Size = end - im.Address
+		}
+		if im.Size%l.SectorSize != 0 {
+			return fmt.Errorf("image %q size %v is not a multiple of the %v sector size",
+				im.Name, im.Size, l.SectorSize)
+		}
+		if im.End() > l.FlashSize() {
+			return fmt.Errorf("image %q at %v-%v does not fit into the %d MB flash",
+				im.Name, im.Address, im.End(), l.FlashSizeMB)
+		}
+		if im.End() > end {
+			return fmt.Errorf("image %q at %v-%v overlaps image %q at %v",
+				im.Name, im.Address, im.End(), l.Images[i+1].Name, end)
+		}
+	}
+	return nil
+}
+
+// Image returns the image with the given name.
+func (l *Layout) Image(name string) (*Image, error) {
+	for i := range l.Images {
+		if l.Images[i].Name == name {
+			return &l.Images[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no image %q in the layout", name)
+}
+
+// Properties returns the bitstream properties that the image with the given
+// name must be built with.
+func (l *Layout) Properties(name string) ([]Property, error) {
+	if _, err := l.Image(name); err != nil {
+		return nil, err
+	}
+	var ps []Property
+	if len(l.Images) > 1 {
+		if name == l.Images[0].Name {
+			ps = append(ps, Property{"BITSTREAM.CONFIG.NEXT_CONFIG_ADDR", l.Images[1].Address.String()})
+		}
+		ps = append(ps, Property{"BITSTREAM.CONFIG.CONFIGFALLBACK", "ENABLE"})
+	}
+	if strings.HasPrefix(l.Interface, "SPI") {
+		if w := strings.TrimPrefix(l.Interface, "SPIx"); w != "1" {
+			ps = append(ps, Property{"BITSTREAM.CONFIG.SPI_BUSWIDTH", w})
+		}
+		if l.FlashSizeMB > 16 {
+			// Flashes over 128 Mbit need 4-byte addressing.
+			ps = append(ps, Property{"BITSTREAM.CONFIG.SPI_32BIT_ADDR", "YES"})
+		}
+	}
+	return ps, nil
+}
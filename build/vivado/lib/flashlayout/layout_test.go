@@ -0,0 +1,101 @@
+package flashlayout
+
+import (
+	"fmt"
+	"testing"
+)
+
+const testLayout = `{
+  "flash_size_mb": 32,
+  "sector_size": "0x10000",
+  "images": [
+    {"name": "golden", "address": "0x0", "size": "0x400000"},
+    {"name": "update", "address": 4194304}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	l, err := Parse([]byte(testLayout))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if l.Interface != "SPIx4" {
+		t.Errorf("Parse() Interface = %v, want SPIx4", l.Interface)
+	}
+	im, err := l.Image("update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if im.Address != 0x400000 || im.End() != 0x2000000 {
+		t.Errorf("Parse() update image = %v-%v, want 0x00400000-0x02000000", im.Address, im.End())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string
+	}{
+		{"no flash size", `{"sector_size": 4096, "images": [{"name": "a", "address": 0}]}`},
+		{"no sector size", `{"flash_size_mb": 16, "images": [{"name": "a", "address": 0}]}`},
+		{"no images", `{"flash_size_mb": 16, "sector_size": 4096}`},
+		{"golden not at 0", `{"flash_size_mb": 16, "sector_size": 4096, "images": [{"name": "a", "address": 4096}]}`},
+		{"unaligned", `{"flash_size_mb": 16, "sector_size": 4096, "images": [
+			{"name": "a", "address": 0}, {"name": "b", "address": "0x1800"}]}`},
+		{"unaligned size", `{"flash_size_mb": 16, "sector_size": 4096, "images": [
+			{"name": "a", "address": 0, "size": "0x800"}]}`},
+		{"overlap", `{"flash_size_mb": 16, "sector_size": 4096, "images": [
+			{"name": "a", "address": 0, "size": "0x2000"}, {"name": "b", "address": "0x1000"}]}`},
+		{"out of order", `{"flash_size_mb": 16, "sector_size": 4096, "images": [
+			{"name": "a", "address": 0}, {"name": "b", "address": "0x2000"}, {"name": "c", "address": "0x1000"}]}`},
+		{"too large", `{"flash_size_mb": 1, "sector_size": 4096, "images": [
+			{"name": "a", "address": 0, "size": "0x200000"}]}`},
+		{"duplicate name", `{"flash_size_mb": 16, "sector_size": 4096, "images": [
+			{"name": "a", "address": 0}, {"name": "a", "address": "0x1000"}]}`},
+		{"bad address", `{"flash_size_mb": 16, "sector_size": 4096, "images": [{"name": "a", "address": "zero"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.layout)); err == nil {
+				t.Errorf("Parse() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestProperties(t *testing.T) {
+	l, err := Parse([]byte(testLayout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		image   string
+		want    string
+		wantErr bool
+	}{
+		{
+			image: "golden",
+			want: "[{BITSTREAM.CONFIG.NEXT_CONFIG_ADDR 0x00400000} {BITSTREAM.CONFIG.CONFIGFALLBACK ENABLE} " +
+				"{BITSTREAM.CONFIG.SPI_BUSWIDTH 4} {BITSTREAM.CONFIG.SPI_32BIT_ADDR YES}]",
+		},
+		{
+			image: "update",
+			want:  "[{BITSTREAM.CONFIG.CONFIGFALLBACK ENABLE} {BITSTREAM.CONFIG.SPI_BUSWIDTH 4} {BITSTREAM.CONFIG.SPI_32BIT_ADDR YES}]",
+		},
+		{
+			image:   "other",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			ps, err := l.Properties(tt.image)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Properties() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := fmt.Sprint(ps); !tt.wantErr && got != tt.want {
+				t.Errorf("Properties() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// noPaths matches the line of a report that says it has nothing to report,
+// e.g. "No clock domain crossings found." or "There are no clock
+// interactions.".
+var noPaths = regexp.MustCompile(`(?im)^\s*(there (are|were) )?no\b.*\b(paths|crossings|clocks|interactions)\b`)
+
+// readAllTables reads the tables of r in both layouts, bordered and in
+// columns, and whether r says that it has nothing to report.
+func readAllTables(r io.Reader) (tables []Table, none bool, err error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, false, fmt.Errorf("read report: %w", err)
+	}
+	if tables, err = ParseTables(bytes.NewReader(b)); err != nil {
+		return nil, false, err
+	}
+	columns, err := ParseColumnTables(bytes.NewReader(b))
+	if err != nil {
+		return nil, false, err
+	}
+	return append(tables, columns...), noPaths.Match(b), nil
+}
+
+// ParseCDC parses the text output of `report_cdc -details` into its clock
+// domain crossings. Each has its Category set to "safe", "unsafe" or
+// "unknown". The report must have a table of crossings, an empty summary or
+// a line saying that there are none; anything else is an error, so that a
+// report in an unknown layout does not pass as one without crossings.
+func ParseCDC(r io.Reader) ([]Violation, error) {
+	tables, none, err := readAllTables(r)
+	if err != nil {
+		return nil, err
+	}
+	var vs []Violation
+	found := none
+	for _, t := range tables {
+		// The summary tables have a severity too, but no endpoints.
+		endpoint := t.Column("Destination", "Endpoint", "To")
+		if t.Column("Severity") < 0 {
+			continue
+		}
+		if t.Column("ID") < 0 || endpoint < 0 {
+			found = found || len(t.Rows) == 0
+			continue
+		}
+		found = true
+		for _, row := range t.Rows {
+			v := Violation{
+				ID:          Cell(row, t.Column("ID")),
+				Severity:    Cell(row, t.Column("Severity")),
+				Description: Cell(row, t.Column("Description")),
+				FromClock:   Cell(row, t.Column("Source Clock", "From Clock")),
+				ToClock:     Cell(row, t.Column("Destination Clock", "To Clock")),
+				Startpoint:  Cell(row, t.Column("Source", "Startpoint", "From")),
+				Endpoint:    Cell(row, endpoint),
+			}
+			if v.ID == "" {
+				continue
+			}
+			if v.FromClock == "" {
+				v.FromClock = firstLabel(t, "Source Clock", "From Clock")
+			}
+			if v.ToClock == "" {
+				v.ToClock = firstLabel(t, "Destination Clock", "To Clock")
+			}
+			v.Category = cdcCategory(Cell(row, t.Column("Category")), v.Description)
+			vs = append(vs, v)
+		}
+	}
+	if !found {
+		return nil, errors.New("no clock domain crossing table in the report")
+	}
+	return vs, nil
+}
+
+// cdcCategory classifies a crossing from its category, or from its
+// description if the report has no category column.
+func cdcCategory(category, description string) string {
+	s := strings.ToLower(category)
+	if s == "" {
+		s = strings.ToLower(description)
+	}
+	switch {
+	case strings.Contains(s, "unsafe"):
+		return "unsafe"
+	case strings.Contains(s, "unknown"):
+		return "unknown"
+	}
+	return "safe"
+}
+
+// firstLabel returns the first label of t that is set of names.
+func firstLabel(t Table, names ...string) string {
+	for _, n := range names {
+		if v := t.Labels[n]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ClockPair is a row of `report_clock_interaction`.
+type ClockPair struct {
+	FromClock string `json:"from_clock"`
+	ToClock   string `json:"to_clock"`
+	// Classification is e.g. "Clean", "No Common Clock" or "Ignored".
+	Classification string `json:"classification"`
+	// Constraints are the inter-clock constraints, e.g. "Timed",
+	// "Asynchronous Groups" or "Timed (unsafe)".
+	Constraints string `json:"constraints"`
+}
+
+// Unsafe returns true if the paths between the clocks are timed, but the
+// clocks have no known phase relationship, such as two unrelated clocks
+// without a set_clock_groups.
+func (p ClockPair) Unsafe() bool {
+	return strings.Contains(strings.ToLower(p.Constraints), "unsafe")
+}
+
+// Violation returns the critical violation of an unsafe clock pair, with
+// ID "clock_interaction", so that waivers apply to it.
+func (p ClockPair) Violation() Violation {
+	return Violation{
+		ID:          "clock_interaction",
+		Severity:    "Critical",
+		Category:    "unsafe",
+		Description: p.Classification + ", " + p.Constraints,
+		FromClock:   p.FromClock,
+		ToClock:     p.ToClock,
+	}
+}
+
+// ParseClockInteraction parses the text output of
+// `report_clock_interaction`. Like ParseCDC, it is an error if the report
+// has neither a clock interaction table nor a line saying there are no
+// clocks.
+func ParseClockInteraction(r io.Reader) ([]ClockPair, error) {
+	tables, none, err := readAllTables(r)
+	if err != nil {
+		return nil, err
+	}
+	var ps []ClockPair
+	found := none
+	for _, t := range tables {
+		from, to := t.Column("From Clock"), t.Column("To Clock")
+		if from < 0 || to < 0 {
+			continue
+		}
+		found = true
+		for _, row := range t.Rows {
+			p := ClockPair{
+				FromClock:      Cell(row, from),
+				ToClock:        Cell(row, to),
+				Classification: Cell(row, t.Column("Clock Pair Classification")),
+				Constraints:    Cell(row, t.Column("Inter-Clock Constraints")),
+			}
+			if p.FromClock != "" {
+				ps = append(ps, p)
+			}
+		}
+	}
+	if !found {
+		return nil, errors.New("no clock interaction table in the report")
+	}
+	return ps, nil
+}
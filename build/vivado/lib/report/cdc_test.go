@@ -0,0 +1,206 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// cdcReport and clockInteractionReport are laid out like the reports of
+// Vivado: columns under dashes, with sections underlined.
+const cdcReport = `Copyright 1986-2022 Xilinx, Inc. All Rights Reserved.
+-------------------------------------------------------------------------------------
+| Tool Version : Vivado v.2022.2 (lin64) Build 3671981 Fri Oct 14 04:59:54 MDT 2022
+| Command      : report_cdc -details -file cdc.rpt
+| Design       : top
+-------------------------------------------------------------------------------------
+
+CDC Report
+
+Severity  Source Clock  Destination Clock  CDC Type         Exceptions  Endpoints  Safe  Unsafe  Unknown  No ASYNC_REG
+--------  ------------  -----------------  --------         ----------  ---------  ----  ------  -------  ------------
+Critical  clk_adc       clk_sys            No Common Clock  None                2     1       0        1             0
+
+
+Details
+-------
+
+Source Clock: clk_adc
+Destination Clock: clk_sys
+
+Severity  ID     Description                                 Depth  Exception  Source                Destination            Category
+--------  -----  ------------------------------------------  -----  ---------  --------------------  ---------------------  --------
+Critical  CDC-1  1-bit unknown CDC circuitry                 0      None       u_adc/valid_reg/C     u_sys/valid_q_reg/D    Unknown
+Warning   CDC-3  1-bit synchronized with ASYNC_REG property  2      None       u_adc/flag_reg/C      u_sync/sync_reg[0]/D   Safe
+`
+
+const clockInteractionReport = `Copyright 1986-2022 Xilinx, Inc. All Rights Reserved.
+--------------------------------------------------------------------------------------
+| Tool Version : Vivado v.2022.2 (lin64) Build 3671981 Fri Oct 14 04:59:54 MDT 2022
+| Command      : report_clock_interaction -delay_type min_max -file clocks.rpt
+| Design       : top
+--------------------------------------------------------------------------------------
+
+Clock Interaction Report
+
+Clock Interaction Table
+-----------------------
+
+                                                                                    WNS Path         Clock Pair       Inter-Clock
+From Clock  To Clock  WNS(ns)  TNS(ns)  TNS Failing Endpoints  TNS Total Endpoints  Requirement(ns)  Classification   Constraints
+----------  --------  -------  -------  ---------------------  -------------------  ---------------  ---------------  -------------------
+clk_sys     clk_sys     1.234    0.000                      0                 1024           10.000  Clean            Timed
+clk_adc     clk_sys     0.512    0.000                      0                    2            1.000  No Common Clock  Timed (unsafe)
+clk_sys     clk_adc        --       --                     --                   --               --  No Common Clock  Asynchronous Groups
+`
+
+// borderedCDCReport and borderedClockInteractionReport have the same
+// crossings and clocks, in bordered tables.
+const borderedCDCReport = `CDC Report
+
+1. Summary
+----------
+
++----------+-------+-------+------------------------------+
+| Severity | ID    | Count | Description                  |
++----------+-------+-------+------------------------------+
+| Critical | CDC-1 |     1 | 1-bit unknown CDC circuitry  |
+| Warning  | CDC-3 |     1 | 1-bit synchronized with ASYNC_REG property |
++----------+-------+-------+------------------------------+
+
+
+2. Details
+----------
+
+Source Clock: clk_adc
+Destination Clock: clk_sys
+
++----------+-------+---------------------------------------------+-----------+----------------------+----------------------------+----------+
+| Severity | ID    | Description                                 | Exception | Source               | Destination                | Category |
++----------+-------+---------------------------------------------+-----------+----------------------+----------------------------+----------+
+| Critical | CDC-1 | 1-bit unknown CDC circuitry                 | None      | u_adc/valid_reg/C    | u_sys/valid_q_reg/D        | Unknown  |
+| Warning  | CDC-3 | 1-bit synchronized with ASYNC_REG property  | None      | u_adc/flag_reg/C     | u_sync/sync_reg[0]/D       | Safe     |
++----------+-------+---------------------------------------------+-----------+----------------------+----------------------------+----------+
+`
+
+const borderedClockInteractionReport = `Clock Interaction Report
+
+1. Clock Interaction Table
+--------------------------
+
++------------+------------+---------------------------+-------------------------+
+| From Clock | To Clock   | Clock Pair Classification | Inter-Clock Constraints |
++------------+------------+---------------------------+-------------------------+
+| clk_sys    | clk_sys    | Clean                     | Timed                   |
+| clk_adc    | clk_sys    | No Common Clock           | Timed (unsafe)          |
+| clk_sys    | clk_adc    | No Common Clock           | Asynchronous Groups     |
++------------+------------+---------------------------+-------------------------+
+`
+
+func TestParseCDC(t *testing.T) {
+	want := []Violation{
+		{
+			ID:          "CDC-1",
+			Severity:    "Critical",
+			Category:    "unknown",
+			Description: "1-bit unknown CDC circuitry",
+			FromClock:   "clk_adc",
+			ToClock:     "clk_sys",
+			Startpoint:  "u_adc/valid_reg/C",
+			Endpoint:    "u_sys/valid_q_reg/D",
+		},
+		{
+			ID:          "CDC-3",
+			Severity:    "Warning",
+			Category:    "safe",
+			Description: "1-bit synchronized with ASYNC_REG property",
+			FromClock:   "clk_adc",
+			ToClock:     "clk_sys",
+			Startpoint:  "u_adc/flag_reg/C",
+			Endpoint:    "u_sync/sync_reg[0]/D",
+		},
+	}
+	for _, report := range []string{cdcReport, borderedCDCReport} {
+		vs, err := ParseCDC(strings.NewReader(report))
+		if err != nil {
+			t.Fatalf("ParseCDC() error = %v", err)
+		}
+		if !reflect.DeepEqual(vs, want) {
+			t.Errorf("ParseCDC() = %+v, want %+v", vs, want)
+		}
+	}
+}
+
+func TestParseCDCEmpty(t *testing.T) {
+	tests := []struct {
+		name    string
+		report  string
+		wantErr bool
+	}{
+		{
+			name:   "No crossings",
+			report: "CDC Report\n\nNo clock domain crossings found.\n",
+		},
+		{
+			name: "Empty summary",
+			report: "CDC Report\n\n" +
+				"Severity  Source Clock  Destination Clock  CDC Type  Exceptions  Endpoints\n" +
+				"--------  ------------  -----------------  --------  ----------  ---------\n",
+		},
+		{
+			name: "Summary without details",
+			report: "CDC Report\n\n" +
+				"Severity  Source Clock  Destination Clock  CDC Type         Exceptions  Endpoints\n" +
+				"--------  ------------  -----------------  --------         ----------  ---------\n" +
+				"Critical  clk_adc       clk_sys            No Common Clock  None                2\n",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown layout",
+			report:  "CDC Report\n\nclk_adc -> clk_sys: 2 unsafe endpoints\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs, err := ParseCDC(strings.NewReader(tt.report))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCDC() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(vs) != 0 {
+				t.Errorf("ParseCDC() = %+v, want none", vs)
+			}
+		})
+	}
+}
+
+func TestParseClockInteraction(t *testing.T) {
+	for _, report := range []string{clockInteractionReport, borderedClockInteractionReport} {
+		ps, err := ParseClockInteraction(strings.NewReader(report))
+		if err != nil {
+			t.Fatalf("ParseClockInteraction() error = %v", err)
+		}
+		want := []ClockPair{
+			{FromClock: "clk_sys", ToClock: "clk_sys", Classification: "Clean", Constraints: "Timed"},
+			{FromClock: "clk_adc", ToClock: "clk_sys", Classification: "No Common Clock", Constraints: "Timed (unsafe)"},
+			{FromClock: "clk_sys", ToClock: "clk_adc", Classification: "No Common Clock", Constraints: "Asynchronous Groups"},
+		}
+		if !reflect.DeepEqual(ps, want) {
+			t.Fatalf("ParseClockInteraction() = %+v, want %+v", ps, want)
+		}
+		for i, want := range []bool{false, true, false} {
+			if got := ps[i].Unsafe(); got != want {
+				t.Errorf("%+v: Unsafe() = %v, want %v", ps[i], got, want)
+			}
+		}
+	}
+}
+
+func TestParseClockInteractionEmpty(t *testing.T) {
+	if _, err := ParseClockInteraction(strings.NewReader("Clock Interaction Report\n\nThere are no clocks in the design.\n")); err != nil {
+		t.Errorf("ParseClockInteraction() error = %v", err)
+	}
+	if _, err := ParseClockInteraction(strings.NewReader("Clock Interaction Report\n\nclk_adc -> clk_sys\n")); err == nil {
+		t.Error("ParseClockInteraction() error = nil, want one for a report without a table")
+	}
+}
@@ -0,0 +1,24 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// ParseFile parses the report file fn with parse, e.g.
+// `report.ParseFile(fn, report.ParseCDC)`. The parse errors have the name
+// of the file.
+func ParseFile[T any](fn string, parse func(io.Reader) (T, error)) (T, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer f.Close()
+	v, err := parse(f)
+	if err != nil {
+		return v, fmt.Errorf("parse %v: %w", fn, err)
+	}
+	return v, nil
+}
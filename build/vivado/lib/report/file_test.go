@@ -0,0 +1,32 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFile(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "top.cdc.rpt")
+	if err := os.WriteFile(fn, []byte(cdcReport), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	vs, err := ParseFile(fn, ParseCDC)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if len(vs) != 2 {
+		t.Errorf("ParseFile() = %+v, want 2 crossings", vs)
+	}
+
+	if err := os.WriteFile(fn, []byte("CDC Report\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseFile(fn, ParseCDC); err == nil || !strings.Contains(err.Error(), "parse "+fn+":") {
+		t.Errorf("ParseFile() error = %v, want a parse error naming %v", err, fn)
+	}
+	if _, err := ParseFile(filepath.Join(t.TempDir(), "missing.rpt"), ParseCDC); err == nil {
+		t.Error("ParseFile() of a missing file succeeded, want error")
+	}
+}
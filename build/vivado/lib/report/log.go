@@ -0,0 +1,142 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CommandTime is the run time and memory of a Vivado command, from its
+// "Time (s)" line in the log.
+type CommandTime struct {
+	Command        string  `json:"command"`
+	CPUSeconds     float64 `json:"cpu_s"`
+	ElapsedSeconds float64 `json:"elapsed_s"`
+	PeakMemoryMB   float64 `json:"peak_memory_mb"`
+	GainMemoryMB   float64 `json:"gain_memory_mb"`
+}
+
+var commandTime = regexp.MustCompile(`^(\S+): Time \(s\): cpu = ([\d:]+) ; elapsed = ([\d:]+) \. Memory \(MB\): peak = ([\d.]+)(?: ; gain = (-?[\d.]+))?`)
+
+// ParseLogTimes reads the times of the commands in a Vivado log, such as
+//
+//	route_design: Time (s): cpu = 00:01:02 ; elapsed = 00:00:41 . Memory (MB): peak = 2843.148 ; gain = 112.023
+//
+// in the order that they ran.
+func ParseLogTimes(r io.Reader) ([]CommandTime, error) {
+	var ts []CommandTime
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for s.Scan() {
+		m := commandTime.FindStringSubmatch(strings.TrimSpace(s.Text()))
+		if m == nil {
+			continue
+		}
+		t := CommandTime{Command: m[1]}
+		t.CPUSeconds = parseDuration(m[2])
+		t.ElapsedSeconds = parseDuration(m[3])
+		t.PeakMemoryMB, _ = strconv.ParseFloat(m[4], 64)
+		t.GainMemoryMB, _ = strconv.ParseFloat(m[5], 64)
+		ts = append(ts, t)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read log: %w", err)
+	}
+	return ts, nil
+}
+
+// parseDuration parses a duration such as "01:02:03" to seconds.
+func parseDuration(v string) float64 {
+	var secs float64
+	for _, f := range strings.Split(v, ":") {
+		n, _ := strconv.Atoi(f)
+		secs = secs*60 + float64(n)
+	}
+	return secs
+}
+
+// FormatSeconds formats secs as h:mm:ss, the inverse of the durations of the
+// log.
+func FormatSeconds(secs float64) string {
+	s := int(secs)
+	return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+}
+
+// LogMessage is a message of a Vivado log, such as
+//
+//	WARNING: [Synth 8-7129] Port clk in module foo is either unconnected or has no load
+type LogMessage struct {
+	// Severity is "INFO", "WARNING", "CRITICAL WARNING" or "ERROR".
+	Severity string `json:"severity"`
+	// ID is the message ID, e.g. "Synth 8-7129".
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+var logMessage = regexp.MustCompile(`^(INFO|WARNING|CRITICAL WARNING|ERROR): \[([^\]]+)\] (.*)$`)
+
+// ParseLogMessages reads the messages of a Vivado log, in the order that
+// Vivado wrote them.
+func ParseLogMessages(r io.Reader) ([]LogMessage, error) {
+	var ms []LogMessage
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for s.Scan() {
+		m := logMessage.FindStringSubmatch(strings.TrimSpace(s.Text()))
+		if m == nil {
+			continue
+		}
+		ms = append(ms, LogMessage{Severity: m[1], ID: m[2], Text: m[3]})
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read log: %w", err)
+	}
+	return ms, nil
+}
+
+// MessageCount counts the messages of a message ID.
+type MessageCount struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"`
+	Count    int    `json:"count"`
+	// Example is the text of the first message.
+	Example string `json:"example"`
+}
+
+// severityRank orders the log message severities, most severe first.
+var severityRank = map[string]int{
+	"ERROR":            0,
+	"CRITICAL WARNING": 1,
+	"WARNING":          2,
+	"INFO":             3,
+}
+
+// CountMessages counts ms by message ID, the most severe and then the most
+// frequent first.
+func CountMessages(ms []LogMessage) []MessageCount {
+	var cs []MessageCount
+	index := map[string]int{}
+	for _, m := range ms {
+		i, ok := index[m.ID]
+		if !ok {
+			i = len(cs)
+			index[m.ID] = i
+			cs = append(cs, MessageCount{ID: m.ID, Severity: m.Severity, Example: m.Text})
+		}
+		cs[i].Count++
+	}
+	sort.SliceStable(cs, func(i, j int) bool {
+		if ri, rj := severityRank[cs[i].Severity], severityRank[cs[j].Severity]; ri != rj {
+			return ri < rj
+		}
+		if cs[i].Count != cs[j].Count {
+			return cs[i].Count > cs[j].Count
+		}
+		return cs[i].ID < cs[j].ID
+	})
+	return cs
+}
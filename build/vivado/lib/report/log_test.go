@@ -0,0 +1,78 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const vivadoLog = `Command: route_design
+Phase 1 Build RT Design
+Time (s): cpu = 00:00:20 ; elapsed = 00:00:15 . Memory (MB): peak = 2600.000 ; gain = 0.000
+route_design: Time (s): cpu = 00:01:02 ; elapsed = 00:00:41 . Memory (MB): peak = 2843.148 ; gain = 112.023 ; free physical = 1024 ; free virtual = 4096
+write_checkpoint: Time (s): cpu = 01:00:00 ; elapsed = 00:00:05 . Memory (MB): peak = 2843.148 ; gain = -1.500
+link_design: Time (s): cpu = 00:00:03 ; elapsed = 00:00:04 . Memory (MB): peak = 1500.000
+`
+
+func TestParseLogTimes(t *testing.T) {
+	ts, err := ParseLogTimes(strings.NewReader(vivadoLog))
+	if err != nil {
+		t.Fatalf("ParseLogTimes() error = %v", err)
+	}
+	want := []CommandTime{
+		{Command: "route_design", CPUSeconds: 62, ElapsedSeconds: 41, PeakMemoryMB: 2843.148, GainMemoryMB: 112.023},
+		{Command: "write_checkpoint", CPUSeconds: 3600, ElapsedSeconds: 5, PeakMemoryMB: 2843.148, GainMemoryMB: -1.5},
+		{Command: "link_design", CPUSeconds: 3, ElapsedSeconds: 4, PeakMemoryMB: 1500},
+	}
+	if !reflect.DeepEqual(ts, want) {
+		t.Errorf("ParseLogTimes() = %+v, want %+v", ts, want)
+	}
+}
+
+const vivadoMessages = `INFO: [Common 17-206] Exiting Vivado
+WARNING: [Synth 8-7129] Port a in module foo is either unconnected or has no load
+CRITICAL WARNING: [Constraints 18-1056] Clock 'clk' completely overrides clock 'clk_in'.
+WARNING: [Synth 8-7129] Port b in module foo is either unconnected or has no load
+WARNING: [Synth 8-3331] design bar has unconnected port c
+WARNING: this line has no message ID
+`
+
+func TestParseLogMessages(t *testing.T) {
+	ms, err := ParseLogMessages(strings.NewReader(vivadoMessages))
+	if err != nil {
+		t.Fatalf("ParseLogMessages() error = %v", err)
+	}
+	if len(ms) != 5 {
+		t.Fatalf("ParseLogMessages() = %+v, want 5 messages", ms)
+	}
+	if want := (LogMessage{Severity: "CRITICAL WARNING", ID: "Constraints 18-1056", Text: "Clock 'clk' completely overrides clock 'clk_in'."}); ms[2] != want {
+		t.Errorf("ParseLogMessages()[2] = %+v, want %+v", ms[2], want)
+	}
+
+	got := CountMessages(ms)
+	want := []MessageCount{
+		{ID: "Constraints 18-1056", Severity: "CRITICAL WARNING", Count: 1, Example: "Clock 'clk' completely overrides clock 'clk_in'."},
+		{ID: "Synth 8-7129", Severity: "WARNING", Count: 2, Example: "Port a in module foo is either unconnected or has no load"},
+		{ID: "Synth 8-3331", Severity: "WARNING", Count: 1, Example: "design bar has unconnected port c"},
+		{ID: "Common 17-206", Severity: "INFO", Count: 1, Example: "Exiting Vivado"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CountMessages() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFormatSeconds(t *testing.T) {
+	for _, tt := range []struct {
+		secs float64
+		want string
+	}{
+		{secs: 0, want: "0:00:00"},
+		{secs: 59.9, want: "0:00:59"},
+		{secs: 3723, want: "1:02:03"},
+		{secs: parseDuration("27:00:01"), want: "27:00:01"},
+	} {
+		if got := FormatSeconds(tt.secs); got != tt.want {
+			t.Errorf("FormatSeconds(%v) = %q, want %q", tt.secs, got, tt.want)
+		}
+	}
+}
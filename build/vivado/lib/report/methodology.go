@@ -0,0 +1,154 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	checkTimingSection = regexp.MustCompile(`^\d+\. checking (\w+)(?: \((\d+)\))?$`)
+	checkTimingIssue   = regexp.MustCompile(`^There (?:are|is) (\d+) (.*?)(?:\s*\((HIGH|MEDIUM|LOW)\))?$`)
+	methodologyIssue   = regexp.MustCompile(`^([A-Z][A-Z0-9_]*-\d+)#\d+\s+(.+)$`)
+)
+
+// checkTimingSeverity maps the check_timing issue levels to severities.
+var checkTimingSeverity = map[string]string{
+	"HIGH":   "Critical",
+	"MEDIUM": "Warning",
+	"LOW":    "Info",
+}
+
+// ParseCheckTiming parses the text output of `check_timing -verbose`. The ID
+// of each violation is its check, e.g. "no_clock". The objects that the
+// verbose report lists, such as the ports without an input delay, each are
+// a violation with the object as its endpoint.
+func ParseCheckTiming(r io.Reader) ([]Violation, error) {
+	var (
+		vs      []Violation
+		check   string
+		prev    string
+		cur     *Violation
+		objects int
+	)
+	flush := func() {
+		if cur != nil && objects == 0 {
+			vs = append(vs, *cur)
+		}
+		cur, objects = nil, 0
+	}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case underline.MatchString(line):
+			// The table of contents lists the same titles, without the
+			// underline.
+			if m := checkTimingSection.FindStringSubmatch(prev); m != nil {
+				flush()
+				check = m[1]
+			}
+		case checkTimingSection.MatchString(line), line == "":
+		case check == "":
+		case checkTimingIssue.MatchString(line):
+			flush()
+			m := checkTimingIssue.FindStringSubmatch(line)
+			if n, _ := strconv.Atoi(m[1]); n == 0 {
+				break
+			}
+			severity := checkTimingSeverity[m[3]]
+			if severity == "" {
+				severity = "Info"
+			}
+			cur = &Violation{ID: check, Severity: severity, Description: line}
+		case cur != nil:
+			v := *cur
+			v.Endpoint = line
+			vs = append(vs, v)
+			objects++
+		}
+		prev = line
+	}
+	flush()
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read report: %w", err)
+	}
+	return vs, nil
+}
+
+// ParseMethodology parses the text output of `report_methodology`. The
+// description of each violation is the title of its rule and the message
+// that names its objects, e.g. "Non-clocked sequential cell: The clock pin
+// u_cnt/q_reg[0]/C is not reached by a timing clock".
+func ParseMethodology(r io.Reader) ([]Violation, error) {
+	var (
+		vs      []Violation
+		cur     *Violation
+		message []string
+	)
+	flush := func() {
+		if cur != nil {
+			if len(message) > 0 {
+				cur.Description = message[0]
+			}
+			if len(message) > 1 {
+				cur.Description += ": " + strings.Join(message[1:], " ")
+			}
+			vs = append(vs, *cur)
+		}
+		cur, message = nil, nil
+	}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case methodologyIssue.MatchString(line):
+			flush()
+			m := methodologyIssue.FindStringSubmatch(line)
+			cur = &Violation{ID: m[1], Severity: m[2]}
+		case cur == nil || line == "":
+		case strings.HasPrefix(line, "Related violations:"):
+			flush()
+		default:
+			message = append(message, line)
+		}
+	}
+	flush()
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read report: %w", err)
+	}
+	return vs, nil
+}
+
+// ParseDRC parses the text output of `report_drc`, which lists its
+// violations the same way as `report_methodology`.
+func ParseDRC(r io.Reader) ([]Violation, error) {
+	return ParseMethodology(r)
+}
+
+// CheckSummary counts the violations of a check.
+type CheckSummary struct {
+	Severity string `json:"severity"`
+	Count    int    `json:"count"`
+	Waived   int    `json:"waived"`
+}
+
+// Summarize counts vs by check ID.
+func Summarize(vs []Violation) map[string]CheckSummary {
+	m := map[string]CheckSummary{}
+	for _, v := range vs {
+		c := m[v.ID]
+		c.Severity = v.Severity
+		c.Count++
+		if v.Waived() {
+			c.Waived++
+		}
+		m[v.ID] = c
+	}
+	return m
+}
@@ -0,0 +1,154 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const checkTimingReport = `check_timing report
+
+Table of Contents
+-----------------
+1. checking no_clock (2)
+2. checking constant_clock (0)
+3. checking unconstrained_internal_endpoints (1)
+4. checking no_input_delay (0)
+
+1. checking no_clock (2)
+------------------------
+ There are 2 register/latch pins with no clock driven by root clock pin: u_cnt/q_reg[0]/C (HIGH)
+
+u_cnt/q_reg[0]/C
+u_cnt/q_reg[1]/C
+
+
+2. checking constant_clock (0)
+------------------------------
+ There are 0 register/latch pins with constant_clock.
+
+
+3. checking unconstrained_internal_endpoints (1)
+------------------------------------------------
+ There are 1 pins that are not constrained for maximum delay. (HIGH)
+
+ There are 0 pins that are not constrained for maximum delay due to constant clock. (MEDIUM)
+
+
+4. checking no_input_delay (0)
+------------------------------
+ There are 0 input ports with no input delay specified. (HIGH)
+`
+
+const methodologyReport = `Report Methodology
+
+Table of Contents
+-----------------
+1. REPORT SUMMARY
+2. REPORT DETAILS
+
+1. REPORT SUMMARY
+-----------------
+            Netlist: netlist
+          Floorplan: design_1
+    Violations found: 2
++-----------+------------------+-------------------------------+------------+
+| Rule      | Severity         | Description                   | Violations |
++-----------+------------------+-------------------------------+------------+
+| TIMING-17 | Critical Warning | Non-clocked sequential cell   | 1          |
+| TIMING-18 | Warning          | Missing input or output delay | 1          |
++-----------+------------------+-------------------------------+------------+
+
+2. REPORT DETAILS
+-----------------
+TIMING-17#1 Critical Warning
+Non-clocked sequential cell  
+The clock pin u_cnt/q_reg[0]/C is not reached by a timing clock
+Related violations: <none>
+
+TIMING-18#1 Warning
+Missing input or output delay  
+An output delay is missing on led[0] relative to the rising and/or
+falling clock edge(s) of clk.
+Related violations: <none>
+`
+
+const drcReport = `Report DRC
+
+2. REPORT DETAILS
+-----------------
+NSTD-1#1 Critical Warning
+Unspecified I/O Standard
+1 out of 9 logical ports use I/O standard (IOSTANDARD) value 'DEFAULT', instead of a user assigned specific value. Problem ports: led[0].
+Related violations: <none>
+
+`
+
+func TestParseCheckTiming(t *testing.T) {
+	vs, err := ParseCheckTiming(strings.NewReader(checkTimingReport))
+	if err != nil {
+		t.Fatalf("ParseCheckTiming() error = %v", err)
+	}
+	const noClock = "There are 2 register/latch pins with no clock driven by root clock pin: u_cnt/q_reg[0]/C (HIGH)"
+	want := []Violation{
+		{ID: "no_clock", Severity: "Critical", Description: noClock, Endpoint: "u_cnt/q_reg[0]/C"},
+		{ID: "no_clock", Severity: "Critical", Description: noClock, Endpoint: "u_cnt/q_reg[1]/C"},
+		{
+			ID:          "unconstrained_internal_endpoints",
+			Severity:    "Critical",
+			Description: "There are 1 pins that are not constrained for maximum delay. (HIGH)",
+		},
+	}
+	if !reflect.DeepEqual(vs, want) {
+		t.Errorf("ParseCheckTiming() = %+v, want %+v", vs, want)
+	}
+}
+
+func TestParseMethodology(t *testing.T) {
+	vs, err := ParseMethodology(strings.NewReader(methodologyReport))
+	if err != nil {
+		t.Fatalf("ParseMethodology() error = %v", err)
+	}
+	want := []Violation{
+		{
+			ID:          "TIMING-17",
+			Severity:    "Critical Warning",
+			Description: "Non-clocked sequential cell: The clock pin u_cnt/q_reg[0]/C is not reached by a timing clock",
+		},
+		{
+			ID:          "TIMING-18",
+			Severity:    "Warning",
+			Description: "Missing input or output delay: An output delay is missing on led[0] relative to the rising and/or falling clock edge(s) of clk.",
+		},
+	}
+	if !reflect.DeepEqual(vs, want) {
+		t.Errorf("ParseMethodology() = %+v, want %+v", vs, want)
+	}
+	if !vs[0].Critical() || vs[1].Critical() {
+		t.Errorf("Critical() = %v, %v, want true, false", vs[0].Critical(), vs[1].Critical())
+	}
+
+	got := Summarize(vs)
+	wantSummary := map[string]CheckSummary{
+		"TIMING-17": {Severity: "Critical Warning", Count: 1},
+		"TIMING-18": {Severity: "Warning", Count: 1},
+	}
+	if !reflect.DeepEqual(got, wantSummary) {
+		t.Errorf("Summarize() = %+v, want %+v", got, wantSummary)
+	}
+}
+
+func TestParseDRC(t *testing.T) {
+	vs, err := ParseDRC(strings.NewReader(drcReport))
+	if err != nil {
+		t.Fatalf("ParseDRC() error = %v", err)
+	}
+	want := []Violation{{
+		ID:          "NSTD-1",
+		Severity:    "Critical Warning",
+		Description: "Unspecified I/O Standard: 1 out of 9 logical ports use I/O standard (IOSTANDARD) value 'DEFAULT', instead of a user assigned specific value. Problem ports: led[0].",
+	}}
+	if !reflect.DeepEqual(vs, want) {
+		t.Errorf("ParseDRC() = %+v, want %+v", vs, want)
+	}
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Power is the result of `report_power`.
+type Power struct {
+	// TotalOnChipW is the total on-chip power in W.
+	TotalOnChipW float64 `json:"total_on_chip_w"`
+	// DynamicW is the dynamic power in W.
+	DynamicW float64 `json:"dynamic_w"`
+	// StaticW is the device static power in W.
+	StaticW float64 `json:"static_w"`
+	// JunctionTempC is the junction temperature in °C.
+	JunctionTempC float64 `json:"junction_temperature_c"`
+	// MaxAmbientC is the highest ambient temperature in °C that keeps the
+	// junction temperature within limits, if reported.
+	MaxAmbientC float64 `json:"max_ambient_c,omitempty"`
+	// ConfidenceLevel tells how well the switching activity is known, e.g.
+	// "Low" without simulation activity.
+	ConfidenceLevel string `json:"confidence_level,omitempty"`
+	// Rails are the power supply rails.
+	Rails []Rail `json:"rails"`
+}
+
+// Rail is the power drawn from a power supply rail, e.g. "Vccint".
+type Rail struct {
+	Name     string  `json:"name"`
+	VoltageV float64 `json:"voltage_v"`
+	TotalA   float64 `json:"total_a"`
+	DynamicA float64 `json:"dynamic_a"`
+	StaticA  float64 `json:"static_a"`
+}
+
+// ParsePower parses the text output of `report_power`.
+func ParsePower(r io.Reader) (*Power, error) {
+	tables, err := ParseTables(r)
+	if err != nil {
+		return nil, err
+	}
+	var p Power
+	var haveTotal, haveJunction bool
+	for _, t := range tables {
+		switch {
+		case t.Header == nil && strings.HasSuffix(t.Section, "Summary"):
+			for _, row := range t.Rows {
+				k, v := Cell(row, 0), Cell(row, 1)
+				var dst *float64
+				switch k {
+				case "Total On-Chip Power (W)":
+					dst, haveTotal = &p.TotalOnChipW, true
+				case "Dynamic (W)":
+					dst = &p.DynamicW
+				case "Device Static (W)":
+					dst = &p.StaticW
+				case "Junction Temperature (C)":
+					dst, haveJunction = &p.JunctionTempC, true
+				case "Max Ambient (C)":
+					dst = &p.MaxAmbientC
+				case "Confidence Level":
+					p.ConfidenceLevel = v
+				}
+				if dst == nil {
+					continue
+				}
+				n, err := ParseNumber(v)
+				if err != nil {
+					return nil, fmt.Errorf("%v: %w", k, err)
+				}
+				*dst = n
+			}
+		case t.Column("Source") == 0 && t.Column("Voltage (V)") > 0:
+			for _, row := range t.Rows {
+				rail := Rail{Name: Cell(row, 0)}
+				for col, dst := range map[string]*float64{
+					"Voltage (V)": &rail.VoltageV,
+					"Total (A)":   &rail.TotalA,
+					"Dynamic (A)": &rail.DynamicA,
+					"Static (A)":  &rail.StaticA,
+				} {
+					// Unused rails report "NA" or "<0.001", which count as
+					// no current.
+					*dst, _ = ParseNumber(Cell(row, t.Column(col)))
+				}
+				if rail.Name != "" {
+					p.Rails = append(p.Rails, rail)
+				}
+			}
+		}
+	}
+	if !haveTotal {
+		return nil, fmt.Errorf("no total on-chip power, not a report_power report?")
+	}
+	if !haveJunction {
+		return nil, fmt.Errorf("no junction temperature in the power report")
+	}
+	return &p, nil
+}
+
+// Check returns the ways in which p exceeds the given budget. A zero limit
+// is not checked.
+func (p Power) Check(maxTotalW, maxJunctionTempC float64) []string {
+	var vs []string
+	if maxTotalW > 0 && p.TotalOnChipW > maxTotalW {
+		vs = append(vs, fmt.Sprintf("total on-chip power %.3f W exceeds the budget of %.3f W",
+			p.TotalOnChipW, maxTotalW))
+	}
+	if maxJunctionTempC > 0 && p.JunctionTempC > maxJunctionTempC {
+		vs = append(vs, fmt.Sprintf("junction temperature %.1f C exceeds the limit of %.1f C",
+			p.JunctionTempC, maxJunctionTempC))
+	}
+	return vs
+}
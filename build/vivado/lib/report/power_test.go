@@ -0,0 +1,126 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const powerReport = `Copyright 1986-2022 Xilinx, Inc. All Rights Reserved.
+---------------------------------------------------------------------------------------------------
+| Tool Version     : Vivado v.2025.2 (lin64) Build 0000000 Thu Nov 20 10:42:17 MST 2025
+| Design           : top
+| Device           : xc7a200tfbg484-1
+| Design State     : routed
+| Grade            : commercial
+| Process          : typical
+| Characterization : Production
+---------------------------------------------------------------------------------------------------
+
+Power Report
+
+Table of Contents
+-----------------
+1. Summary
+1.1 On-Chip Components
+1.2 Power Supply Summary
+
+1. Summary
+----------
+
++--------------------------+--------------+
+| Total On-Chip Power (W)  | 0.412        |
+| Design Power Budget (W)  | Unspecified* |
+| Power Budget Margin (W)  | NA           |
+| Dynamic (W)              | 0.276        |
+| Device Static (W)        | 0.136        |
+| Effective TJA (C/W)      | 2.5          |
+| Max Ambient (C)          | 84.0         |
+| Junction Temperature (C) | 26.0         |
+| Confidence Level         | Low          |
+| Setting File             | ---          |
+| Simulation Activity File | ---          |
+| Design Nets Matched      | NA           |
++--------------------------+--------------+
+
+
+1.1 On-Chip Components
+----------------------
+
++----------------+-----------+----------+-----------+-----------------+
+| On-Chip        | Power (W) | Used     | Available | Utilization (%) |
++----------------+-----------+----------+-----------+-----------------+
+| Clocks         |     0.011 |        5 |       --- |             --- |
+| Slice Logic    |     0.004 |     2817 |       --- |             --- |
+| Static Power   |     0.136 |          |           |                 |
+| Total          |     0.412 |          |           |                 |
++----------------+-----------+----------+-----------+-----------------+
+
+
+1.2 Power Supply Summary
+------------------------
+
++-----------+-------------+-----------+-------------+------------+-------------+-------------+------------+
+| Source    | Voltage (V) | Total (A) | Dynamic (A) | Static (A) | Powerup (A) | Budget (A)  | Margin (A) |
++-----------+-------------+-----------+-------------+------------+-------------+-------------+------------+
+| Vccint    |       1.000 |     0.084 |       0.065 |      0.019 |       NA    | Unspecified | NA         |
+| Vccaux    |       1.800 |     0.019 |       0.006 |      0.013 |       NA    | Unspecified | NA         |
+| Vcco33    |       3.300 |     0.000 |       0.000 |      0.000 |       NA    | Unspecified | NA         |
+| MGTAVcc   |       1.000 |     0.000 |       0.000 |      0.000 |       NA    | Unspecified | NA         |
++-----------+-------------+-----------+-------------+------------+-------------+-------------+------------+
+`
+
+func TestParsePower(t *testing.T) {
+	p, err := ParsePower(strings.NewReader(powerReport))
+	if err != nil {
+		t.Fatalf("ParsePower() error = %v", err)
+	}
+	want := &Power{
+		TotalOnChipW:    0.412,
+		DynamicW:        0.276,
+		StaticW:         0.136,
+		JunctionTempC:   26.0,
+		MaxAmbientC:     84.0,
+		ConfidenceLevel: "Low",
+		Rails: []Rail{
+			{Name: "Vccint", VoltageV: 1.0, TotalA: 0.084, DynamicA: 0.065, StaticA: 0.019},
+			{Name: "Vccaux", VoltageV: 1.8, TotalA: 0.019, DynamicA: 0.006, StaticA: 0.013},
+			{Name: "Vcco33", VoltageV: 3.3},
+			{Name: "MGTAVcc", VoltageV: 1.0},
+		},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("ParsePower() = %+v, want %+v", p, want)
+	}
+}
+
+func TestParsePowerNotAPowerReport(t *testing.T) {
+	const timing = `1. Summary
+----------
+
++-----+-----+
+| WNS | 0.1 |
++-----+-----+
+`
+	if _, err := ParsePower(strings.NewReader(timing)); err == nil {
+		t.Errorf("ParsePower() succeeded, want error")
+	}
+}
+
+func TestPowerCheck(t *testing.T) {
+	p := Power{TotalOnChipW: 0.412, JunctionTempC: 26.0}
+	for _, tc := range []struct {
+		name              string
+		maxW, maxJunction float64
+		want              int
+	}{
+		{"unchecked", 0, 0, 0},
+		{"within", 0.5, 85, 0},
+		{"power", 0.4, 85, 1},
+		{"both", 0.4, 25, 2},
+	} {
+		if got := p.Check(tc.maxW, tc.maxJunction); len(got) != tc.want {
+			t.Errorf("%v: Check() = %q, want %d violations", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Package report parses the text reports that Vivado writes, such as those
+// of `report_power`.
+//
+// Vivado reports are made of numbered sections, each with a title underlined
+// with dashes, that hold tables drawn with ASCII borders:
+//
+//  1. Summary
+//     ----------
+//
+//     +--------------------------+--------------+
+//     | Total On-Chip Power (W)  | 0.123        |
+//     | Junction Temperature (C) | 25.6         |
+//     +--------------------------+--------------+
+//
+// A table whose first rows are closed off by a border of their own has those
+// rows as a header. Lines such as "From Clock: clk_a" above a table label it.
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Table is a table of a Vivado text report.
+type Table struct {
+	// Section is the title of the section that the table is in, e.g.
+	// "1.2 Power Supply Summary".
+	Section string
+	// Labels are the "key: value" lines above the table in its section,
+	// e.g. "From Clock" for "From Clock: clk_a".
+	Labels map[string]string
+	// Header is the header row, if the table has one.
+	Header []string
+	// Rows are the data rows.
+	Rows [][]string
+	// Indents are the number of spaces that the first cell of each row is
+	// indented by, which is the depth of the row in hierarchical tables.
+	Indents []int
+}
+
+// Column returns the index of the first header column named any of names,
+// or -1 if there is none. Vivado versions name some columns differently.
+func (t Table) Column(names ...string) int {
+	for _, name := range names {
+		for i, h := range t.Header {
+			if h == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Cell returns the cell of row in column col, or "" if the row is short.
+func Cell(row []string, col int) string {
+	if col < 0 || col >= len(row) {
+		return ""
+	}
+	return row[col]
+}
+
+var (
+	underline = regexp.MustCompile(`^-{3,}$`)
+	label     = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9 ()/_-]*?)\s*:\s+(\S.*)$`)
+)
+
+// ParseTables reads all tables of a Vivado text report.
+func ParseTables(r io.Reader) ([]Table, error) {
+	var (
+		tables  []Table
+		section string
+		labels  map[string]string
+		prev    string
+		cur     *Table
+		// The rows of the current table, and the number of rows seen
+		// before each of its borders.
+		rows    [][]string
+		indents []int
+		borders []int
+	)
+	finish := func() {
+		if cur == nil {
+			return
+		}
+		// With a border below the first rows and another below the last,
+		// the first rows are the header.
+		if len(borders) >= 3 && borders[1] > 0 && borders[1] < len(rows) {
+			cur.Header = joinRows(rows[:borders[1]])
+			rows, indents = rows[borders[1]:], indents[borders[1]:]
+		}
+		cur.Rows, cur.Indents = rows, indents
+		tables = append(tables, *cur)
+		cur, rows, indents, borders = nil, nil, nil, nil
+	}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case strings.HasPrefix(line, "+-") || strings.HasPrefix(line, "+="):
+			if cur == nil {
+				cur = &Table{Section: section, Labels: labels}
+			}
+			borders = append(borders, len(rows))
+		case strings.HasPrefix(line, "|"):
+			if cur == nil {
+				cur = &Table{Section: section, Labels: labels}
+			}
+			rows = append(rows, splitRow(line))
+			// One space pads the cell from the border.
+			first := strings.TrimPrefix(line, "|")
+			indents = append(indents, len(first)-len(strings.TrimLeft(first, " "))-1)
+		default:
+			finish()
+			if underline.MatchString(line) && prev != "" {
+				section, labels = prev, nil
+			} else if m := label.FindStringSubmatch(line); m != nil {
+				// Copy, so that the labels of earlier tables stay as they
+				// were.
+				l := map[string]string{m[1]: m[2]}
+				for k, v := range labels {
+					if k != m[1] {
+						l[k] = v
+					}
+				}
+				labels = l
+			}
+		}
+		prev = line
+	}
+	finish()
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read report: %w", err)
+	}
+	return tables, nil
+}
+
+var dashRuns = regexp.MustCompile(`^\s*-+(\s+-+)+\s*$`)
+
+// ParseColumnTables reads the tables of a Vivado text report that are laid
+// out in columns under a line of dashes, such as those of
+// `report_timing_summary` and `report_clock_interaction`:
+//
+//	| Clock Summary
+//	| -------------
+//
+//	Clock  Waveform(ns)       Period(ns)      Frequency(MHz)
+//	-----  ------------       ----------      --------------
+//	clk    {0.000 2.500}      5.000           200.000
+//
+// A column spans from the start of its dashes to the start of the next. A
+// header may span several lines, which are joined cell by cell. The sections
+// of such reports are titled in a box, as above, or underlined with dashes
+// and followed by an empty line. Lines such as "Source Clock: clk_a" above a
+// table label it.
+func ParseColumnTables(r io.Reader) ([]Table, error) {
+	var lines []string
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for s.Scan() {
+		lines = append(lines, strings.TrimRight(s.Text(), " \t"))
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read report: %w", err)
+	}
+	blank := func(i int) bool {
+		return i >= len(lines) || strings.TrimSpace(lines[i]) == ""
+	}
+
+	var tables []Table
+	var section string
+	var labels map[string]string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "|") && i+1 < len(lines) &&
+			underline.MatchString(strings.TrimSpace(strings.TrimPrefix(lines[i+1], "|"))) {
+			section, labels = strings.TrimSpace(strings.TrimPrefix(line, "|")), nil
+			i++
+			continue
+		}
+		if !blank(i) && i+1 < len(lines) && underline.MatchString(strings.TrimSpace(lines[i+1])) && blank(i+2) {
+			section, labels = strings.TrimSpace(line), nil
+			i++
+			continue
+		}
+		if m := label.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			l := map[string]string{m[1]: m[2]}
+			for k, v := range labels {
+				if k != m[1] {
+					l[k] = v
+				}
+			}
+			labels = l
+			continue
+		}
+		if !dashRuns.MatchString(line) || i == 0 || blank(i-1) {
+			continue
+		}
+		var starts []int
+		for j := range line {
+			if line[j] == '-' && (j == 0 || line[j-1] == ' ') {
+				starts = append(starts, j)
+			}
+		}
+		// The header is the lines above the dashes, up to an empty line or
+		// a label.
+		first := i - 1
+		for first > 0 && !blank(first-1) && !strings.HasPrefix(lines[first-1], "|") &&
+			label.FindStringSubmatch(strings.TrimSpace(lines[first-1])) == nil {
+			first--
+		}
+		var header [][]string
+		for _, h := range lines[first:i] {
+			header = append(header, splitColumns(h, starts))
+		}
+		t := Table{Section: section, Labels: labels, Header: joinRows(header)}
+		for i++; !blank(i); i++ {
+			t.Rows = append(t.Rows, splitColumns(lines[i], starts))
+			t.Indents = append(t.Indents, len(lines[i])-len(strings.TrimLeft(lines[i], " ")))
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+// splitColumns splits line into the trimmed columns that start at starts.
+func splitColumns(line string, starts []int) []string {
+	cells := make([]string, len(starts))
+	for i, start := range starts {
+		end := len(line)
+		if i+1 < len(starts) && starts[i+1] < end {
+			end = starts[i+1]
+		}
+		if start < end {
+			cells[i] = strings.TrimSpace(line[start:end])
+		}
+	}
+	return cells
+}
+
+// joinRows joins a header that spans several rows into one, cell by cell.
+func joinRows(rows [][]string) []string {
+	var h []string
+	for _, row := range rows {
+		for i, c := range row {
+			if i == len(h) {
+				h = append(h, "")
+			}
+			if c != "" && h[i] != "" {
+				h[i] += " "
+			}
+			h[i] += c
+		}
+	}
+	return h
+}
+
+// splitRow splits a table row such as "| a | b |" into its trimmed cells.
+func splitRow(line string) []string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(line, "|")
+	for i, c := range cells {
+		cells[i] = strings.TrimSpace(c)
+	}
+	return cells
+}
+
+// ParseNumber parses the number at the start of a report value, such as
+// "1.234" or "1.234 (Junction temp exceeded!)". Values such as "NA" or
+// "<0.001" are not numbers.
+func ParseNumber(v string) (float64, error) {
+	f := strings.Fields(v)
+	if len(f) == 0 {
+		return 0, fmt.Errorf("not a number: %q", v)
+	}
+	n, err := strconv.ParseFloat(f[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("not a number: %q", v)
+	}
+	return n, nil
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTables(t *testing.T) {
+	const report = `1. Clock Summary
+----------------
+
++-------+--------+---------+
+| Clock | Period | Frequency |
+|       | (ns)   | (MHz)     |
++-------+--------+---------+
+| clk   | 10.000 | 100.000 |
+| clk2x |  5.000 | 200.000 |
++-------+--------+---------+
+
+2. Notes
+--------
++--------+
+| no header |
++--------+
+`
+	tables, err := ParseTables(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("ParseTables() error = %v", err)
+	}
+	want := []Table{
+		{
+			Section: "1. Clock Summary",
+			Header:  []string{"Clock", "Period (ns)", "Frequency (MHz)"},
+			Rows:    [][]string{{"clk", "10.000", "100.000"}, {"clk2x", "5.000", "200.000"}},
+			Indents: []int{0, 0},
+		},
+		{
+			Section: "2. Notes",
+			Rows:    [][]string{{"no header"}},
+			Indents: []int{0},
+		},
+	}
+	if !reflect.DeepEqual(tables, want) {
+		t.Errorf("ParseTables() = %q, want %q", tables, want)
+	}
+	if got := tables[0].Column("Period (ns)"); got != 1 {
+		t.Errorf("Column() = %d, want 1", got)
+	}
+}
+
+func TestParseColumnTables(t *testing.T) {
+	const report = `------------------------------------------------------------------------------------------------
+| Clock Summary
+| -------------
+------------------------------------------------------------------------------------------------
+
+Clock               Waveform(ns)       Period(ns)      Frequency(MHz)
+-----               ------------       ----------      --------------
+clk                 {0.000 2.500}      5.000           200.000
+  clk_out1_mmcm     {0.000 1.250}      2.500           400.000
+
+
+------------------------------------------------------------------------------------------------
+| Design Timing Summary
+| ---------------------
+------------------------------------------------------------------------------------------------
+
+    WNS(ns)      TNS(ns)  TNS Failing Endpoints
+    -------      -------  ---------------------
+     -0.512       -1.234                      3
+`
+	tables, err := ParseColumnTables(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("ParseColumnTables() error = %v", err)
+	}
+	want := []Table{
+		{
+			Section: "Clock Summary",
+			Header:  []string{"Clock", "Waveform(ns)", "Period(ns)", "Frequency(MHz)"},
+			Rows: [][]string{
+				{"clk", "{0.000 2.500}", "5.000", "200.000"},
+				{"clk_out1_mmcm", "{0.000 1.250}", "2.500", "400.000"},
+			},
+			Indents: []int{0, 2},
+		},
+		{
+			Section: "Design Timing Summary",
+			Header:  []string{"WNS(ns)", "TNS(ns)", "TNS Failing Endpoints"},
+			Rows:    [][]string{{"-0.512", "-1.234", "3"}},
+			Indents: []int{5},
+		},
+	}
+	if !reflect.DeepEqual(tables, want) {
+		t.Errorf("ParseColumnTables() = %q, want %q", tables, want)
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	for _, tc := range []struct {
+		v    string
+		want float64
+		ok   bool
+	}{
+		{"1.234", 1.234, true},
+		{"101.5 (Junction temp exceeded!)", 101.5, true},
+		{"NA", 0, false},
+		{"<0.001", 0, false},
+		{"", 0, false},
+	} {
+		got, err := ParseNumber(tc.v)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("ParseNumber(%q) = %v, %v", tc.v, got, err)
+		}
+	}
+}
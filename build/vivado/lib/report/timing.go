@@ -0,0 +1,245 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TimingPath is a path of `report_timing`.
+type TimingPath struct {
+	// Type is "setup" for max delay paths, "hold" for min delay paths.
+	Type       string  `json:"type"`
+	SlackNs    float64 `json:"slack_ns"`
+	Startpoint string  `json:"startpoint"`
+	Endpoint   string  `json:"endpoint"`
+	// StartClock and EndClock are the clocks of the startpoint and the
+	// endpoint, empty for unclocked ports.
+	StartClock    string  `json:"start_clock,omitempty"`
+	EndClock      string  `json:"end_clock,omitempty"`
+	PathGroup     string  `json:"path_group,omitempty"`
+	RequirementNs float64 `json:"requirement_ns"`
+	// DataPathDelayNs is the delay of the data path, LogicDelayNs and
+	// RouteDelayNs its split into cell and net delays.
+	DataPathDelayNs float64 `json:"data_path_delay_ns"`
+	LogicDelayNs    float64 `json:"logic_delay_ns"`
+	RouteDelayNs    float64 `json:"route_delay_ns"`
+	LogicLevels     int     `json:"logic_levels"`
+	// Cells are the hierarchical names of the cells along the data path,
+	// from the startpoint to the endpoint.
+	Cells []string `json:"cells"`
+	// Module is the hierarchical instance that holds the endpoint, empty for
+	// the top level.
+	Module string `json:"module"`
+}
+
+// Failing returns true if the path does not meet timing.
+func (p TimingPath) Failing() bool {
+	return p.SlackNs < 0
+}
+
+var (
+	timingSlack     = regexp.MustCompile(`^Slack(?: \(\w+\))?\s*:\s*(\S+)`)
+	timingField     = regexp.MustCompile(`^([A-Z][A-Za-z ]*?):\s+(.*)$`)
+	timingClockedBy = regexp.MustCompile(`clocked by (\S+)`)
+	timingSplit     = regexp.MustCompile(`logic (-?[\d.]+)ns.*route (-?[\d.]+)ns`)
+	timingSeparator = regexp.MustCompile(`^-{20,}`)
+)
+
+// ParseTimingPaths parses the text output of `report_timing`, for setup or
+// hold, or both appended to one file.
+func ParseTimingPaths(r io.Reader) ([]TimingPath, error) {
+	var (
+		paths []TimingPath
+		cur   *TimingPath
+		// The last header field, which a line of details in parentheses
+		// such as the clock of the source belongs to.
+		field string
+		// The segment of the path table, separated by dashed lines: 1 is
+		// the source clock path, 2 the data path.
+		segment int
+	)
+	finish := func() {
+		if cur != nil {
+			cur.Module = parentCell(endpointCell(cur.Endpoint))
+			paths = append(paths, *cur)
+		}
+		cur, field, segment = nil, "", 0
+	}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if m := timingSlack.FindStringSubmatch(line); m != nil {
+			finish()
+			// Unconstrained paths have an infinite slack, and are skipped.
+			if slack, err := strconv.ParseFloat(strings.TrimSuffix(m[1], "ns"), 64); err == nil && !math.IsInf(slack, 0) {
+				cur = &TimingPath{SlackNs: slack, Cells: []string{}}
+			}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		if segment == 0 {
+			if strings.HasPrefix(line, "(") && field != "" {
+				if m := timingClockedBy.FindStringSubmatch(line); m != nil {
+					switch field {
+					case "Source":
+						cur.StartClock = m[1]
+					case "Destination":
+						cur.EndClock = m[1]
+					}
+				}
+				continue
+			}
+			if timingSeparator.MatchString(line) {
+				segment = 1
+				continue
+			}
+			m := timingField.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			field = m[1]
+			if err := cur.setField(m[1], m[2]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if timingSeparator.MatchString(line) {
+			segment++
+			continue
+		}
+		if segment == 2 {
+			cur.addDataPathLine(line)
+		}
+	}
+	finish()
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read report: %w", err)
+	}
+	return paths, nil
+}
+
+// setField sets a field of the path header, such as "Logic Levels: 6".
+func (p *TimingPath) setField(k, v string) error {
+	var err error
+	switch k {
+	case "Source":
+		p.Startpoint = v
+	case "Destination":
+		p.Endpoint = v
+	case "Path Group":
+		p.PathGroup = v
+	case "Path Type":
+		switch {
+		case strings.HasPrefix(v, "Setup"):
+			p.Type = "setup"
+		case strings.HasPrefix(v, "Hold"):
+			p.Type = "hold"
+		default:
+			p.Type = strings.ToLower(strings.Fields(v)[0])
+		}
+	case "Requirement":
+		p.RequirementNs, err = parseNs(v)
+	case "Data Path Delay":
+		p.DataPathDelayNs, err = parseNs(v)
+		if m := timingSplit.FindStringSubmatch(v); m != nil && err == nil {
+			p.LogicDelayNs, _ = strconv.ParseFloat(m[1], 64)
+			p.RouteDelayNs, _ = strconv.ParseFloat(m[2], 64)
+		}
+	case "Logic Levels":
+		p.LogicLevels, err = strconv.Atoi(strings.Fields(v)[0])
+	}
+	if err != nil {
+		return fmt.Errorf("%v: %w", k, err)
+	}
+	return nil
+}
+
+// addDataPathLine adds the cell of a data path line, such as
+// "SLICE_X1Y1  LUT3 (Prop_lut3_I0_O)  0.124  6.5 r  u_b/acc[7]_i_2/O".
+func (p *TimingPath) addDataPathLine(line string) {
+	f := strings.Fields(line)
+	// Nets are not cells, and ports such as "btn (IN)" have no pin.
+	if len(f) == 0 || strings.HasPrefix(line, "net ") || strings.HasSuffix(line, ")") {
+		return
+	}
+	pin := f[len(f)-1]
+	if !strings.Contains(pin, "/") {
+		return
+	}
+	cell := parentCell(pin)
+	if n := len(p.Cells); n == 0 || p.Cells[n-1] != cell {
+		p.Cells = append(p.Cells, cell)
+	}
+}
+
+// parseNs parses a delay such as "5.000ns  (clk rise@5.000ns ...)".
+func parseNs(v string) (float64, error) {
+	f := strings.Fields(v)
+	if len(f) == 0 {
+		return 0, fmt.Errorf("not a delay: %q", v)
+	}
+	return strconv.ParseFloat(strings.TrimSuffix(f[0], "ns"), 64)
+}
+
+// endpointCell returns the cell of a pin endpoint, or the port of a port
+// endpoint.
+func endpointCell(endpoint string) string {
+	if !strings.Contains(endpoint, "/") {
+		return ""
+	}
+	return parentCell(endpoint)
+}
+
+// parentCell returns the hierarchical parent of the name of a cell or pin,
+// e.g. "u_b/acc_reg[7]" for "u_b/acc_reg[7]/D", and "" at the top.
+func parentCell(name string) string {
+	i := strings.LastIndex(name, "/")
+	if i < 0 {
+		return ""
+	}
+	return name[:i]
+}
+
+// ModuleTiming sums up the timing paths that end in a module.
+type ModuleTiming struct {
+	// Module is the hierarchical instance, empty for the top level.
+	Module       string  `json:"module"`
+	Paths        int     `json:"paths"`
+	Failing      int     `json:"failing"`
+	WorstSlackNs float64 `json:"worst_slack_ns"`
+}
+
+// ByModule groups paths by the module of their endpoint, worst slack first.
+func ByModule(paths []TimingPath) []ModuleTiming {
+	ms := []ModuleTiming{}
+	index := map[string]int{}
+	for _, p := range paths {
+		i, ok := index[p.Module]
+		if !ok {
+			i = len(ms)
+			index[p.Module] = i
+			ms = append(ms, ModuleTiming{Module: p.Module, WorstSlackNs: p.SlackNs})
+		}
+		m := &ms[i]
+		m.Paths++
+		if p.Failing() {
+			m.Failing++
+		}
+		if p.SlackNs < m.WorstSlackNs {
+			m.WorstSlackNs = p.SlackNs
+		}
+	}
+	sort.SliceStable(ms, func(i, j int) bool {
+		return ms[i].WorstSlackNs < ms[j].WorstSlackNs
+	})
+	return ms
+}
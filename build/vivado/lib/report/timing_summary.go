@@ -0,0 +1,121 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// TimingSummary is the result of `report_timing_summary`. Worst slacks are
+// in ns, totals sum the negative slacks in ns.
+type TimingSummary struct {
+	WNSNs               float64 `json:"wns_ns"`
+	TNSNs               float64 `json:"tns_ns"`
+	TNSFailingEndpoints int     `json:"tns_failing_endpoints"`
+	TNSTotalEndpoints   int     `json:"tns_total_endpoints"`
+	WHSNs               float64 `json:"whs_ns"`
+	THSNs               float64 `json:"ths_ns"`
+	THSFailingEndpoints int     `json:"ths_failing_endpoints"`
+	THSTotalEndpoints   int     `json:"ths_total_endpoints"`
+	// WPWSNs and TPWSNs are the worst and total pulse width slack.
+	WPWSNs float64 `json:"wpws_ns"`
+	TPWSNs float64 `json:"tpws_ns"`
+	// Clocks are the clocks of the design, with the timing of the paths
+	// within each clock.
+	Clocks []ClockTiming `json:"clocks"`
+}
+
+// Met returns true if the design meets setup, hold and pulse width timing.
+func (s TimingSummary) Met() bool {
+	return s.WNSNs >= 0 && s.WHSNs >= 0 && s.WPWSNs >= 0
+}
+
+// ClockTiming is a clock, and the timing of the paths within it.
+type ClockTiming struct {
+	Name         string  `json:"name"`
+	Waveform     string  `json:"waveform"`
+	PeriodNs     float64 `json:"period_ns"`
+	FrequencyMHz float64 `json:"frequency_mhz"`
+	// Generated is set for clocks generated from another clock, such as
+	// MMCM outputs.
+	Generated           bool    `json:"generated,omitempty"`
+	WNSNs               float64 `json:"wns_ns"`
+	TNSNs               float64 `json:"tns_ns"`
+	TNSFailingEndpoints int     `json:"tns_failing_endpoints"`
+	WHSNs               float64 `json:"whs_ns"`
+	THSNs               float64 `json:"ths_ns"`
+	THSFailingEndpoints int     `json:"ths_failing_endpoints"`
+	WPWSNs              float64 `json:"wpws_ns"`
+}
+
+// ParseTimingSummary parses the text output of `report_timing_summary`.
+func ParseTimingSummary(r io.Reader) (*TimingSummary, error) {
+	tables, err := ParseColumnTables(r)
+	if err != nil {
+		return nil, err
+	}
+	var s TimingSummary
+	var found bool
+	clocks := map[string]int{}
+	for _, t := range tables {
+		num := func(row []string, col string) float64 {
+			// Clocks without paths have no slack.
+			v, _ := ParseNumber(Cell(row, t.Column(col)))
+			return v
+		}
+		count := func(row []string, col string) int {
+			v, _ := strconv.Atoi(Cell(row, t.Column(col)))
+			return v
+		}
+		switch t.Section {
+		case "Design Timing Summary":
+			if len(t.Rows) == 0 {
+				continue
+			}
+			row := t.Rows[0]
+			found = true
+			s.WNSNs, s.TNSNs = num(row, "WNS(ns)"), num(row, "TNS(ns)")
+			s.TNSFailingEndpoints = count(row, "TNS Failing Endpoints")
+			s.TNSTotalEndpoints = count(row, "TNS Total Endpoints")
+			s.WHSNs, s.THSNs = num(row, "WHS(ns)"), num(row, "THS(ns)")
+			s.THSFailingEndpoints = count(row, "THS Failing Endpoints")
+			s.THSTotalEndpoints = count(row, "THS Total Endpoints")
+			s.WPWSNs, s.TPWSNs = num(row, "WPWS(ns)"), num(row, "TPWS(ns)")
+		case "Clock Summary":
+			for i, row := range t.Rows {
+				name := Cell(row, t.Column("Clock"))
+				if name == "" {
+					continue
+				}
+				clocks[name] = len(s.Clocks)
+				s.Clocks = append(s.Clocks, ClockTiming{
+					Name:         name,
+					Waveform:     Cell(row, t.Column("Waveform(ns)")),
+					PeriodNs:     num(row, "Period(ns)"),
+					FrequencyMHz: num(row, "Frequency(MHz)"),
+					Generated:    t.Indents[i] > 0,
+				})
+			}
+		case "Intra Clock Table":
+			for _, row := range t.Rows {
+				name := Cell(row, t.Column("Clock"))
+				i, ok := clocks[name]
+				if !ok {
+					clocks[name] = len(s.Clocks)
+					i = len(s.Clocks)
+					s.Clocks = append(s.Clocks, ClockTiming{Name: name})
+				}
+				c := &s.Clocks[i]
+				c.WNSNs, c.TNSNs = num(row, "WNS(ns)"), num(row, "TNS(ns)")
+				c.TNSFailingEndpoints = count(row, "TNS Failing Endpoints")
+				c.WHSNs, c.THSNs = num(row, "WHS(ns)"), num(row, "THS(ns)")
+				c.THSFailingEndpoints = count(row, "THS Failing Endpoints")
+				c.WPWSNs = num(row, "WPWS(ns)")
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no design timing summary, not a report_timing_summary report?")
+	}
+	return &s, nil
+}
@@ -0,0 +1,87 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const timingSummaryReport = `Timing Summary Report
+
+------------------------------------------------------------------------------------------------
+| Design Timing Summary
+| ---------------------
+------------------------------------------------------------------------------------------------
+
+    WNS(ns)      TNS(ns)  TNS Failing Endpoints  TNS Total Endpoints      WHS(ns)      THS(ns)  THS Failing Endpoints  THS Total Endpoints     WPWS(ns)     TPWS(ns)  TPWS Failing Endpoints  TPWS Total Endpoints
+    -------      -------  ---------------------  -------------------      -------      -------  ---------------------  -------------------     --------     --------  ----------------------  --------------------
+     -0.512       -1.234                      3                 1234        0.051        0.000                      0                 1234        1.750        0.000                       0                   500
+
+
+Timing constraints are not met.
+
+
+------------------------------------------------------------------------------------------------
+| Clock Summary
+| -------------
+------------------------------------------------------------------------------------------------
+
+Clock              Waveform(ns)       Period(ns)      Frequency(MHz)
+-----              ------------       ----------      --------------
+clk                {0.000 5.000}      10.000          100.000
+  clk_out1_mmcm    {0.000 2.500}      5.000           200.000
+
+
+------------------------------------------------------------------------------------------------
+| Intra Clock Table
+| -----------------
+------------------------------------------------------------------------------------------------
+
+Clock                  WNS(ns)      TNS(ns)  TNS Failing Endpoints  TNS Total Endpoints      WHS(ns)      THS(ns)  THS Failing Endpoints  THS Total Endpoints     WPWS(ns)     TPWS(ns)  TPWS Failing Endpoints  TPWS Total Endpoints
+-----                  -------      -------  ---------------------  -------------------      -------      -------  ---------------------  -------------------     --------     --------  ----------------------  --------------------
+clk                                                                                                                                                               3.000        0.000                       0                     1
+  clk_out1_mmcm         -0.512       -1.234                      3                 1234        0.051        0.000                      0                 1234        1.750        0.000                       0                   499
+`
+
+func TestParseTimingSummary(t *testing.T) {
+	s, err := ParseTimingSummary(strings.NewReader(timingSummaryReport))
+	if err != nil {
+		t.Fatalf("ParseTimingSummary() error = %v", err)
+	}
+	want := &TimingSummary{
+		WNSNs:               -0.512,
+		TNSNs:               -1.234,
+		TNSFailingEndpoints: 3,
+		TNSTotalEndpoints:   1234,
+		WHSNs:               0.051,
+		THSTotalEndpoints:   1234,
+		WPWSNs:              1.75,
+		Clocks: []ClockTiming{
+			{Name: "clk", Waveform: "{0.000 5.000}", PeriodNs: 10, FrequencyMHz: 100, WPWSNs: 3},
+			{
+				Name:                "clk_out1_mmcm",
+				Waveform:            "{0.000 2.500}",
+				PeriodNs:            5,
+				FrequencyMHz:        200,
+				Generated:           true,
+				WNSNs:               -0.512,
+				TNSNs:               -1.234,
+				TNSFailingEndpoints: 3,
+				WHSNs:               0.051,
+				WPWSNs:              1.75,
+			},
+		},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("ParseTimingSummary() = %+v, want %+v", s, want)
+	}
+	if s.Met() {
+		t.Errorf("Met() = true, want false")
+	}
+}
+
+func TestParseTimingSummaryNotATimingSummary(t *testing.T) {
+	if _, err := ParseTimingSummary(strings.NewReader(powerReport)); err == nil {
+		t.Errorf("ParseTimingSummary() succeeded, want error")
+	}
+}
@@ -0,0 +1,143 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const timingReport = `Timing Report
+
+Slack (VIOLATED) :        -0.512ns  (required time - arrival time)
+  Source:                 u_a/q_reg[3]/C
+                            (rising edge-triggered cell FDRE clocked by clk_sys  {rise@0.000ns fall@2.500ns period=5.000ns})
+  Destination:            u_b/u_mac/acc_reg[7]/D
+                            (rising edge-triggered cell FDRE clocked by clk_sys  {rise@0.000ns fall@2.500ns period=5.000ns})
+  Path Group:             clk_sys
+  Path Type:              Setup (Max at Slow Process Corner)
+  Requirement:            5.000ns  (clk_sys rise@5.000ns - clk_sys rise@0.000ns)
+  Data Path Delay:        5.321ns  (logic 2.110ns (39.654%)  route 3.211ns (60.346%))
+  Logic Levels:           2  (LUT3=1 LUT6=1)
+  Clock Path Skew:        -0.045ns (DCD - SCD + CPR)
+
+    Location             Delay type                Incr(ns)  Path(ns)    Netlist Resource(s)
+  -------------------------------------------------------------------    -------------------
+                         (clock clk_sys rise edge)    0.000     0.000 r
+    E3                                                0.000     0.000 r  clk (IN)
+                         net (fo=0)                   0.000     0.000    clk
+    BUFGCTRL_X0Y0        BUFG (Prop_bufg_I_O)         0.096     3.551 r  clk_IBUF_BUFG_inst/O
+                         net (fo=40, routed)          1.617     5.168    clk_IBUF_BUFG
+    SLICE_X0Y0           FDRE                                         r  u_a/q_reg[3]/C
+  -------------------------------------------------------------------    -------------------
+    SLICE_X0Y0           FDRE (Prop_fdre_C_Q)         0.456     5.624 r  u_a/q_reg[3]/Q
+                         net (fo=3, routed)           1.800     7.424    u_a/q[3]
+    SLICE_X1Y1           LUT3 (Prop_lut3_I0_O)        0.124     7.548 r  u_b/u_mac/acc[7]_i_2/O
+                         net (fo=1, routed)           1.411     8.959    u_b/u_mac/acc[7]_i_2_n_0
+    SLICE_X1Y2           LUT6 (Prop_lut6_I1_O)        1.530    10.489 r  u_b/u_mac/acc[7]_i_1/O
+    SLICE_X1Y2           FDRE                                         r  u_b/u_mac/acc_reg[7]/D
+  -------------------------------------------------------------------    -------------------
+
+                         (clock clk_sys rise edge)    5.000     5.000 r
+    SLICE_X1Y2           FDRE (Setup_fdre_C_D)        0.062    10.0      u_b/u_mac/acc_reg[7]
+  -------------------------------------------------------------------
+                         required time                         10.0
+                         arrival time                         -10.512
+  -------------------------------------------------------------------
+                         slack                                 -0.512
+
+
+Slack (MET) :             0.102ns  (arrival time - required time)
+  Source:                 btn
+                            (input port)
+  Destination:            sync_reg[0]/D
+                            (rising edge-triggered cell FDRE clocked by clk_sys  {rise@0.000ns fall@2.500ns period=5.000ns})
+  Path Group:             clk_sys
+  Path Type:              Hold (Min at Fast Process Corner)
+  Requirement:            0.000ns  (clk_sys rise@0.000ns - clk_sys rise@0.000ns)
+  Data Path Delay:        1.100ns  (logic 0.300ns (27.273%)  route 0.800ns (72.727%))
+  Logic Levels:           1  (IBUF=1)
+
+    Location             Delay type                Incr(ns)  Path(ns)    Netlist Resource(s)
+  -------------------------------------------------------------------    -------------------
+                         input delay                  0.000     0.000
+  -------------------------------------------------------------------    -------------------
+    E4                                                0.000     0.000 r  btn (IN)
+                         net (fo=0)                   0.000     0.000    btn
+    E4                   IBUF (Prop_ibuf_I_O)         0.300     0.300 r  btn_IBUF_inst/O
+                         net (fo=1, routed)           0.800     1.100    btn_IBUF
+    SLICE_X2Y2           FDRE                                         r  sync_reg[0]/D
+  -------------------------------------------------------------------    -------------------
+
+
+Slack:                    inf
+  Source:                 rst
+                            (input port)
+  Destination:            led[0]
+  Path Group:             (none)
+`
+
+func TestParseTimingPaths(t *testing.T) {
+	paths, err := ParseTimingPaths(strings.NewReader(timingReport))
+	if err != nil {
+		t.Fatalf("ParseTimingPaths() error = %v", err)
+	}
+	want := []TimingPath{
+		{
+			Type:            "setup",
+			SlackNs:         -0.512,
+			Startpoint:      "u_a/q_reg[3]/C",
+			Endpoint:        "u_b/u_mac/acc_reg[7]/D",
+			StartClock:      "clk_sys",
+			EndClock:        "clk_sys",
+			PathGroup:       "clk_sys",
+			RequirementNs:   5,
+			DataPathDelayNs: 5.321,
+			LogicDelayNs:    2.110,
+			RouteDelayNs:    3.211,
+			LogicLevels:     2,
+			Cells: []string{
+				"u_a/q_reg[3]",
+				"u_b/u_mac/acc[7]_i_2",
+				"u_b/u_mac/acc[7]_i_1",
+				"u_b/u_mac/acc_reg[7]",
+			},
+			Module: "u_b/u_mac",
+		},
+		{
+			Type:            "hold",
+			SlackNs:         0.102,
+			Startpoint:      "btn",
+			Endpoint:        "sync_reg[0]/D",
+			EndClock:        "clk_sys",
+			PathGroup:       "clk_sys",
+			DataPathDelayNs: 1.1,
+			LogicDelayNs:    0.3,
+			RouteDelayNs:    0.8,
+			LogicLevels:     1,
+			Cells:           []string{"btn_IBUF_inst", "sync_reg[0]"},
+		},
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("ParseTimingPaths() = %+v, want %+v", paths, want)
+	}
+	if !paths[0].Failing() || paths[1].Failing() {
+		t.Errorf("Failing() = %v, %v, want true, false", paths[0].Failing(), paths[1].Failing())
+	}
+}
+
+func TestByModule(t *testing.T) {
+	paths := []TimingPath{
+		{SlackNs: 0.2, Module: "u_a"},
+		{SlackNs: -0.1, Module: "u_b"},
+		{SlackNs: -0.3, Module: "u_b"},
+		{SlackNs: 0.5},
+	}
+	want := []ModuleTiming{
+		{Module: "u_b", Paths: 2, Failing: 2, WorstSlackNs: -0.3},
+		{Module: "u_a", Paths: 1, WorstSlackNs: 0.2},
+		{Module: "", Paths: 1, WorstSlackNs: 0.5},
+	}
+	if got := ByModule(paths); !reflect.DeepEqual(got, want) {
+		t.Errorf("ByModule() = %+v, want %+v", got, want)
+	}
+}
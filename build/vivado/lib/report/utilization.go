@@ -0,0 +1,153 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Resource is the use of a site type, such as "Slice LUTs".
+type Resource struct {
+	Name      string  `json:"name"`
+	Used      float64 `json:"used"`
+	Available float64 `json:"available"`
+	UtilPct   float64 `json:"util_pct"`
+}
+
+// Utilization is the result of `report_utilization`.
+type Utilization struct {
+	LUT  float64 `json:"lut"`
+	FF   float64 `json:"ff"`
+	BRAM float64 `json:"bram"`
+	DSP  float64 `json:"dsp"`
+	// Resources are the site types of the report, without their breakdown,
+	// such as "LUT as Logic" below "Slice LUTs".
+	Resources []Resource `json:"resources"`
+}
+
+// Resource returns the resource named any of names, and whether there is
+// one. Vivado names the site types of each device family differently.
+func (u Utilization) Resource(names ...string) (Resource, bool) {
+	for _, name := range names {
+		for _, r := range u.Resources {
+			if r.Name == name {
+				return r, true
+			}
+		}
+	}
+	return Resource{}, false
+}
+
+// ParseUtilization parses the text output of `report_utilization`.
+func ParseUtilization(r io.Reader) (*Utilization, error) {
+	tables, err := ParseTables(r)
+	if err != nil {
+		return nil, err
+	}
+	var u Utilization
+	seen := map[string]bool{}
+	for _, t := range tables {
+		site, used := t.Column("Site Type"), t.Column("Used")
+		if site < 0 || used < 0 {
+			continue
+		}
+		for i, row := range t.Rows {
+			// Vivado marks the site types that it may still optimize
+			// away with an asterisk.
+			name := strings.TrimSuffix(Cell(row, site), "*")
+			if t.Indents[i] > 0 || name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			res := Resource{Name: name}
+			res.Used, _ = ParseNumber(Cell(row, used))
+			res.Available, _ = ParseNumber(Cell(row, t.Column("Available")))
+			res.UtilPct, _ = ParseNumber(Cell(row, t.Column("Util%")))
+			u.Resources = append(u.Resources, res)
+		}
+	}
+	if len(u.Resources) == 0 {
+		return nil, fmt.Errorf("no site types, not a report_utilization report?")
+	}
+	if r, ok := u.Resource("Slice LUTs", "CLB LUTs"); ok {
+		u.LUT = r.Used
+	}
+	if r, ok := u.Resource("Slice Registers", "CLB Registers"); ok {
+		u.FF = r.Used
+	}
+	if r, ok := u.Resource("Block RAM Tile"); ok {
+		u.BRAM = r.Used
+	}
+	if r, ok := u.Resource("DSPs"); ok {
+		u.DSP = r.Used
+	}
+	return &u, nil
+}
+
+// InstanceUtilization is the use of the resources of an instance of the
+// design, including those of the instances within it.
+type InstanceUtilization struct {
+	// Instance is the hierarchical path of the instance, e.g. "u_a/u_b",
+	// or the name of the top module for the design itself.
+	Instance string `json:"instance"`
+	Module   string `json:"module"`
+	// Depth is 0 for the design, 1 for the instances in it, and so on.
+	Depth int     `json:"depth"`
+	LUT   float64 `json:"lut"`
+	FF    float64 `json:"ff"`
+	// BRAM counts block RAM tiles, of which a RAMB18 is half.
+	BRAM float64 `json:"bram"`
+	DSP  float64 `json:"dsp"`
+}
+
+// ParseHierUtilization parses the text output of
+// `report_utilization -hierarchical`. The instances are in the order of the
+// report, each after the instance that it is in.
+func ParseHierUtilization(r io.Reader) ([]InstanceUtilization, error) {
+	tables, err := ParseTables(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		us    []InstanceUtilization
+		found bool
+	)
+	for _, t := range tables {
+		inst := t.Column("Instance")
+		if inst < 0 || t.Column("Module") < 0 {
+			continue
+		}
+		found = true
+		// The path of the instance at each depth.
+		var path []string
+		for i, row := range t.Rows {
+			num := func(names ...string) float64 {
+				v, _ := ParseNumber(Cell(row, t.Column(names...)))
+				return v
+			}
+			// Each level of the hierarchy indents by two spaces.
+			depth := t.Indents[i] / 2
+			if depth > len(path) {
+				depth = len(path)
+			}
+			path = append(path[:depth], Cell(row, inst))
+			name := path[0]
+			if depth > 0 {
+				name = strings.Join(path[1:], "/")
+			}
+			us = append(us, InstanceUtilization{
+				Instance: name,
+				Module:   Cell(row, t.Column("Module")),
+				Depth:    depth,
+				LUT:      num("Total LUTs"),
+				FF:       num("FFs"),
+				BRAM:     num("RAMB36", "RAMB36 Blocks") + num("RAMB18", "RAMB18 Blocks")/2,
+				DSP:      num("DSP48 Blocks", "DSP Blocks", "DSPs"),
+			})
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no instances, not a report_utilization -hierarchical report?")
+	}
+	return us, nil
+}
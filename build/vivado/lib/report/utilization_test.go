@@ -0,0 +1,104 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const utilizationReport = `Utilization Design Information
+
+1. Slice Logic
+--------------
+
++----------------------------+------+-------+-----------+-------+
+|          Site Type         | Used | Fixed | Available | Util% |
++----------------------------+------+-------+-----------+-------+
+| Slice LUTs*                | 1234 |     0 |     63400 |  1.95 |
+|   LUT as Logic             | 1200 |     0 |     63400 |  1.89 |
+| Slice Registers            | 2000 |     0 |    126800 |  1.58 |
++----------------------------+------+-------+-----------+-------+
+
+
+3. Memory
+---------
+
++-------------------+------+-------+-----------+-------+
+|     Site Type     | Used | Fixed | Available | Util% |
++-------------------+------+-------+-----------+-------+
+| Block RAM Tile    |  1.5 |     0 |       135 |  1.11 |
+|   RAMB36/FIFO*    |    1 |     0 |       135 |  0.74 |
++-------------------+------+-------+-----------+-------+
+
+
+4. DSP
+------
+
++-----------+------+-------+-----------+-------+
+| Site Type | Used | Fixed | Available | Util% |
++-----------+------+-------+-----------+-------+
+| DSPs      |    2 |     0 |       240 |  0.83 |
++-----------+------+-------+-----------+-------+
+`
+
+const hierUtilizationReport = `Utilization Design Information
+
+1. Utilization by Hierarchy
+---------------------------
+
++--------------+--------+------------+-----+--------+--------+--------------+
+|   Instance   | Module | Total LUTs | FFs | RAMB36 | RAMB18 | DSP48 Blocks |
++--------------+--------+------------+-----+--------+--------+--------------+
+| top          |  (top) |       1234 | 2000|      1 |      1 |            2 |
+|   u_a        |      a |       1000 | 1500|      1 |      0 |            2 |
+|     u_sub    |    sub |        100 |  200|      0 |      0 |            0 |
+|   u_b        |      b |        200 |  400|      0 |      1 |            0 |
++--------------+--------+------------+-----+--------+--------+--------------+
+`
+
+func TestParseUtilization(t *testing.T) {
+	u, err := ParseUtilization(strings.NewReader(utilizationReport))
+	if err != nil {
+		t.Fatalf("ParseUtilization() error = %v", err)
+	}
+	want := &Utilization{
+		LUT:  1234,
+		FF:   2000,
+		BRAM: 1.5,
+		DSP:  2,
+		Resources: []Resource{
+			{Name: "Slice LUTs", Used: 1234, Available: 63400, UtilPct: 1.95},
+			{Name: "Slice Registers", Used: 2000, Available: 126800, UtilPct: 1.58},
+			{Name: "Block RAM Tile", Used: 1.5, Available: 135, UtilPct: 1.11},
+			{Name: "DSPs", Used: 2, Available: 240, UtilPct: 0.83},
+		},
+	}
+	if !reflect.DeepEqual(u, want) {
+		t.Errorf("ParseUtilization() = %+v, want %+v", u, want)
+	}
+}
+
+func TestParseHierUtilization(t *testing.T) {
+	us, err := ParseHierUtilization(strings.NewReader(hierUtilizationReport))
+	if err != nil {
+		t.Fatalf("ParseHierUtilization() error = %v", err)
+	}
+	want := []InstanceUtilization{
+		{Instance: "top", Module: "(top)", LUT: 1234, FF: 2000, BRAM: 1.5, DSP: 2},
+		{Instance: "u_a", Module: "a", Depth: 1, LUT: 1000, FF: 1500, BRAM: 1, DSP: 2},
+		{Instance: "u_a/u_sub", Module: "sub", Depth: 2, LUT: 100, FF: 200},
+		{Instance: "u_b", Module: "b", Depth: 1, LUT: 200, FF: 400, BRAM: 0.5},
+	}
+	if !reflect.DeepEqual(us, want) {
+		t.Errorf("ParseHierUtilization() = %+v, want %+v", us, want)
+	}
+}
+
+func TestParseUtilizationNotAUtilizationReport(t *testing.T) {
+	if _, err := ParseUtilization(strings.NewReader(powerReport)); err == nil {
+		t.Errorf("ParseUtilization() succeeded, want error")
+	}
+	if _, err := ParseHierUtilization(strings.NewReader(powerReport)); err == nil {
+		t.Errorf("ParseHierUtilization() succeeded, want error")
+	}
+}
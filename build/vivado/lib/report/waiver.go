@@ -0,0 +1,210 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Violation is an issue that a Vivado report flags, such as an unsafe clock
+// domain crossing.
+type Violation struct {
+	// ID is the check that flags the issue, e.g. "CDC-1".
+	ID string `json:"id"`
+	// Severity is e.g. "Critical", "Warning" or "Info".
+	Severity string `json:"severity"`
+	// Category is the kind of clock domain crossing: "safe", "unsafe" or
+	// "unknown".
+	Category    string `json:"category,omitempty"`
+	Description string `json:"description,omitempty"`
+	FromClock   string `json:"from_clock,omitempty"`
+	ToClock     string `json:"to_clock,omitempty"`
+	Startpoint  string `json:"startpoint,omitempty"`
+	Endpoint    string `json:"endpoint,omitempty"`
+	// WaiverReason is the reason of the waiver that matches the violation,
+	// if any.
+	WaiverReason string `json:"waiver_reason,omitempty"`
+}
+
+// Critical returns true if the violation has critical severity, including
+// Vivado's "Critical Warning" and "Error".
+func (v Violation) Critical() bool {
+	s := strings.ToLower(v.Severity)
+	return strings.HasPrefix(s, "critical") || s == "error"
+}
+
+// Waived returns true if a waiver matches the violation.
+func (v Violation) Waived() bool {
+	return v.WaiverReason != ""
+}
+
+// Waiver accepts the violations that it matches. A waiver file is a JSON
+// file such as:
+//
+//	{
+//	  "waivers": [
+//	    {
+//	      "id": "CDC-1",
+//	      "from_clock": "clk_adc",
+//	      "to_clock": "clk_sys",
+//	      "endpoint": "u_adc_sync/*",
+//	      "reason": "Gray-coded FIFO pointers, see adc_fifo.vhd."
+//	    },
+//	    {
+//	      "id": "TIMING-18",
+//	      "description": "*led[*]*",
+//	      "reason": "LEDs are not timed."
+//	    }
+//	  ]
+//	}
+//
+// All set fields must match, and at least one must be set. In patterns, "*"
+// matches any text, including "/", and "?" any single character.
+type Waiver struct {
+	ID        string `json:"id"`
+	FromClock string `json:"from_clock"`
+	ToClock   string `json:"to_clock"`
+	Endpoint  string `json:"endpoint"`
+	// Description matches the description of the violation, which names
+	// the objects of checks that have no endpoint, such as methodology
+	// checks.
+	Description string `json:"description"`
+	// Reason tells why the violation is safe. It is required.
+	Reason string `json:"reason"`
+
+	patterns []*regexp.Regexp
+}
+
+// Waivers is a waiver file.
+type Waivers struct {
+	Waivers []Waiver `json:"waivers"`
+}
+
+// ReadWaivers reads and checks the waiver file fn.
+func ReadWaivers(fn string) (*Waivers, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var ws Waivers
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&ws); err != nil {
+		return nil, fmt.Errorf("parse waivers %v: %w", fn, err)
+	}
+	for i := range ws.Waivers {
+		if err := ws.Waivers[i].compile(); err != nil {
+			return nil, fmt.Errorf("%v: waiver %d: %w", fn, i, err)
+		}
+	}
+	return &ws, nil
+}
+
+// compile checks the waiver and compiles its patterns.
+func (w *Waiver) compile() error {
+	fields := []string{w.ID, w.FromClock, w.ToClock, w.Endpoint, w.Description}
+	if strings.Join(fields, "") == "" {
+		return fmt.Errorf("waiver matches everything, set one of id, from_clock, to_clock, endpoint or description")
+	}
+	if strings.TrimSpace(w.Reason) == "" {
+		return fmt.Errorf("waiver has no reason")
+	}
+	w.patterns = nil
+	for _, f := range fields {
+		var re *regexp.Regexp
+		if f != "" {
+			var err error
+			re, err = globRegexp(f)
+			if err != nil {
+				return err
+			}
+		}
+		w.patterns = append(w.patterns, re)
+	}
+	return nil
+}
+
+// globRegexp compiles a pattern with "*" and "?" wildcards.
+func globRegexp(p string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range p {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+func (w Waiver) String() string {
+	var s []string
+	for _, f := range []struct{ k, v string }{
+		{"id", w.ID}, {"from_clock", w.FromClock}, {"to_clock", w.ToClock},
+		{"endpoint", w.Endpoint}, {"description", w.Description},
+	} {
+		if f.v != "" {
+			s = append(s, fmt.Sprintf("%v=%v", f.k, f.v))
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+// Match returns true if the waiver matches v.
+func (w Waiver) Match(v Violation) bool {
+	if w.patterns == nil {
+		if err := w.compile(); err != nil {
+			return false
+		}
+	}
+	for i, f := range []string{v.ID, v.FromClock, v.ToClock, v.Endpoint, v.Description} {
+		if re := w.patterns[i]; re != nil && !re.MatchString(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply marks the violations that a waiver matches as waived, and returns
+// the waivers that match none. Those are stale, and best removed.
+func (ws *Waivers) Apply(vs []Violation) []Waiver {
+	if ws == nil {
+		return nil
+	}
+	used := make([]bool, len(ws.Waivers))
+	for i := range vs {
+		for j, w := range ws.Waivers {
+			if w.Match(vs[i]) {
+				vs[i].WaiverReason = w.Reason
+				used[j] = true
+				break
+			}
+		}
+	}
+	var unused []Waiver
+	for j, w := range ws.Waivers {
+		if !used[j] {
+			unused = append(unused, w)
+		}
+	}
+	return unused
+}
+
+// Unwaived returns the critical violations of vs that no waiver matches.
+func Unwaived(vs []Violation) []Violation {
+	var u []Violation
+	for _, v := range vs {
+		if v.Critical() && !v.Waived() {
+			u = append(u, v)
+		}
+	}
+	return u
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeWaivers(t *testing.T, content string) string {
+	t.Helper()
+	fn := filepath.Join(t.TempDir(), "waivers.json")
+	if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fn
+}
+
+func TestWaiversApply(t *testing.T) {
+	ws, err := ReadWaivers(writeWaivers(t, `{
+  "waivers": [
+    {"id": "CDC-1", "from_clock": "clk_adc", "endpoint": "u_sys/*_q_reg/D", "reason": "Held for 4 cycles"},
+    {"from_clock": "clk_dbg", "reason": "Debug only"}
+  ]
+}`))
+	if err != nil {
+		t.Fatalf("ReadWaivers() error = %v", err)
+	}
+	vs := []Violation{
+		{ID: "CDC-1", Severity: "Critical", FromClock: "clk_adc", ToClock: "clk_sys", Endpoint: "u_sys/valid_q_reg/D"},
+		{ID: "CDC-1", Severity: "Critical", FromClock: "clk_adc", ToClock: "clk_sys", Endpoint: "u_sys/other_reg/D"},
+		{ID: "CDC-3", Severity: "Warning", FromClock: "clk_adc", ToClock: "clk_sys", Endpoint: "u_sync/sync_reg[0]/D"},
+	}
+	unused := ws.Apply(vs)
+	if !vs[0].Waived() || vs[0].WaiverReason != "Held for 4 cycles" {
+		t.Errorf("Apply() did not waive %+v", vs[0])
+	}
+	if vs[1].Waived() || vs[2].Waived() {
+		t.Errorf("Apply() waived %+v, %+v", vs[1], vs[2])
+	}
+	if len(unused) != 1 || unused[0].FromClock != "clk_dbg" {
+		t.Errorf("Apply() unused = %+v, want the clk_dbg waiver", unused)
+	}
+	if got := Unwaived(vs); len(got) != 1 || got[0].Endpoint != "u_sys/other_reg/D" {
+		t.Errorf("Unwaived() = %+v", got)
+	}
+}
+
+func TestReadWaiversInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"No reason":     `{"waivers": [{"id": "CDC-1"}]}`,
+		"Matches all":   `{"waivers": [{"reason": "all fine"}]}`,
+		"Unknown field": `{"waivers": [{"id": "CDC-1", "reason": "x", "cell": "u_a"}]}`,
+		"Not JSON":      `waive CDC-1`,
+	} {
+		if _, err := ReadWaivers(writeWaivers(t, content)); err == nil {
+			t.Errorf("%v: ReadWaivers() succeeded, want error", name)
+		}
+	}
+}
+
+func TestWaiverDescription(t *testing.T) {
+	ws, err := ReadWaivers(writeWaivers(t, `{"waivers": [
+  {"id": "TIMING-18", "description": "*led[*]*", "reason": "LEDs are not timed"}
+]}`))
+	if err != nil {
+		t.Fatalf("ReadWaivers() error = %v", err)
+	}
+	vs := []Violation{
+		{ID: "TIMING-18", Severity: "Warning", Description: "An output delay is missing on led[0]"},
+		{ID: "TIMING-18", Severity: "Warning", Description: "An output delay is missing on uart_tx"},
+	}
+	ws.Apply(vs)
+	if !vs[0].Waived() || vs[1].Waived() {
+		t.Errorf("Apply() = %+v, want only led[0] waived", vs)
+	}
+}
@@ -22,6 +22,15 @@ load("//internal:vivado_ila.bzl", _vivado_ila = "vivado_ila")
 load("//internal:vivado_read_ila.bzl", _vivado_read_ila = "vivado_read_ila")
 load("//internal:vivado_extract.bzl", _vivado_extract = "vivado_extract")
 load("//internal:vivado_program_flash.bzl", _vivado_program_flash = "vivado_program_flash")
+load("//internal:vivado_cfgmem.bzl",
+    _vivado_cfgmem = "vivado_cfgmem",
+    _vivado_multiboot_constraints = "vivado_multiboot_constraints",
+)
+load("//internal:vivado_updatemem.bzl", _vivado_updatemem = "vivado_updatemem")
+load("//internal:vivado_dfx.bzl", _vivado_dfx = "vivado_dfx")
+load("//internal:vivado_bin.bzl", _vivado_bin = "vivado_bin")
+load("//internal:vivado_bitstream_diff_test.bzl", _vivado_bitstream_diff_test = "vivado_bitstream_diff_test")
+load("//internal:vivado_report_test.bzl", _vivado_report_test = "vivado_report_test")
 
 vivado_project = _vivado_project
 vivado_synthesis = _vivado_synthesis
@@ -43,3 +52,10 @@ vivado_ila = _vivado_ila
 vivado_read_ila = _vivado_read_ila
 vivado_extract = _vivado_extract
 vivado_program_flash = _vivado_program_flash
+vivado_cfgmem = _vivado_cfgmem
+vivado_multiboot_constraints = _vivado_multiboot_constraints
+vivado_updatemem = _vivado_updatemem
+vivado_dfx = _vivado_dfx
+vivado_bin = _vivado_bin
+vivado_bitstream_diff_test = _vivado_bitstream_diff_test
+vivado_report_test = _vivado_report_test
@@ -2,6 +2,7 @@
 
 load("@bazel_skylib//rules:common_settings.bzl", "BuildSettingInfo")
 load("@rules_bid//build:rules.bzl", "run_docker_cmd")
+load("//internal:providers.bzl", "VivadoNetlistProvider")
 
 DOCKER_RUN_SCRIPT_ATTRS = {
     "env": attr.string_dict(
@@ -117,3 +118,338 @@ def script_cmd(
         freeargs=freeargs,
         workdir_name="/work",
     )
+
+# Attribute set for rules that write simulation netlists with
+# `sim_netlists(ctx, ...)`.
+SIM_NETLIST_ATTRS = {
+    "funcsim_netlist": attr.string(
+        default = "",
+        values = ["", "verilog", "vhdl"],
+        doc = "If set, writes a functional simulation netlist in this " +
+              "language, in the `netlists` output group.",
+    ),
+    "timesim_netlist": attr.bool(
+        default = False,
+        doc = "If set, writes a Verilog timing simulation netlist and its " +
+              "SDF delays, in the `netlists` output group.",
+    ),
+}
+
+def sim_netlists(ctx, args):
+    """Declares the simulation netlists that the rule is asked to write.
+
+    Args:
+      ctx: The rule context. The rule's `attrs` must include SIM_NETLIST_ATTRS.
+      args: The xprgen arguments to add the netlist files to.
+
+    Returns:
+      A VivadoNetlistProvider, or None if no netlists are asked for.
+    """
+    if not ctx.attr.funcsim_netlist and not ctx.attr.timesim_netlist:
+        return None
+    name = ctx.attr.name
+    funcsim = None
+    if ctx.attr.funcsim_netlist:
+        ext = "vhd" if ctx.attr.funcsim_netlist == "vhdl" else "v"
+        funcsim = ctx.actions.declare_file("{}.funcsim.{}".format(name, ext))
+        args.add("--funcsim-netlist", funcsim.path)
+    timesim = None
+    sdf = None
+    if ctx.attr.timesim_netlist:
+        timesim = ctx.actions.declare_file("{}.timesim.v".format(name))
+        sdf = ctx.actions.declare_file("{}.timesim.sdf".format(name))
+        args.add("--timesim-netlist", timesim.path)
+        args.add("--sdf-file", sdf.path)
+    return VivadoNetlistProvider(
+        funcsim = funcsim,
+        timesim = timesim,
+        sdf = sdf,
+    )
+
+# Attribute set for rules that check clock domain crossings with
+# `cdc_reports(ctx, ...)` and `cdc_check(ctx, ...)`.
+CDC_ATTRS = {
+    "cdc_report": attr.bool(
+        default = False,
+        doc = "If set, writes `report_cdc -details` and " +
+              "`report_clock_interaction` reports, and fails the build on " +
+              "critical clock domain crossings and unsafe clock pairs that " +
+              "`cdc_waivers` does not waive. The reports and the checked " +
+              "crossings as JSON are in the `cdc` output group.",
+    ),
+    "cdc_waivers": attr.label(
+        allow_single_file = [".json"],
+        doc = "A JSON file of waivers for `cdc_report`, keyed by check ID, " +
+              "source and destination clock and endpoint pattern, each " +
+              "with a reason. See `//build/vivado/bin/cdcreport`.",
+    ),
+    "_cdcreport": attr.label(
+        default = Label("//build/vivado/bin/cdcreport"),
+        executable = True,
+        cfg = "host",
+        doc = "The cdcreport binary.",
+    ),
+}
+
+def cdc_reports(ctx, suffix, args):
+    """Declares the clock domain crossing reports that the rule is asked to write.
+
+    Args:
+      ctx: The rule context. The rule's `attrs` must include CDC_ATTRS.
+      suffix: The suffix of the report names, e.g. "_synth".
+      args: The xprgen arguments to add the report files to.
+
+    Returns:
+      A struct with the `reports` for Vivado to write, and the `json` result
+      that `cdc_check` writes, or None if no reports are asked for.
+    """
+    if not ctx.attr.cdc_report:
+        if ctx.file.cdc_waivers:
+            fail("cdc_waivers: set cdc_report to check clock domain crossings")
+        return None
+    name = ctx.attr.name
+    cdc = ctx.actions.declare_file("{}.cdc{}.rpt".format(name, suffix))
+    interaction = ctx.actions.declare_file(
+        "{}.clock_interaction{}.rpt".format(name, suffix))
+    args.add("--cdc-report", cdc.path)
+    args.add("--clock-interaction-report", interaction.path)
+    return struct(
+        reports = [cdc, interaction],
+        json = ctx.actions.declare_file("{}.cdc{}.json".format(name, suffix)),
+    )
+
+def cdc_check(ctx, cdc):
+    """Checks the reports of `cdc_reports`, and writes the result as JSON.
+
+    Args:
+      ctx: The rule context. The rule's `attrs` must include CDC_ATTRS.
+      cdc: The result of `cdc_reports`, or None.
+
+    Returns:
+      The files of the `cdc` output group.
+    """
+    if not cdc:
+        return []
+    (cdc_file, interaction_file) = cdc.reports
+    inputs = [cdc_file, interaction_file]
+    args = ctx.actions.args()
+    args.add("--cdc-report", cdc_file.path)
+    args.add("--clock-interaction-report", interaction_file.path)
+    args.add("--out", cdc.json.path)
+    if ctx.file.cdc_waivers:
+        inputs.append(ctx.file.cdc_waivers)
+        args.add("--waivers", ctx.file.cdc_waivers.path)
+    ctx.actions.run(
+        inputs = inputs,
+        outputs = [cdc.json],
+        executable = ctx.executable._cdcreport,
+        arguments = [args],
+        mnemonic = "CDCREPORT",
+        progress_message = "Checking clock domain crossings: {}".format(cdc_file.path),
+    )
+    return cdc.reports + [cdc.json]
+
+# Attribute set for rules that check timing constraints and design
+# methodology with `methodology_reports(ctx, ...)` and
+# `methodology_check(ctx, ...)`.
+METHODOLOGY_ATTRS = {
+    "methodology_report": attr.bool(
+        default = False,
+        doc = "If set, writes `check_timing -verbose` and " +
+              "`report_methodology` reports, and fails the build on " +
+              "critical violations, such as unclocked registers or " +
+              "unconstrained ports, that `methodology_waivers` does not " +
+              "waive. The reports and the violations by check ID as JSON " +
+              "are in the `methodology` output group.",
+    ),
+    "methodology_waivers": attr.label(
+        allow_single_file = [".json"],
+        doc = "A JSON file of waivers for `methodology_report`, in the " +
+              "format of `cdc_waivers`, keyed by check ID, e.g. " +
+              "`TIMING-17` or `no_clock`, and endpoint or description " +
+              "pattern. See `//build/vivado/bin/methodologyreport`.",
+    ),
+    "_methodologyreport": attr.label(
+        default = Label("//build/vivado/bin/methodologyreport"),
+        executable = True,
+        cfg = "host",
+        doc = "The methodologyreport binary.",
+    ),
+}
+
+def methodology_reports(ctx, suffix, args):
+    """Declares the timing and methodology reports that the rule is asked to write.
+
+    Args:
+      ctx: The rule context. The rule's `attrs` must include METHODOLOGY_ATTRS.
+      suffix: The suffix of the report names, e.g. "_synth".
+      args: The xprgen arguments to add the report files to.
+
+    Returns:
+      A struct with the `reports` for Vivado to write, and the `json` result
+      that `methodology_check` writes, or None if no reports are asked for.
+    """
+    if not ctx.attr.methodology_report:
+        if ctx.file.methodology_waivers:
+            fail("methodology_waivers: set methodology_report to check " +
+                 "timing and methodology")
+        return None
+    name = ctx.attr.name
+    check_timing = ctx.actions.declare_file(
+        "{}.check_timing{}.rpt".format(name, suffix))
+    methodology = ctx.actions.declare_file(
+        "{}.methodology{}.rpt".format(name, suffix))
+    args.add("--check-timing-report", check_timing.path)
+    args.add("--methodology-report", methodology.path)
+    return struct(
+        reports = [check_timing, methodology],
+        json = ctx.actions.declare_file(
+            "{}.methodology{}.json".format(name, suffix)),
+    )
+
+def methodology_check(ctx, methodology):
+    """Checks the reports of `methodology_reports`, and writes the result as JSON.
+
+    Args:
+      ctx: The rule context. The rule's `attrs` must include METHODOLOGY_ATTRS.
+      methodology: The result of `methodology_reports`, or None.
+
+    Returns:
+      The files of the `methodology` output group.
+    """
+    if not methodology:
+        return []
+    (check_timing_file, methodology_file) = methodology.reports
+    inputs = [check_timing_file, methodology_file]
+    args = ctx.actions.args()
+    args.add("--check-timing-report", check_timing_file.path)
+    args.add("--methodology-report", methodology_file.path)
+    args.add("--out", methodology.json.path)
+    if ctx.file.methodology_waivers:
+        inputs.append(ctx.file.methodology_waivers)
+        args.add("--waivers", ctx.file.methodology_waivers.path)
+    ctx.actions.run(
+        inputs = inputs,
+        outputs = [methodology.json],
+        executable = ctx.executable._methodologyreport,
+        arguments = [args],
+        mnemonic = "METHODOLOGYREPORT",
+        progress_message = "Checking timing and methodology: {}".format(
+            methodology_file.path),
+    )
+    return methodology.reports + [methodology.json]
+
+METRICS_ATTRS = {
+    "prometheus_metrics": attr.bool(
+        default = False,
+        doc = "The `metrics` output group has the elapsed time, CPU " +
+              "time and peak memory of every Vivado command in the logs " +
+              "as JSON. If set, also writes them as a Prometheus textfile, " +
+              "for the textfile collector of the node exporter.",
+    ),
+    "_logmetrics": attr.label(
+        default = Label("//build/vivado/bin/logmetrics"),
+        executable = True,
+        cfg = "host",
+        doc = "The logmetrics binary.",
+    ),
+}
+
+def log_metrics(ctx, suffix, logs):
+    """Writes the run time and memory of every command in the Vivado logs as JSON.
+
+    Args:
+      ctx: The rule context. The rule's `attrs` must include METRICS_ATTRS.
+      suffix: The suffix of the metrics file names, e.g. "_synth".
+      logs: The logs to read, as (step, log) tuples in the order that the
+        steps ran.
+
+    Returns:
+      The files of the `metrics` output group.
+    """
+    name = ctx.attr.name
+    json_file = ctx.actions.declare_file("{}.metrics{}.json".format(name, suffix))
+    outputs = [json_file]
+    args = ctx.actions.args()
+    args.add("--target", str(ctx.label))
+    for (step, log) in logs:
+        args.add("--log", "{}={}".format(step, log.path))
+    args.add("--out", json_file.path)
+    if ctx.attr.prometheus_metrics:
+        prom_file = ctx.actions.declare_file("{}.metrics{}.prom".format(name, suffix))
+        args.add("--prometheus", prom_file.path)
+        outputs.append(prom_file)
+    ctx.actions.run(
+        inputs = [log for (_, log) in logs],
+        outputs = outputs,
+        executable = ctx.executable._logmetrics,
+        arguments = [args],
+        mnemonic = "LOGMETRICS",
+        progress_message = "Collecting run time metrics: {}".format(json_file.path),
+    )
+    return outputs
+
+def _hook_attr(when, stage):
+    command = "write_bitstream" if stage == "bitstream" else "{}_design".format(stage)
+    return attr.label_list(
+        allow_files = [".tcl"],
+        default = [],
+        doc = "TCL files to `source`, in order, {} the `{}` command in Vivado".format(
+            when, command),
+    )
+
+def hook_attrs(stages):
+    """Returns the attributes of the TCL hook files for the given stages.
+
+    Every stage gets a `pre_<stage>_hooks` and a `post_<stage>_hooks` label
+    list attribute. The hooks are labels, so they are inputs of the stage
+    action, and a change to a hook only reruns the stages that source it.
+
+    Args:
+      stages: The stage names, e.g. ["opt", "place"].
+
+    Returns:
+      A dict of the attributes, to merge into the rule's `attrs`.
+    """
+    attrs = {}
+    for stage in stages:
+        attrs["pre_{}_hooks".format(stage)] = _hook_attr("before", stage)
+        attrs["post_{}_hooks".format(stage)] = _hook_attr("after", stage)
+    return attrs
+
+def stage_hooks(ctx, stage, args):
+    """Adds the TCL hook files of a stage to the xprgen arguments.
+
+    Args:
+      ctx: The rule context. The rule's `attrs` must include
+        `hook_attrs([stage])`.
+      stage: The stage name, e.g. "phys_opt".
+      args: The xprgen arguments of the stage.
+
+    Returns:
+      The hook files, which are inputs of the stage.
+    """
+    flag = stage.replace("_", "-")
+    pre = getattr(ctx.files, "pre_{}_hooks".format(stage))
+    post = getattr(ctx.files, "post_{}_hooks".format(stage))
+    args.add_all(pre, before_each = "--pre-{}-hook".format(flag))
+    args.add_all(post, before_each = "--post-{}-hook".format(flag))
+    return pre + post
+
+def check_include_dirs(ctx, include_dirs, hdrs):
+    """Fails unless every include dir holds at least one of the headers.
+
+    The include dirs are plain paths, and the Vivado actions only see their
+    declared inputs. So an include dir is only there if one of the headers
+    is in it, or below it.
+
+    Args:
+      ctx: The rule context.
+      include_dirs: The include dirs, relative to the exec root.
+      hdrs: The header files.
+    """
+    for d in include_dirs:
+        prefix = d.rstrip("/") + "/"
+        if not [f for f in hdrs if f.path.startswith(prefix)]:
+            fail("{}: include dir {} has none of the hdrs, add the headers in it to hdrs".format(
+                ctx.label, d))
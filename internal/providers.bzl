@@ -12,6 +12,10 @@ VivadoLibraryProvider = provider(
         "deps_names": "A depset of library names contained in `deps`",
         "library_dir": "A Vivado compiled library directory",
         "unisims_libs": "A boolean indicating if this library contains UNISIMs",
+        "netlist": "The simulation netlist compiled into this library, if any: " +
+          "a struct with `mode` (\"funcsim\" or \"timesim\"), `sdf` (the SDF " +
+          "file of a timing netlist, or None) and `glbl` (True for Verilog " +
+          "netlists, which need the `glbl` module)",
     }
 )
 
@@ -46,6 +50,30 @@ VivadoSynthProvider = provider(
     "synth_xpr_file": "The XPR file after synthesis",
     "synth_dcp_file": "The DCP file of synthesis step",
     "probes": "The probes file (.ltx) generated during synthesis (optional)",
+    "part": "The part designator that the design was synthesized for",
+    "log": "The Vivado log of the synthesis (optional)",
+  },
+)
+
+
+VivadoOocProvider = provider(
+  "Information about an out-of-context (OOC) synthesis partition",
+  fields = {
+    "dcp": "The DCP file of the out-of-context synthesis",
+    "stub": "The synthesis stub (.v or .vhdl) that declares the partition as a black box",
+    "top": "The top level entity of the partition",
+    "part": "The part designator that the partition was synthesized for",
+    "libraries": "The names of the libraries that the partition replaces in the designs that link it",
+  },
+)
+
+
+VivadoNetlistProvider = provider(
+  "Simulation netlists of a synthesized or routed design",
+  fields = {
+    "funcsim": "The functional simulation netlist (.v or .vhd), or None",
+    "timesim": "The timing simulation netlist (.v), or None",
+    "sdf": "The standard delay format (SDF) file that `timesim` annotates, or None",
   },
 )
 
@@ -55,6 +83,40 @@ VivadoBitstreamProvider = provider(
   fields = {
     "bitstream": "The bitstream to program into the FPGA",
     "probes": "The probes file (.ltx) generated during place and route (optional)",
+    "mem_info": "The block RAM memory map (.mmi) for updatemem (optional)",
+  },
+)
+
+VivadoReportsProvider = provider(
+  "The reports and logs of an implementation run",
+  fields = {
+    "timing_summary": "The `report_timing_summary` report of the routed design",
+    "utilization": "The `report_utilization` report of the routed design",
+    "drc": "The `report_drc` report of the routed design",
+    "logs": "The Vivado logs of the steps as (step, log) tuples, in the order that the steps ran",
+  },
+)
+
+VivadoDfxProvider = provider(
+  "Information about a dynamic function exchange (DFX) design",
+  fields = {
+    "full_bitstreams": "The full bitstreams, one per configuration, in configuration order",
+    "partial_bitstreams": "A dict of reconfigurable module label to its partial bitstream",
+    "static_dcp": "The routed and locked static design, with black box partitions",
+    "part": "The part designator that the design was implemented for",
+  },
+)
+
+VivadoCfgmemProvider = provider(
+  "Information about a configuration memory (flash) image",
+  fields = {
+    "image": "The flash image (.mcs or .bin) to program",
+    "images": "All flash image files; dual QSPI (SPIx8) images come in two halves",
+    "format": "The flash image format, mcs or bin",
+    "interface": "The flash interface the image was written for, e.g. SPIx4",
+    "size": "The flash size in megabytes",
+    "layout": "The MultiBoot flash layout file (.json), or None",
+    "layout_images": "A dict of layout image name to a flash image holding only that image",
   },
 )
 
@@ -0,0 +1,82 @@
+"""Rule to convert a bitstream into a raw binary image.
+
+`vivado_bin` strips the header of a `.bit` file and writes the configuration
+data as a raw `.bin` image, bit- and byte-swapped as needed for the
+configuration interface of the loader. It also writes a human readable dump of
+the configuration packets, which helps debug boot problems.
+
+The conversion is done by a Go tool, so unlike `write_bitstream -bin_file` it
+does not need a Vivado run.
+"""
+
+load("//internal:providers.bzl",
+    "VivadoBitstreamProvider",
+)
+
+def _vivado_bin_impl(ctx):
+    """Implementation for the vivado_bin rule.
+
+    Args:
+      ctx: The rule context.
+
+    Returns:
+      A list of providers with DefaultInfo carrying the binary image.
+    """
+    bitfile = ctx.attr.bitstream[VivadoBitstreamProvider].bitstream
+    bin_file = ctx.actions.declare_file("{}.bin".format(ctx.attr.name))
+    dump_file = ctx.actions.declare_file("{}.packets.txt".format(ctx.attr.name))
+
+    args = ctx.actions.args()
+    args.add("--bitfile", bitfile.path)
+    args.add("--out-bin", bin_file.path)
+    args.add("--dump", dump_file.path)
+    args.add("--interface", ctx.attr.interface)
+    args.add("--byte-swap", ctx.attr.byte_swap)
+
+    ctx.actions.run(
+        inputs = [bitfile],
+        outputs = [bin_file, dump_file],
+        executable = ctx.executable._bitconv,
+        arguments = [args],
+        mnemonic = "BITCONV",
+        progress_message = "Converting bitstream to binary image: {}".format(bin_file.path),
+    )
+
+    return [
+        DefaultInfo(files = depset([bin_file])),
+        OutputGroupInfo(
+            packets = depset([dump_file]),
+        ),
+    ]
+
+vivado_bin = rule(
+    implementation = _vivado_bin_impl,
+    doc = "Converts a bitstream into a raw binary (.bin) image without " +
+          "running Vivado. The configuration packet dump is available in " +
+          "the `packets` output group.",
+    attrs = {
+        "bitstream": attr.label(
+            mandatory = True,
+            providers = [VivadoBitstreamProvider],
+            doc = "The target providing the bitstream to convert.",
+        ),
+        "interface": attr.string(
+            default = "spi",
+            values = ["spi", "smapx8", "smapx16", "smapx32"],
+            doc = "The configuration interface of the loader. The SelectMAP " +
+                  "interfaces need the bits of every byte reversed.",
+        ),
+        "byte_swap": attr.int(
+            default = 0,
+            values = [0, 2, 4],
+            doc = "If set, reverses the byte order within words of this " +
+                  "many bytes, e.g. for loaders on little-endian CPUs.",
+        ),
+        "_bitconv": attr.label(
+            default = Label("//build/vivado/bin/bitconv"),
+            executable = True,
+            cfg = "host",
+            doc = "The bitstream conversion tool.",
+        ),
+    },
+)
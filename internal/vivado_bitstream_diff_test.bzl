@@ -0,0 +1,125 @@
+"""Test rule checking that two bitstreams are equivalent.
+
+`vivado_bitstream_diff_test` compares two bitstreams at the configuration
+frame level, ignoring the build timestamp in the `.bit` header, the USERID and
+the USR_ACCESS register. Use it to check that a release bitstream is
+reproducible, e.g. by comparing a fresh build against a golden copy checked in
+to the repository.
+"""
+
+load("//internal:providers.bzl",
+    "VivadoBitstreamProvider",
+)
+
+def _get_rlocation(file, ctx):
+    if file.short_path.startswith("../"):
+        return file.short_path[3:]
+    else:
+        return ctx.workspace_name + "/" + file.short_path
+
+def _bitstream_file(target, attr_name):
+    """Returns the bitstream file of a target.
+
+    Args:
+      target: A target providing VivadoBitstreamProvider, or a single file.
+      attr_name: The attribute name, for error messages.
+
+    Returns:
+      The bitstream File.
+    """
+    if VivadoBitstreamProvider in target:
+        return target[VivadoBitstreamProvider].bitstream
+    files = target.files.to_list()
+    if len(files) != 1:
+        fail("{}: expected exactly one bitstream file, got: {}".format(
+            attr_name, files))
+    return files[0]
+
+def _vivado_bitstream_diff_test_impl(ctx):
+    """Implementation for the vivado_bitstream_diff_test rule.
+
+    Args:
+      ctx: The rule context.
+
+    Returns:
+      A list of providers.
+    """
+    bitfile = _bitstream_file(ctx.attr.bitstream, "bitstream")
+    reference = _bitstream_file(ctx.attr.reference, "reference")
+    bitdiff = ctx.executable._bitdiff
+
+    args = []
+    if ctx.attr.part:
+        args += ["--part", ctx.attr.part]
+    if ctx.attr.frame_words:
+        args += ["--frame-words", str(ctx.attr.frame_words)]
+    args += ["--max-frames", str(ctx.attr.max_frames)]
+
+    executable = ctx.actions.declare_file(ctx.label.name + ".sh")
+    ctx.actions.expand_template(
+        template = ctx.file._test_template,
+        output = executable,
+        substitutions = {
+            "{{BITDIFF_RLOCATION}}": _get_rlocation(bitdiff, ctx),
+            "{{BITFILE_RLOCATION}}": _get_rlocation(bitfile, ctx),
+            "{{REFERENCE_RLOCATION}}": _get_rlocation(reference, ctx),
+            "{{ARGS}}": " ".join(args),
+        },
+        is_executable = True,
+    )
+
+    return [
+        DefaultInfo(
+            executable = executable,
+            runfiles = ctx.runfiles(files = [bitfile, reference, bitdiff])
+                .merge(ctx.attr._bash_runfiles[DefaultInfo].default_runfiles)
+                .merge(ctx.attr._bitdiff[DefaultInfo].default_runfiles),
+        ),
+    ]
+
+vivado_bitstream_diff_test = rule(
+    implementation = _vivado_bitstream_diff_test_impl,
+    test = True,
+    doc = "Checks that two bitstreams configure the same frames and " +
+          "registers. The test fails and lists the differing frames by " +
+          "frame address (FAR) if they do not.",
+    attrs = {
+        "bitstream": attr.label(
+            mandatory = True,
+            allow_files = [".bit", ".bin"],
+            doc = "The bitstream to check: a target providing " +
+                  "VivadoBitstreamProvider, or a .bit or .bin file.",
+        ),
+        "reference": attr.label(
+            mandatory = True,
+            allow_files = [".bit", ".bin"],
+            doc = "The bitstream to compare against: a target providing " +
+                  "VivadoBitstreamProvider, or a .bit or .bin file.",
+        ),
+        "part": attr.string(
+            doc = "The device part, used to find the frame size. If not " +
+                  "set, the part is taken from the .bit header.",
+        ),
+        "frame_words": attr.int(
+            doc = "If set, overrides the number of words per configuration " +
+                  "frame derived from the part.",
+        ),
+        "max_frames": attr.int(
+            default = 50,
+            doc = "The maximum number of differing frames to list, 0 for all.",
+        ),
+        "_bitdiff": attr.label(
+            default = Label("//build/vivado/bin/bitdiff"),
+            executable = True,
+            cfg = "target",
+            doc = "The bitstream comparison tool.",
+        ),
+        "_bash_runfiles": attr.label(
+            default = "@bazel_tools//tools/bash/runfiles",
+        ),
+        "_test_template": attr.label(
+            default = "//internal:vivado_bitstream_diff_test.sh.tpl",
+            allow_single_file = True,
+        ),
+    },
+)
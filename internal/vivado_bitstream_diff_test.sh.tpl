@@ -0,0 +1,48 @@
+#!/usr/bin/env bash
+
+# --- begin runfiles.bash initialization ---
+# Copy-pasted from Bazel's Bash runfiles library (tools/bash/runfiles/runfiles.bash).
+if [[ ! -d "${RUNFILES_DIR:-/dev/null}" && ! -f "${RUNFILES_MANIFEST_FILE:-/dev/null}" ]]; then
+  if [[ -f "$0.runfiles_manifest" ]]; then
+    export RUNFILES_MANIFEST_FILE="$0.runfiles_manifest"
+  elif [[ -f "$0.runfiles/MANIFEST" ]]; then
+    export RUNFILES_MANIFEST_FILE="$0.runfiles/MANIFEST"
+  elif [[ -f "$0.runfiles/bazel_tools/tools/bash/runfiles/runfiles.bash" ]]; then
+    export RUNFILES_DIR="$0.runfiles"
+  fi
+fi
+if [[ -f "${RUNFILES_DIR:-/dev/null}/bazel_tools/tools/bash/runfiles/runfiles.bash" ]]; then
+  source "${RUNFILES_DIR}/bazel_tools/tools/bash/runfiles/runfiles.bash"
+elif [[ -f "${RUNFILES_MANIFEST_FILE:-/dev/null}" ]]; then
+  source "$(grep -m1 "^bazel_tools/tools/bash/runfiles/runfiles.bash " \
+            "${RUNFILES_MANIFEST_FILE}" | cut -d ' ' -f 2-)"
+else
+  echo >&2 "ERROR: cannot find @bazel_tools//tools/bash/runfiles:runfiles.bash"
+  exit 1
+fi
+# --- end runfiles.bash initialization ---
+
+set -eo pipefail
+
+BITDIFF=$(rlocation {{BITDIFF_RLOCATION}})
+if [[ ! -f "${BITDIFF}" ]]; then
+  echo >&2 "ERROR: cannot find bitdiff at ${BITDIFF}"
+  exit 1
+fi
+
+BITFILE=$(rlocation {{BITFILE_RLOCATION}})
+if [[ ! -f "${BITFILE}" ]]; then
+  echo >&2 "ERROR: cannot find bitstream at ${BITFILE}"
+  exit 1
+fi
+
+REFERENCE=$(rlocation {{REFERENCE_RLOCATION}})
+if [[ ! -f "${REFERENCE}" ]]; then
+  echo >&2 "ERROR: cannot find reference bitstream at ${REFERENCE}"
+  exit 1
+fi
+
+"${BITDIFF}" \
+    --bitfile "${BITFILE}" \
+    --reference "${REFERENCE}" \
+    {{ARGS}}
@@ -0,0 +1,339 @@
+"""Rule to write a configuration memory (flash) image from a bitstream.
+
+`vivado_cfgmem` runs Vivado's `write_cfgmem` to turn a bitstream into an
+`.mcs` or `.bin` image for the board's configuration flash. The image can carry
+data files, such as a soft CPU firmware, at fixed offsets next to the
+bitstream. The layout is checked at build time: every region must fit into the
+flash and no two regions may overlap.
+
+For field updates, a MultiBoot `layout` puts a golden image and an update
+image at fixed offsets. `vivado_multiboot_constraints` writes the bitstream
+properties each image must be built with, so that the golden image jumps to
+the update image and falls back if it fails to load.
+
+The action needs Vivado but no hardware, so it is a normal, cacheable build
+step. `vivado_program_flash` can program the resulting image, or build it
+itself from a bitstream target.
+"""
+
+load("//internal:providers.bzl",
+    "VivadoBitstreamProvider",
+    "VivadoCfgmemProvider",
+)
+load("//internal:defines.bzl",
+    "VIVADO_CONFIG_ATTRS",
+    _script_cmd = "script_cmd",
+    _vivado_config = "vivado_config",
+)
+
+CFGMEM_INTERFACES = ["SPIx1", "SPIx2", "SPIx4", "SPIx8", "BPIx8", "BPIx16"]
+
+def write_cfgmem(ctx, config, name, bitfile, format, size, interface,
+                 load_address = "0x0", data = {}, layout = None,
+                 layout_images = {}):
+    """Declares the actions that write a flash image with write_cfgmem.
+
+    The rule must have the `_script` and `_cfgmemgen` attributes.
+
+    Args:
+      ctx: The rule context.
+      config: The Vivado configuration, from vivado_config.
+      name: The base name of the outputs.
+      bitfile: The bitstream File to load, or None with a layout.
+      format: The image format, "mcs" or "bin".
+      size: The flash size in megabytes, or 0 to take it from the layout.
+      interface: The flash interface, e.g. "SPIx4".
+      load_address: The address to load the bitstream at.
+      data: A dict of data File to the address to load it at.
+      layout: The MultiBoot flash layout File, or None.
+      layout_images: A dict of the name of a layout image to the bitstream
+        File to load into it.
+
+    Returns:
+      The list of flash image Files. Dual QSPI (SPIx8) images come in two
+      halves, primary first.
+    """
+    if interface == "SPIx8":
+        # write_cfgmem splits the image in two and suffixes the file names.
+        images = [
+            ctx.actions.declare_file("{}_primary.{}".format(name, format)),
+            ctx.actions.declare_file("{}_secondary.{}".format(name, format)),
+        ]
+        out_file_path = "{}/{}.{}".format(images[0].dirname, name, format)
+    else:
+        images = [ctx.actions.declare_file("{}.{}".format(name, format))]
+        out_file_path = images[0].path
+
+    cfgmem_tcl = ctx.actions.declare_file("{}.cfgmem.tcl".format(name))
+    args = ctx.actions.args()
+    args.add("--out-tcl", cfgmem_tcl.path)
+    args.add("--out-file", out_file_path)
+    args.add("--format", format)
+    args.add("--interface", interface)
+    if size:
+        args.add("--size", str(size))
+    inputs = data.keys()
+    if layout:
+        args.add("--layout", layout.path)
+        for (image, f) in layout_images.items():
+            args.add("--image", "{}={}".format(image, f.path))
+        inputs = inputs + [layout] + layout_images.values()
+    else:
+        args.add("--bitfile", bitfile.path)
+        args.add("--load-address", load_address)
+        inputs = inputs + [bitfile]
+    for (f, address) in data.items():
+        args.add("--data", "{}={}".format(address, f.path))
+
+    ctx.actions.run(
+        inputs = inputs,
+        outputs = [cfgmem_tcl],
+        executable = ctx.executable._cfgmemgen,
+        arguments = [args],
+        mnemonic = "CFGMEMGEN",
+        progress_message = "Generating write_cfgmem script: {}".format(cfgmem_tcl.path),
+    )
+
+    docker_run = ctx.executable._script
+    cache_dir = ctx.actions.declare_directory("_vivado_cfgmem.cache.{}".format(name))
+
+    # write_cfgmem runs inside the container whose working directory is the
+    # exec root, so exec-root-relative paths resolve.
+    script = _script_cmd(
+        docker_run.path,
+        images[0].path,
+        cache_dir.path,
+        freeargs = ["--net=host", "-e", "HOME=/work"],
+        container = config.container,
+    )
+
+    ctx.actions.run_shell(
+        progress_message = "Vivado write_cfgmem \"{}\" ({} {})".format(
+            name, format, interface),
+        inputs = [docker_run, cfgmem_tcl] + inputs,
+        outputs = images + [cache_dir],
+        tools = [docker_run],
+        mnemonic = "VivadoCfgmem",
+        command = (
+            "mkdir -p \"$(dirname {image})\" && " +
+            "{script} " +
+            "LD_LIBRARY_PATH=\"{vivado_path}/lib/lnx64.o\" " +
+            "{vivado_path}/bin/setEnvAndRunCmd.sh vivado " +
+            "-notrace -mode batch -source {tcl} 1>&2"
+        ).format(
+            image = images[0].path,
+            script = script,
+            vivado_path = config.vivado_path,
+            tcl = cfgmem_tcl.path,
+        ),
+    )
+    return images
+
+def _data_files(ctx):
+    """Returns the data files of the rule as a dict of File to address."""
+    data = {}
+    for (target, address) in ctx.attr.data.items():
+        files = target.files.to_list()
+        if len(files) != 1:
+            fail("data: expected exactly one file in {}, got: {}".format(
+                target.label, files))
+        data[files[0]] = address
+    return data
+
+def _vivado_cfgmem_impl(ctx):
+    """Implementation for the vivado_cfgmem rule.
+
+    Args:
+      ctx: The rule context.
+
+    Returns:
+      A list of providers with DefaultInfo carrying the flash image.
+    """
+    config = _vivado_config(ctx)
+    layout = ctx.file.layout
+    if bool(layout) == bool(ctx.attr.bitstream):
+        fail("vivado_cfgmem: exactly one of `bitstream` or `layout` is required.")
+
+    bitfile = None
+    layout_images = {}
+    if layout:
+        if not ctx.attr.images:
+            fail("vivado_cfgmem: `images` is required with `layout`.")
+        for (image, target) in ctx.attr.images.items():
+            layout_images[image] = target[VivadoBitstreamProvider].bitstream
+    else:
+        if not ctx.attr.size:
+            fail("vivado_cfgmem: `size` is required with `bitstream`.")
+        bitfile = ctx.attr.bitstream[VivadoBitstreamProvider].bitstream
+
+    images = write_cfgmem(
+        ctx,
+        config,
+        ctx.attr.name,
+        bitfile,
+        format = ctx.attr.format,
+        size = ctx.attr.size,
+        interface = ctx.attr.interface,
+        load_address = ctx.attr.load_address,
+        data = _data_files(ctx),
+        layout = layout,
+        layout_images = layout_images,
+    )
+
+    # Each layout image also gets a flash image of its own, so that it can be
+    # programmed without touching the other images, e.g. for field updates.
+    image_files = {}
+    if layout and ctx.attr.interface != "SPIx8":
+        for (image, f) in layout_images.items():
+            image_files[image] = write_cfgmem(
+                ctx,
+                config,
+                "{}.{}".format(ctx.attr.name, image),
+                None,
+                format = ctx.attr.format,
+                size = ctx.attr.size,
+                interface = ctx.attr.interface,
+                layout = layout,
+                layout_images = {image: f},
+            )[0]
+
+    return [
+        DefaultInfo(files = depset(images)),
+        OutputGroupInfo(
+            layout_images = depset(image_files.values()),
+        ),
+        VivadoCfgmemProvider(
+            image = images[0],
+            images = images,
+            format = ctx.attr.format,
+            interface = ctx.attr.interface,
+            size = ctx.attr.size,
+            layout = layout,
+            layout_images = image_files,
+        ),
+    ]
+
+def _vivado_multiboot_constraints_impl(ctx):
+    """Implementation for the vivado_multiboot_constraints rule.
+
+    Args:
+      ctx: The rule context.
+
+    Returns:
+      A list of providers with DefaultInfo carrying the constraints file.
+    """
+    xdc = ctx.actions.declare_file("{}.xdc".format(ctx.attr.name))
+    args = ctx.actions.args()
+    args.add("--layout", ctx.file.layout.path)
+    args.add("--properties-for", ctx.attr.image)
+    args.add("--out-properties", xdc.path)
+
+    ctx.actions.run(
+        inputs = [ctx.file.layout],
+        outputs = [xdc],
+        executable = ctx.executable._cfgmemgen,
+        arguments = [args],
+        mnemonic = "CFGMEMGEN",
+        progress_message = "Generating MultiBoot constraints: {}".format(xdc.path),
+    )
+    return [DefaultInfo(files = depset([xdc]))]
+
+CFGMEM_ATTRS = {
+    "_script": attr.label(
+        default = "@rules_bid//build:docker_run",
+        executable = True,
+        cfg = "host",
+        doc = "The docker run script.",
+    ),
+    "_cfgmemgen": attr.label(
+        default = Label("//build/vivado/bin/cfgmemgen"),
+        executable = True,
+        cfg = "host",
+        doc = "The program that generates the write_cfgmem script.",
+    ),
+}
+
+vivado_cfgmem = rule(
+    implementation = _vivado_cfgmem_impl,
+    doc = "Writes a configuration memory (flash) image (.mcs/.bin) from a " +
+          "bitstream with `write_cfgmem`, optionally with data files at " +
+          "fixed offsets. With a MultiBoot `layout`, the image holds a " +
+          "bitstream per layout image, and each layout image is also " +
+          "written on its own, in the `layout_images` output group.",
+    attrs = VIVADO_CONFIG_ATTRS | CFGMEM_ATTRS | {
+        "bitstream": attr.label(
+            providers = [VivadoBitstreamProvider],
+            doc = "The target providing the bitstream to write into the " +
+                  "image. Either this or `layout` is required.",
+        ),
+        "layout": attr.label(
+            allow_single_file = [".json"],
+            doc = "The MultiBoot flash layout (.json): images, their " +
+                  "addresses, the flash size and sector size. The layout is " +
+                  "checked for overlaps and sector alignment. See " +
+                  "`build/vivado/lib/flashlayout` for the format.",
+        ),
+        "images": attr.string_keyed_label_dict(
+            providers = [VivadoBitstreamProvider],
+            doc = "With `layout`, the bitstream to write into each layout " +
+                  "image, keyed by the image name. The golden and update " +
+                  "images must be built separately, see " +
+                  "`vivado_multiboot_constraints`.",
+        ),
+        "size": attr.int(
+            doc = "The flash capacity in megabytes (MB), passed to " +
+                  "`write_cfgmem -size`. Required with `bitstream`; taken " +
+                  "from the layout otherwise.",
+        ),
+        "interface": attr.string(
+            default = "SPIx4",
+            values = CFGMEM_INTERFACES,
+            doc = "The flash interface. SPIx8 (dual QSPI) images are " +
+                  "written as two files, `<name>_primary` and " +
+                  "`<name>_secondary`. Must match the interface of the " +
+                  "layout, if any.",
+        ),
+        "format": attr.string(
+            default = "mcs",
+            values = ["mcs", "bin"],
+            doc = "The flash image format.",
+        ),
+        "load_address": attr.string(
+            default = "0x0",
+            doc = "The flash address to load the bitstream at. Not used " +
+                  "with `layout`.",
+        ),
+        "data": attr.label_keyed_string_dict(
+            allow_files = True,
+            doc = "Data files to load into the image, keyed by label, with " +
+                  "the flash address to load each at, e.g. " +
+                  "`{\":firmware\": \"0x00400000\"}`.",
+        ),
+    },
+)
+
+vivado_multiboot_constraints = rule(
+    implementation = _vivado_multiboot_constraints_impl,
+    doc = "Writes the constraints (.xdc) that set the bitstream properties " +
+          "an image of a MultiBoot flash layout must be built with: " +
+          "`BITSTREAM.CONFIG.NEXT_CONFIG_ADDR` for the golden image, " +
+          "`BITSTREAM.CONFIG.CONFIGFALLBACK` and the SPI settings. Add the " +
+          "output to the `xdcs` of the image's synthesis target.",
+    attrs = {
+        "layout": attr.label(
+            mandatory = True,
+            allow_single_file = [".json"],
+            doc = "The MultiBoot flash layout.",
+        ),
+        "image": attr.string(
+            mandatory = True,
+            doc = "The name of the layout image to write the constraints for.",
+        ),
+        "_cfgmemgen": attr.label(
+            default = Label("//build/vivado/bin/cfgmemgen"),
+            executable = True,
+            cfg = "host",
+            doc = "The program that generates the constraints.",
+        ),
+    },
+)
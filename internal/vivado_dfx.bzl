@@ -0,0 +1,269 @@
+"""Rule for dynamic function exchange (DFX) designs.
+
+`vivado_dfx` implements a static design with reconfigurable partitions, and
+writes a full bitstream per configuration and a partial bitstream per
+reconfigurable module. It supports 7-series and UltraScale+ parts.
+
+The design is built from:
+
+1. The static design, synthesized with `vivado_synthesis2`, where each
+   partition is a black box: list one reconfigurable module of each
+   partition in its `black_boxes`.
+2. The reconfigurable modules, each synthesized with `vivado_synthesis2` and
+   `out_of_context = True`. All modules of a partition have the same top.
+3. Constraints with a pblock per partition, which pins the partition to its
+   region of the device.
+
+Example:
+
+```
+vivado_synthesis2(
+    name = "rm_blink",
+    top = "rp",
+    srcs = ["blink.vhdl"],
+    part = PART,
+    out_of_context = True,
+)
+
+vivado_synthesis2(
+    name = "rm_count",
+    top = "rp",
+    srcs = ["count.vhdl"],
+    part = PART,
+    out_of_context = True,
+)
+
+vivado_synthesis2(
+    name = "static",
+    top = "top",
+    srcs = ["top.vhdl"],
+    part = PART,
+    black_boxes = [":rm_blink"],
+)
+
+vivado_dfx(
+    name = "dfx",
+    synthesis = ":static",
+    modules = {
+        ":rm_blink": "u_rp",
+        ":rm_count": "u_rp",
+    },
+    xdcs = ["pins.xdc", "floorplan.xdc"],
+)
+```
+
+The configurations are made from the modules in the order they are listed:
+the first configuration has the first module of each partition, the second
+the second module, and so on. A partition that runs out of modules is left
+as a black box in the later configurations. The first configuration is
+implemented in full; its static design is then locked, and the other
+configurations are implemented on top of it. `pr_verify` checks that all
+configurations share the same static design, so that any partial bitstream
+can be loaded over any full bitstream.
+
+The rule provides `VivadoBitstreamProvider` with the full bitstream of the
+first configuration, and `VivadoDfxProvider` with all bitstreams. Program a
+partial bitstream onto a configured device with the `partial` attribute of
+`vivado_program_device`.
+"""
+
+load("//internal:defines.bzl",
+    "DOCKER_RUN_SCRIPT_ATTRS",
+    "VIVADO_CONFIG_ATTRS",
+    _vivado_config = "vivado_config",
+)
+load("//internal:providers.bzl",
+    "VivadoBitstreamProvider",
+    "VivadoDfxProvider",
+    "VivadoOocProvider",
+    "VivadoSynthProvider",
+)
+load("//internal:vivado_place_and_route2.bzl",
+    _pnr_stage = "pnr_stage",
+)
+
+def _vivado_dfx_impl(ctx):
+    """Implementation for the vivado_dfx rule.
+
+    Args:
+      ctx: The rule context.
+
+    Returns:
+      A list of providers with DefaultInfo, VivadoBitstreamProvider and
+      VivadoDfxProvider.
+    """
+    config = _vivado_config(ctx)
+    name = ctx.attr.name
+    synthesis = ctx.attr.synthesis[VivadoSynthProvider]
+    part = getattr(synthesis, "part", None)
+    if not part:
+        fail("synthesis: {} has no part, use vivado_synthesis2.".format(
+            ctx.attr.synthesis.label))
+
+    # The reconfigurable modules of each partition, in the order given.
+    partitions = {}
+    for (target, cell) in ctx.attr.modules.items():
+        if not cell or len(cell.split()) != 1:
+            fail("modules: want a single cell for {}, got: {}".format(
+                target.label, repr(cell)))
+        ooc = target[VivadoOocProvider]
+        if ooc.part != part:
+            fail("modules: {} is synthesized for part {}, want {}".format(
+                target.label, ooc.part, part))
+        partitions.setdefault(cell, []).append(target)
+    num_configs = max([len(v) for v in partitions.values()])
+
+    xdc_files = []
+    for target in ctx.attr.xdcs:
+        xdc_files += target.files.to_list()
+
+    static_dcp_file = ctx.actions.declare_file("{}.static.dcp".format(name))
+    full_bitstreams = []
+    partial_bitstreams = {}
+    routed_dcps = []
+    reports = []
+    logs = []
+    for i in range(num_configs):
+        stage = "config{}".format(i)
+        dcp_file = ctx.actions.declare_file("{}.{}.dcp".format(name, stage))
+        bit_file = ctx.actions.declare_file("{}.{}.bit".format(name, stage))
+        timing_summary_file = ctx.actions.declare_file(
+            "{}.timing_summary.{}.rpt".format(name, stage))
+        outputs = [dcp_file, bit_file, timing_summary_file]
+        inputs = []
+
+        args = ctx.actions.args()
+        args.add("--part", part)
+        args.add("--save-dcp", dcp_file.path)
+        args.add("--bitstream", bit_file.path)
+        args.add("--timing-report", timing_summary_file.path)
+        args.add("--opt-design-options", ctx.attr.opt_design_options)
+        args.add("--place-design-options", ctx.attr.place_design_options)
+        args.add("--route-design-options", ctx.attr.route_design_options)
+        for (cell, modules) in partitions.items():
+            if i >= len(modules):
+                args.add("--dfx-cell", "{}=".format(cell))
+                continue
+            module = modules[i]
+            ooc = module[VivadoOocProvider]
+            # Modules of the same name may come from different packages.
+            label = module.label
+            module_path = "/".join([
+                p for p in [label.repo_name, label.package, label.name] if p])
+            partial_file = ctx.actions.declare_file(
+                "{}.partial/{}.bit".format(name, module_path))
+            partial_bitstreams[label] = partial_file
+            outputs.append(partial_file)
+            inputs.append(ooc.dcp)
+            args.add("--dfx-cell", "{}={}={}".format(
+                cell, ooc.dcp.path, partial_file.path))
+
+        if i == 0:
+            # The first configuration defines the partitions and their
+            # floorplan, and locks the static design for the others.
+            load_dcp = synthesis.synth_dcp_file
+            inputs += xdc_files
+            args.add_all([f.path for f in xdc_files], before_each = "--constraints")
+            args.add("--dfx-static-dcp", static_dcp_file.path)
+            outputs.append(static_dcp_file)
+        else:
+            load_dcp = static_dcp_file
+
+        logs.append(_pnr_stage(ctx, config, stage,
+            ctx.file._dfx_template, load_dcp, inputs, outputs, args))
+        full_bitstreams.append(bit_file)
+        routed_dcps.append(dcp_file)
+        reports.append(timing_summary_file)
+
+    # pr_verify needs at least two configurations to compare.
+    if num_configs > 1:
+        pr_verify_file = ctx.actions.declare_file("{}.pr_verify.rpt".format(name))
+        args = ctx.actions.args()
+        args.add_all([f.path for f in routed_dcps[1:]], before_each = "--pr-verify-dcp")
+        args.add("--pr-verify-report", pr_verify_file.path)
+        logs.append(_pnr_stage(ctx, config, "pr_verify",
+            ctx.file._pr_verify_template, routed_dcps[0], routed_dcps[1:],
+            [pr_verify_file], args))
+        reports.append(pr_verify_file)
+
+    return [
+        DefaultInfo(files = depset(
+            full_bitstreams + partial_bitstreams.values() + reports)),
+        OutputGroupInfo(
+            configuration_checkpoints = depset(routed_dcps),
+            static_checkpoint = depset([static_dcp_file]),
+            stage_logs = depset(logs),
+        ),
+        VivadoBitstreamProvider(
+            bitstream = full_bitstreams[0],
+            probes = None,
+        ),
+        VivadoDfxProvider(
+            full_bitstreams = full_bitstreams,
+            partial_bitstreams = partial_bitstreams,
+            static_dcp = static_dcp_file,
+            part = part,
+        ),
+    ]
+
+vivado_dfx = rule(
+    implementation = _vivado_dfx_impl,
+    doc = "Implements a dynamic function exchange (DFX) design, and writes " +
+          "a full bitstream per configuration and a partial bitstream per " +
+          "reconfigurable module. Supports 7-series and UltraScale+ parts.",
+    attrs = DOCKER_RUN_SCRIPT_ATTRS | VIVADO_CONFIG_ATTRS | {
+        "synthesis": attr.label(
+            mandatory = True,
+            providers = [VivadoSynthProvider],
+            doc = "The static design, synthesized with `vivado_synthesis2`, " +
+                  "with the partitions as black boxes.",
+        ),
+        "modules": attr.label_keyed_string_dict(
+            mandatory = True,
+            providers = [VivadoOocProvider],
+            doc = "The reconfigurable modules, keyed by their out-of-context " +
+                  "synthesis target, with the instance path of the partition " +
+                  "that each goes into, e.g. `{\":rm_blink\": \"u_rp\"}`. " +
+                  "The partial bitstream of a module is written to " +
+                  "`<name>.partial/<package>/<target>.bit`.",
+        ),
+        "xdcs": attr.label_list(
+            allow_files = True,
+            doc = "Constraint files, with a pblock for each partition.",
+        ),
+        "opt_design_options": attr.string(
+            default = "",
+            doc = "Additional options to pass to the `opt_design` command in Vivado",
+        ),
+        "place_design_options": attr.string(
+            default = "",
+            doc = "Additional options to pass to the `place_design` command in Vivado",
+        ),
+        "route_design_options": attr.string(
+            default = "",
+            doc = "Additional options to pass to the `route_design` command in Vivado",
+        ),
+        "_generator": attr.label(
+            doc = "xprgen binary",
+            default = Label("//build/vivado/bin/xprgen"),
+            executable = True,
+            cfg = "host",
+        ),
+        "_pnr": attr.label(
+            doc = "pnr binary",
+            default = Label("//build/vivado:pnr"),
+            executable = True,
+            cfg = "host",
+        ),
+        "_dfx_template": attr.label(
+            doc = "DFX configuration template",
+            default = Label("//build/vivado:dfx_batch_tcl_template"),
+            allow_single_file = True,
+        ),
+        "_pr_verify_template": attr.label(
+            doc = "pr_verify template",
+            default = Label("//build/vivado:pr_verify_batch_tcl_template"),
+            allow_single_file = True,
+        ),
+    },
+)
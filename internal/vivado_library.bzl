@@ -8,6 +8,7 @@ load("//internal:defines.bzl",
 )
 load("//internal:providers.bzl",
     "VivadoLibraryProvider",
+    "VivadoNetlistProvider",
 )
 
 def _vivado_library_impl(ctx):
@@ -33,6 +34,26 @@ def _vivado_library_impl(ctx):
     for target in srcs_targets:
         files += [file for file in target.files.to_list()]
 
+    # A simulation netlist of a built design is compiled like a source. It
+    # instantiates the simulation primitives, so `deps` must hold them.
+    netlist = None
+    if ctx.attr.netlist:
+        provider = ctx.attr.netlist[VivadoNetlistProvider]
+        netlist_file = getattr(provider, ctx.attr.netlist_mode)
+        if not netlist_file:
+            fail("netlist: {} has no {} netlist, build it with `{}_netlist` set".format(
+                ctx.attr.netlist.label, ctx.attr.netlist_mode, ctx.attr.netlist_mode))
+        files += [netlist_file]
+        sdf = None
+        if ctx.attr.netlist_mode == "timesim":
+            sdf = provider.sdf
+            inputs += [sdf]
+        netlist = struct(
+            mode = ctx.attr.netlist_mode,
+            sdf = sdf,
+            glbl = netlist_file.extension == "v",
+        )
+
     provider_direct_list = []
     provider_transitive_depsets = []
 
@@ -207,8 +228,8 @@ def _vivado_library_impl(ctx):
     for file in files:
         args += [file.path]
 
-    # Special Vivado sauce.
-    if ctx.attr.use_glbl:
+    # Special Vivado sauce. Verilog netlists need it too.
+    if ctx.attr.use_glbl or (netlist and netlist.glbl):
         command = "xvlog"
         args = ["{}/data/verilog/src/glbl.v".format(config.vivado_path)] + args
 
@@ -253,6 +274,7 @@ def _vivado_library_impl(ctx):
         deps_names=deps_names,
         library_dir=library_output_dir,
         unisims_libs=False,
+        netlist=netlist,
     )
 
     return [
@@ -290,6 +312,20 @@ vivado_library = rule(
             doc = """An optional library name, in the case the target name
                      can not be used for some reason."""
         ),
+        "netlist": attr.label(
+            providers = [VivadoNetlistProvider],
+            doc = "A synthesis or place and route target whose simulation " +
+                  "netlist to compile into this library, for gate-level " +
+                  "simulation of exactly what was built. `deps` must hold " +
+                  "the simulation primitives, from `vivado_unisims_library`.",
+        ),
+        "netlist_mode": attr.string(
+            default = "funcsim",
+            values = ["funcsim", "timesim"],
+            doc = "The netlist to compile: the functional netlist, or the " +
+                  "timing netlist, which `vivado_test` elaborates with its " +
+                  "SDF delays.",
+        ),
         "use_glbl": attr.bool(
             default=False,
             doc = "Whether to use the global glbl.v.",
@@ -2,87 +2,77 @@
 
 load("//internal:defines.bzl",
     "DOCKER_RUN_SCRIPT_ATTRS",
+    "CDC_ATTRS",
+    "METHODOLOGY_ATTRS",
+    "METRICS_ATTRS",
+    "SIM_NETLIST_ATTRS",
     "VIVADO_CONFIG_ATTRS",
+    _cdc_check = "cdc_check",
+    _cdc_reports = "cdc_reports",
+    _hook_attrs = "hook_attrs",
+    _log_metrics = "log_metrics",
+    _methodology_check = "methodology_check",
+    _methodology_reports = "methodology_reports",
     _script_cmd = "script_cmd",
+    _sim_netlists = "sim_netlists",
+    _stage_hooks = "stage_hooks",
     _vivado_config = "vivado_config",
 )
 load("//internal:providers.bzl",
     "VivadoSynthProvider",
     "VivadoBitstreamProvider",
+    "VivadoReportsProvider",
 )
 
-def _vivado_place_and_route2_impl(ctx):
-    """Implementation for the vivado_place_and_route2 rule.
+def pnr_stage(ctx, config, stage, template, load_dcp, inputs, outputs, args):
+    """Declares the xprgen and Vivado actions for one implementation stage.
+
+    Each stage runs in its own Vivado invocation, which opens the checkpoint
+    of the previous stage and writes its own outputs. This way Bazel can
+    cache every prefix of the implementation flow separately.
+
+    The rule must have the `DOCKER_RUN_SCRIPT_ATTRS`, and the `_generator`
+    (xprgen) and `_pnr` attributes of `vivado_place_and_route2`.
 
     Args:
       ctx: The rule context.
+      config: The resolved Vivado configuration.
+      stage: The stage name, e.g. "opt", used to name the stage files.
+      template: The TCL batch template file for this stage.
+      load_dcp: The checkpoint file to load at the start of the stage.
+      inputs: Additional inputs that the stage reads.
+      outputs: The outputs the stage produces.
+      args: The stage-specific xprgen arguments.
 
     Returns:
-      A list of providers, including DefaultInfo and VivadoBitstreamProvider.
+      The log file of the stage.
     """
-    config = _vivado_config(ctx)
-    args = ctx.actions.args()
     name = ctx.attr.name
     generator = ctx.attr._generator.files
     generator_path = generator.to_list()[0]
-    inputs = []
-    outputs = []
-
-    template_file = ctx.attr._batch_template.files.to_list()[0]
-    inputs += [template_file]
-
-    tcl_file = ctx.actions.declare_file("{}.pnr.tcl".format(name))
-    outputs += [tcl_file]
 
-    input_dcp_file = ctx.attr.synthesis[VivadoSynthProvider].synth_dcp_file
-
-    output_dcp_file = ctx.actions.declare_file("{}.pnr.dcp".format(name))
-    outputs += [output_dcp_file]
-    drc_report_file = ctx.actions.declare_file("{}.drc.rpt".format(name))
-
-    timing_summary_file = ctx.actions.declare_file("{}.timing_summary.pnr.rpt".format(name))
-    outputs += [timing_summary_file]
-    utilization_file = ctx.actions.declare_file("{}.utilization.pnr.rpt".format(name))
-    outputs += [utilization_file]
-    bit_file = ctx.actions.declare_file("{}.bit".format(name))
-    probes_file = ctx.actions.declare_file("{}.ltx".format(name))
-    outputs += [probes_file]
-
-    xdc_files = []
-    xdc_files_paths = []
-    for target in ctx.attr.xdcs:
-        xdc_files_paths += [ file.path for file in target.files.to_list() ]
-        xdc_files += target.files.to_list()
-    inputs += xdc_files
+    output_dir = ctx.actions.declare_directory("_pnr.work.{}.{}".format(name, stage))
+    cache_dir = ctx.actions.declare_directory("_pnr.cache.{}.{}".format(name, stage))
 
+    tcl_file = ctx.actions.declare_file("{}.{}.tcl".format(name, stage))
     args.add("--custom-filename", tcl_file.path)
-    args.add("--custom-template", template_file.path)
-    args.add("--load-dcp", input_dcp_file.path)
-    args.add("--save-dcp", output_dcp_file.path)
-    args.add("--timing-report", timing_summary_file.path)
-    args.add("--utilization-report", utilization_file.path)
-    args.add("--drc-report", drc_report_file.path)
+    args.add("--custom-template", template.path)
+    args.add("--project-name", name)
     args.add("--top-name", name)
-    args.add("--bitstream", bit_file.path)
-    args.add("--probes-file", probes_file.path)
-    args.add_all(xdc_files_paths, before_each = "--constraints")
-    args.add("--place-design-options", ctx.attr.place_design_options)
-    args.add("--route-design-options", ctx.attr.route_design_options)
-    args.add_all(ctx.attr.post_place_design, before_each = "--post-place-design")
-    args.add_all(ctx.attr.post_route_design, before_each = "--post-route-design")
+    args.add("--load-dcp", load_dcp.path)
+    # Vivado starts in the exec root, and the script changes to the work dir.
+    args.add("--work-dir", output_dir.path)
 
     ctx.actions.run(
         outputs = [tcl_file],
-        inputs = inputs,
+        inputs = [template] + inputs,
         tools = [ generator ],
         executable = generator_path,
         arguments = [ args ],
-        progress_message = "Vivado PNR XPRGEN {}".format(name),
+        progress_message = "Vivado PNR XPRGEN {} ({})".format(name, stage),
         mnemonic = "XPRGEN",
     )
 
-    # PNR step here.
-
     # Prepare the docker mount.
     docker_run = ctx.executable._script
     env = ctx.attr.env
@@ -93,11 +83,6 @@ def _vivado_place_and_route2_impl(ctx):
       "/tmp/.X11-unix": "/tmp/.X11-unix:ro",
     })
 
-    output_dir_path = "_pnr.work.{}".format(name)
-    output_dir = ctx.actions.declare_directory(output_dir_path)
-    cache_dir_rpath = "_pnr.cache.{}".format(name)
-    cache_dir = ctx.actions.declare_directory(cache_dir_rpath)
-
     script = _script_cmd(
       docker_run.path,
       output_dir.path,
@@ -111,16 +96,14 @@ def _vivado_place_and_route2_impl(ctx):
       container=config.container,
     )
 
-    outputs = [output_dcp_file, drc_report_file, timing_summary_file, utilization_file, bit_file, probes_file]
-    inputs = [tcl_file, input_dcp_file] + xdc_files
-    logfile = ctx.actions.declare_file("{}.log".format(ctx.attr.name))
-    script_file = ctx.actions.declare_file("{}.script".format(ctx.attr.name))
+    logfile = ctx.actions.declare_file("{}.{}.log".format(name, stage))
+    script_file = ctx.actions.declare_file("{}.{}.script".format(name, stage))
     ctx.actions.write(script_file, content=script)
     pnr_binary = ctx.executable._pnr
 
     ctx.actions.run_shell(
-        progress_message = "Vivado Place and Route(2): {}".format(name),
-        inputs = inputs + [docker_run, script_file],
+        progress_message = "Vivado Place and Route(2) {}: {}".format(stage, name),
+        inputs = [tcl_file, load_dcp, docker_run, script_file] + inputs,
         outputs = outputs + [output_dir, cache_dir, logfile],
         tools = [docker_run, pnr_binary],
         mnemonic = "VPNR2",
@@ -141,25 +124,301 @@ def _vivado_place_and_route2_impl(ctx):
             name=logfile.path,
         ),
     )
-    return [
+    return logfile
+
+def _vivado_place_and_route2_impl(ctx):
+    """Implementation for the vivado_place_and_route2 rule.
+
+    The implementation is split into opt, place, the optional phys_opt, and
+    route stages, followed by a stage that writes the reports and the
+    bitstream. Each stage is a separate action that produces a checkpoint.
+
+    Args:
+      ctx: The rule context.
+
+    Returns:
+      A list of providers, including DefaultInfo and VivadoBitstreamProvider.
+    """
+    config = _vivado_config(ctx)
+    name = ctx.attr.name
+
+    synth_dcp_file = ctx.attr.synthesis[VivadoSynthProvider].synth_dcp_file
+
+    xdc_files = []
+    for target in ctx.attr.xdcs:
+        xdc_files += target.files.to_list()
+
+    # Stage: opt_design.
+    opt_dcp_file = ctx.actions.declare_file("{}.opt.dcp".format(name))
+    args = ctx.actions.args()
+    args.add("--save-dcp", opt_dcp_file.path)
+    args.add_all([f.path for f in xdc_files], before_each = "--constraints")
+    args.add("--opt-design-options", ctx.attr.opt_design_options)
+    args.add_all(ctx.attr.post_opt_design, before_each = "--post-opt-design")
+    opt_hooks = _stage_hooks(ctx, "opt", args)
+    opt_log = pnr_stage(ctx, config, "opt",
+        ctx.file._opt_template, synth_dcp_file, xdc_files + opt_hooks,
+        [opt_dcp_file], args)
+
+    # Stage: place_design.
+    place_dcp_file = ctx.actions.declare_file("{}.place.dcp".format(name))
+    place_timing_summary_file = ctx.actions.declare_file(
+        "{}.timing_summary.place.rpt".format(name))
+    args = ctx.actions.args()
+    args.add("--save-dcp", place_dcp_file.path)
+    args.add("--timing-report", place_timing_summary_file.path)
+    args.add("--place-design-options", ctx.attr.place_design_options)
+    args.add_all(ctx.attr.post_place_design, before_each = "--post-place-design")
+    place_hooks = _stage_hooks(ctx, "place", args)
+    place_log = pnr_stage(ctx, config, "place",
+        ctx.file._place_template, opt_dcp_file, place_hooks,
+        [place_dcp_file, place_timing_summary_file], args)
+    stage_dcps = [opt_dcp_file, place_dcp_file]
+    stage_logs = [opt_log, place_log]
+    stage_names = ["opt", "place"]
+
+    # Stage: phys_opt_design, only if requested.
+    pre_route_dcp_file = place_dcp_file
+    if ctx.attr.phys_opt_design:
+        phys_opt_dcp_file = ctx.actions.declare_file("{}.phys_opt.dcp".format(name))
+        args = ctx.actions.args()
+        args.add("--save-dcp", phys_opt_dcp_file.path)
+        args.add("--phys-opt-design-options", ctx.attr.phys_opt_design_options)
+        args.add_all(ctx.attr.post_phys_opt_design, before_each = "--post-phys-opt-design")
+        phys_opt_hooks = _stage_hooks(ctx, "phys_opt", args)
+        stage_logs += [pnr_stage(ctx, config, "phys_opt",
+            ctx.file._phys_opt_template, place_dcp_file, phys_opt_hooks,
+            [phys_opt_dcp_file], args)]
+        stage_names.append("phys_opt")
+        stage_dcps += [phys_opt_dcp_file]
+        pre_route_dcp_file = phys_opt_dcp_file
+    elif ctx.files.pre_phys_opt_hooks or ctx.files.post_phys_opt_hooks:
+        fail("vivado_place_and_route2: set phys_opt_design to use the " +
+             "phys_opt hooks")
+
+    # Stage: route_design.
+    output_dcp_file = ctx.actions.declare_file("{}.pnr.dcp".format(name))
+    args = ctx.actions.args()
+    args.add("--save-dcp", output_dcp_file.path)
+    args.add("--route-design-options", ctx.attr.route_design_options)
+    args.add_all(ctx.attr.post_route_design, before_each = "--post-route-design")
+    route_hooks = _stage_hooks(ctx, "route", args)
+    stage_logs += [pnr_stage(ctx, config, "route",
+        ctx.file._route_template, pre_route_dcp_file, route_hooks,
+        [output_dcp_file], args)]
+    stage_names.append("route")
+    stage_dcps += [output_dcp_file]
+
+    # Stage: reports and bitstream.
+    drc_report_file = ctx.actions.declare_file("{}.drc.rpt".format(name))
+    timing_summary_file = ctx.actions.declare_file("{}.timing_summary.pnr.rpt".format(name))
+    utilization_file = ctx.actions.declare_file("{}.utilization.pnr.rpt".format(name))
+    hier_utilization_file = ctx.actions.declare_file(
+        "{}.utilization_hier.pnr.rpt".format(name))
+    bit_file = ctx.actions.declare_file("{}.bit".format(name))
+    probes_file = ctx.actions.declare_file("{}.ltx".format(name))
+    args = ctx.actions.args()
+    args.add("--timing-report", timing_summary_file.path)
+    args.add("--utilization-report", utilization_file.path)
+    args.add("--hier-utilization-report", hier_utilization_file.path)
+    args.add("--drc-report", drc_report_file.path)
+    args.add("--bitstream", bit_file.path)
+    args.add("--probes-file", probes_file.path)
+    if ctx.attr.allow_dummy_outputs:
+        args.add("--allow-dummy-outputs")
+    part = getattr(ctx.attr.synthesis[VivadoSynthProvider], "part", None)
+    if part:
+        args.add("--part", part)
+    if ctx.attr.bitstream_compress:
+        args.add("--bitstream-compress")
+    if ctx.attr.config_voltage:
+        args.add("--config-voltage", ctx.attr.config_voltage)
+    if ctx.attr.cfgbvs:
+        args.add("--cfgbvs", ctx.attr.cfgbvs)
+    if ctx.attr.spi_buswidth:
+        args.add("--spi-buswidth", str(ctx.attr.spi_buswidth))
+    if ctx.attr.config_rate:
+        args.add("--config-rate", ctx.attr.config_rate)
+    if ctx.attr.userid:
+        args.add("--userid", ctx.attr.userid)
+    if ctx.attr.usr_access:
+        args.add("--usr-access", ctx.attr.usr_access)
+    outputs = [drc_report_file, timing_summary_file, utilization_file,
+               hier_utilization_file, bit_file, probes_file]
+    mem_info_file = None
+    if ctx.attr.write_mem_info:
+        mem_info_file = ctx.actions.declare_file("{}.mmi".format(name))
+        args.add("--mem-info-file", mem_info_file.path)
+        outputs.append(mem_info_file)
+    netlists = _sim_netlists(ctx, args)
+    netlist_files = []
+    if netlists:
+        netlist_files = [f for f in [netlists.funcsim, netlists.timesim, netlists.sdf] if f]
+        outputs += netlist_files
+    cdc = _cdc_reports(ctx, ".pnr", args)
+    if cdc:
+        outputs += cdc.reports
+    methodology = _methodology_reports(ctx, ".pnr", args)
+    if methodology:
+        outputs += methodology.reports
+    timing_paths_files = []
+    if ctx.attr.timing_paths:
+        timing_paths_file = ctx.actions.declare_file("{}.timing_paths.rpt".format(name))
+        args.add("--timing-paths-report", timing_paths_file.path)
+        args.add("--timing-max-paths", str(ctx.attr.timing_paths))
+        args.add("--timing-nworst", str(ctx.attr.timing_nworst))
+        outputs.append(timing_paths_file)
+        timing_paths_json_file = ctx.actions.declare_file("{}.timing_paths.json".format(name))
+        timing_paths_files = [timing_paths_file, timing_paths_json_file]
+    power_inputs = []
+    power_files = []
+    if ctx.attr.power_report:
+        power_report_file = ctx.actions.declare_file("{}.power.rpt".format(name))
+        args.add("--power-report", power_report_file.path)
+        outputs.append(power_report_file)
+        if ctx.attr.toggle_rate:
+            args.add("--toggle-rate", ctx.attr.toggle_rate)
+        if ctx.attr.static_probability:
+            args.add("--static-probability", ctx.attr.static_probability)
+        if ctx.file.saif:
+            power_inputs.append(ctx.file.saif)
+            args.add("--saif-file", ctx.file.saif.path)
+        if ctx.attr.saif_strip_path:
+            args.add("--saif-strip-path", ctx.attr.saif_strip_path)
+        power_json_file = ctx.actions.declare_file("{}.power.json".format(name))
+        power_files = [power_report_file, power_json_file]
+    elif (ctx.attr.toggle_rate or ctx.attr.static_probability or ctx.file.saif or
+          ctx.attr.saif_strip_path or ctx.attr.max_power or ctx.attr.max_junction_temp):
+        fail("vivado_place_and_route2: set power_report to use the power " +
+             "analysis attributes")
+    stamp_files = []
+    if ctx.attr.stamp:
+        if ctx.attr.userid or ctx.attr.usr_access:
+            fail("vivado_place_and_route2: stamp sets USERID and USR_ACCESS, " +
+                 "do not set userid or usr_access with it")
+        stamp_files = [ctx.info_file, ctx.version_file]
+        args.add_all(stamp_files, before_each = "--stamp-file")
+    bitstream_hooks = _stage_hooks(ctx, "bitstream", args)
+    logfile = pnr_stage(ctx, config, "bitstream",
+        ctx.file._batch_template, output_dcp_file,
+        stamp_files + power_inputs + bitstream_hooks,
+        outputs, args)
+
+    if power_files:
+        args = ctx.actions.args()
+        args.add("--report", power_report_file.path)
+        args.add("--out", power_json_file.path)
+        if ctx.attr.max_power:
+            args.add("--max-total-power", ctx.attr.max_power)
+        if ctx.attr.max_junction_temp:
+            args.add("--max-junction-temp", ctx.attr.max_junction_temp)
+        ctx.actions.run(
+            inputs = [power_report_file],
+            outputs = [power_json_file],
+            executable = ctx.executable._powerreport,
+            arguments = [args],
+            mnemonic = "POWERREPORT",
+            progress_message = "Checking power report: {}".format(power_report_file.path),
+        )
+
+    if timing_paths_files:
+        args = ctx.actions.args()
+        args.add("--report", timing_paths_file.path)
+        args.add("--out", timing_paths_json_file.path)
+        ctx.actions.run(
+            inputs = [timing_paths_file],
+            outputs = [timing_paths_json_file],
+            executable = ctx.executable._timingreport,
+            arguments = [args],
+            mnemonic = "TIMINGREPORT",
+            progress_message = "Converting timing paths: {}".format(timing_paths_file.path),
+        )
+
+    # The dashboard of the reports and the logs of all stages.
+    html_report_file = ctx.actions.declare_file("{}.report.html".format(name))
+    html_inputs = [timing_summary_file, utilization_file, drc_report_file]
+    args = ctx.actions.args()
+    args.add("--name", name)
+    args.add("--timing-summary", timing_summary_file.path)
+    args.add("--utilization", utilization_file.path)
+    args.add("--drc", drc_report_file.path)
+    if methodology:
+        (check_timing_file, methodology_file) = methodology.reports
+        html_inputs += methodology.reports
+        args.add("--check-timing-report", check_timing_file.path)
+        args.add("--methodology-report", methodology_file.path)
+    logs = []
+    synth_log = getattr(ctx.attr.synthesis[VivadoSynthProvider], "log", None)
+    if synth_log:
+        logs.append(("synth", synth_log))
+    logs += zip(stage_names + ["bitstream"], stage_logs + [logfile])
+    for (stage, log) in logs:
+        html_inputs.append(log)
+        args.add("--log", "{}={}".format(stage, log.path))
+    args.add("--out", html_report_file.path)
+    ctx.actions.run(
+        inputs = html_inputs,
+        outputs = [html_report_file],
+        executable = ctx.executable._htmlreport,
+        arguments = [args],
+        mnemonic = "HTMLREPORT",
+        progress_message = "Writing the HTML report: {}".format(html_report_file.path),
+    )
+
+    cdc_files = _cdc_check(ctx, cdc)
+    methodology_files = _methodology_check(ctx, methodology)
+    metrics_files = _log_metrics(ctx, ".pnr", logs)
+
+    providers = [netlists] if netlists else []
+    return providers + [
         DefaultInfo(files=depset([
             bit_file,
             probes_file,
             utilization_file,
+            hier_utilization_file,
             timing_summary_file,
             drc_report_file,
             output_dcp_file,
             logfile,
-        ])),
+        ] + cdc_files + methodology_files + timing_paths_files + power_files)),
+        OutputGroupInfo(
+            stage_checkpoints = depset(stage_dcps),
+            stage_logs = depset(stage_logs),
+            place_reports = depset([place_timing_summary_file]),
+            mem_info = depset([mem_info_file] if mem_info_file else []),
+            netlists = depset(netlist_files),
+            cdc = depset(cdc_files),
+            methodology = depset(methodology_files),
+            timing_paths = depset(timing_paths_files),
+            power = depset(power_files),
+            html_report = depset([html_report_file]),
+            metrics = depset(metrics_files),
+        ),
         VivadoBitstreamProvider(
             bitstream = bit_file,
             probes = probes_file,
+            mem_info = mem_info_file,
+        ),
+        VivadoReportsProvider(
+            timing_summary = timing_summary_file,
+            utilization = utilization_file,
+            drc = drc_report_file,
+            logs = logs,
         ),
     ]
 
 vivado_place_and_route2 = rule(
     implementation = _vivado_place_and_route2_impl,
-    attrs = DOCKER_RUN_SCRIPT_ATTRS | VIVADO_CONFIG_ATTRS | {
+    doc = "Implements a synthesized design in separate opt, place, optional " +
+          "phys_opt and route stages, then writes the reports and the " +
+          "bitstream. The `html_report` output group has a self-contained " +
+          "HTML page with the timing summary and its clocks, the " +
+          "utilization, the DRC and methodology findings, the warnings of " +
+          "the logs by message ID and the runtime of each step. Build it " +
+          "with `--output_groups=html_report`.",
+    attrs = DOCKER_RUN_SCRIPT_ATTRS | VIVADO_CONFIG_ATTRS | SIM_NETLIST_ATTRS | CDC_ATTRS |
+             METHODOLOGY_ATTRS | METRICS_ATTRS |
+             _hook_attrs(["opt", "place", "phys_opt", "route", "bitstream"]) | {
         "synthesis": attr.label(
             doc = "The mandatory synth2 target to use",
             mandatory = True,
@@ -168,6 +427,14 @@ vivado_place_and_route2 = rule(
         "xdcs": attr.label_list(
             doc = "Constraint files",
         ),
+        "opt_design_options": attr.string(
+            default = "",
+            doc = "Additional options to pass to the `opt_design` command in Vivado",
+        ),
+        "post_opt_design": attr.string_list(
+            default = [],
+            doc = "TCL commands, one per line, to add after `opt_design` command in Vivado",
+        ),
         "place_design_options": attr.string(
             default = "",
             doc = "Additional options to pass to the `place_design` command in Vivado",
@@ -184,6 +451,151 @@ vivado_place_and_route2 = rule(
             default = [],
             doc = "TCL commands, one per line, to add after `route_design` command in Vivado",
         ),
+        "phys_opt_design": attr.bool(
+            default = False,
+            doc = "If set, runs `phys_opt_design` as a separate stage between placement and routing",
+        ),
+        "phys_opt_design_options": attr.string(
+            default = "",
+            doc = "Additional options to pass to the `phys_opt_design` command in Vivado",
+        ),
+        "post_phys_opt_design": attr.string_list(
+            default = [],
+            doc = "TCL commands, one per line, to add after `phys_opt_design` command in Vivado",
+        ),
+        "allow_dummy_outputs": attr.bool(
+            default = False,
+            doc = "If set, writes a placeholder bitstream and probes file " +
+                  "instead of failing when Vivado can not generate them. " +
+                  "The placeholders can not be programmed into a device.",
+        ),
+        "write_mem_info": attr.bool(
+            default = False,
+            doc = "If set, writes the block RAM memory map (.mmi) with " +
+                  "`write_mem_info`, in the `mem_info` output group. " +
+                  "`vivado_updatemem` needs it to patch new memory contents " +
+                  "into the bitstream.",
+        ),
+        "bitstream_compress": attr.bool(
+            default = False,
+            doc = "If set, compresses the bitstream",
+        ),
+        "config_voltage": attr.string(
+            default = "",
+            values = ["", "1.5", "1.8", "2.5", "3.3"],
+            doc = "The configuration bank voltage (`CONFIG_VOLTAGE`). " +
+                  "UltraScale+ parts only support 1.5 and 1.8.",
+        ),
+        "cfgbvs": attr.string(
+            default = "",
+            values = ["", "VCCO", "GND"],
+            doc = "The configuration bank voltage select (`CFGBVS`). " +
+                  "Not available on UltraScale+ parts.",
+        ),
+        "spi_buswidth": attr.int(
+            default = 0,
+            values = [0, 1, 2, 4, 8],
+            doc = "The SPI flash bus width used at configuration. " +
+                  "A width of 8 (dual quad SPI) needs an UltraScale or UltraScale+ part.",
+        ),
+        "config_rate": attr.string(
+            default = "",
+            doc = "The master configuration clock rate in MHz, e.g. \"33\". " +
+                  "7-series parts only support a fixed set of rates.",
+        ),
+        "userid": attr.string(
+            default = "",
+            doc = "The 32-bit `USERID` value in hex, e.g. \"0xDEADBEEF\"",
+        ),
+        "usr_access": attr.string(
+            default = "",
+            doc = "The 32-bit `USR_ACCESS` value in hex, or \"TIMESTAMP\" " +
+                  "to use the bitstream generation time",
+        ),
+        "stamp": attr.bool(
+            default = False,
+            doc = "If set, encodes the build provenance into `USERID` and " +
+                  "`USR_ACCESS`: the short git commit and dirty flag from the " +
+                  "`STABLE_GIT_COMMIT` and `STABLE_GIT_DIRTY` workspace status " +
+                  "keys, and the build time from `BUILD_TIMESTAMP`. The " +
+                  "workspace status command must provide the git keys. " +
+                  "Read the stamp back with `//build/vivado/bin/bitstamp` or " +
+                  "with the `--mode=identify` of `vivado_program_device`.",
+        ),
+        "timing_paths": attr.int(
+            default = 0,
+            doc = "If set, writes the worst this many setup and hold paths " +
+                  "of each path group with `report_timing`, and as JSON, in " +
+                  "the `timing_paths` output group. Each path has its " +
+                  "startpoint, endpoint, clocks, slack, logic levels, logic " +
+                  "and routing delay and the cells along it, and the paths " +
+                  "are grouped by the module that they end in.",
+        ),
+        "timing_nworst": attr.int(
+            default = 1,
+            doc = "The number of paths to report per endpoint, for " +
+                  "`timing_paths`.",
+        ),
+        "power_report": attr.bool(
+            default = False,
+            doc = "If set, writes a `report_power` report of the routed " +
+                  "design, and its summary as JSON, in the `power` output " +
+                  "group. The JSON has the total on-chip, dynamic and static " +
+                  "power, the junction temperature and the current of each " +
+                  "supply rail.",
+        ),
+        "toggle_rate": attr.string(
+            default = "",
+            doc = "The default toggle rate in percent of the nets without " +
+                  "simulated activity, for `power_report`. Vivado uses 12.5 " +
+                  "if not set.",
+        ),
+        "static_probability": attr.string(
+            default = "",
+            doc = "The default probability, 0 to 1, that a net without " +
+                  "simulated activity is high, for `power_report`. Vivado " +
+                  "uses 0.5 if not set.",
+        ),
+        "saif": attr.label(
+            allow_single_file = [".saif"],
+            doc = "The switching activity (SAIF) of a simulation of the " +
+                  "design, for `power_report`. This makes the power estimate " +
+                  "much more accurate than default toggle rates.",
+        ),
+        "saif_strip_path": attr.string(
+            default = "",
+            doc = "The instance path of the design in the `saif` " +
+                  "simulation, e.g. `tb/dut`.",
+        ),
+        "max_power": attr.string(
+            default = "",
+            doc = "If set, fails the build if the total on-chip power in W " +
+                  "of `power_report` exceeds this, e.g. \"2.5\".",
+        ),
+        "max_junction_temp": attr.string(
+            default = "",
+            doc = "If set, fails the build if the junction temperature in " +
+                  "C of `power_report` exceeds this, e.g. \"85\" for the " +
+                  "thermal budget of a passively cooled enclosure.",
+        ),
+        "_htmlreport": attr.label(
+            doc = "htmlreport binary",
+            default = Label("//build/vivado/bin/htmlreport"),
+            executable = True,
+            cfg = "host",
+        ),
+        "_timingreport": attr.label(
+            doc = "timingreport binary",
+            default = Label("//build/vivado/bin/timingreport"),
+            executable = True,
+            cfg = "host",
+        ),
+        "_powerreport": attr.label(
+            doc = "powerreport binary",
+            default = Label("//build/vivado/bin/powerreport"),
+            executable = True,
+            cfg = "host",
+        ),
         "_generator": attr.label(
             doc = "xprgen binary",
             default = Label("//build/vivado/bin/xprgen"),
@@ -196,9 +608,30 @@ vivado_place_and_route2 = rule(
             executable = True,
             cfg = "host",
         ),
+        "_opt_template": attr.label(
+            doc = "opt stage template",
+            default = Label("//build/vivado:opt_batch_tcl_template"),
+            allow_single_file = True,
+        ),
+        "_place_template": attr.label(
+            doc = "place stage template",
+            default = Label("//build/vivado:place_batch_tcl_template"),
+            allow_single_file = True,
+        ),
+        "_phys_opt_template": attr.label(
+            doc = "phys_opt stage template",
+            default = Label("//build/vivado:phys_opt_batch_tcl_template"),
+            allow_single_file = True,
+        ),
+        "_route_template": attr.label(
+            doc = "route stage template",
+            default = Label("//build/vivado:route_batch_tcl_template"),
+            allow_single_file = True,
+        ),
         "_batch_template": attr.label(
-            doc = "pnr template",
+            doc = "reports and bitstream stage template",
             default = Label("//build/vivado:pnr_batch_tcl_template"),
+            allow_single_file = True,
         ),
     },
 )
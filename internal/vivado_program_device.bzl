@@ -2,6 +2,7 @@
 
 load("//internal:providers.bzl",
     "VivadoBitstreamProvider",
+    "VivadoDfxProvider",
 )
 load("//internal:defines.bzl",
     "VIVADO_CONFIG_ATTRS",
@@ -29,6 +30,16 @@ def _vivado_program_device(ctx):
 
     bitstream_provider = bittarget[VivadoBitstreamProvider]
     bitfile = bitstream_provider.bitstream
+    if ctx.attr.partial:
+        if VivadoDfxProvider not in bittarget:
+            fail("partial: {} is not a vivado_dfx target".format(bittarget.label))
+        partials = bittarget[VivadoDfxProvider].partial_bitstreams
+        module = ctx.attr.partial.label
+        if module not in partials:
+            fail("partial: {} has no module {}, want one of: {}".format(
+                bittarget.label, module,
+                ", ".join(sorted([str(k) for k in partials]))))
+        bitfile = partials[module]
 
     # Needed binaries
     script = ctx.attr._script.files.to_list()[0]
@@ -70,6 +81,7 @@ def _vivado_program_device(ctx):
     args.add("--run-docker", script.path)
     args.add("--template", tpl1.path)
     args.add("--bitfile", bitfile.short_path)
+    args.add("--bitfile-path", bitfile.path)
     args.add("--vivado-version", config.vivado_version)
 
     # Add runner arguments here.
@@ -78,6 +90,9 @@ def _vivado_program_device(ctx):
         targets=ctx.attr.data)
     args.add("--prog-runner-args={}".format(prog_runner_args))
     args.add("--prog-runner-binary", ctx.files.prog_daemon[0].short_path)
+    args.add("--bitstamp", ctx.executable._bitstamp.short_path)
+    if ctx.attr.partial:
+        args.add("--partial")
 
     ctx.actions.run(
         inputs = [generator, gotopt2, script, bitfile] + daemon_outputs,
@@ -107,6 +122,7 @@ def _vivado_program_device(ctx):
         ctx.attr._data[DefaultInfo].default_runfiles,
         ctx.attr._gotopt2[DefaultInfo].default_runfiles,
         ctx.attr.prog_daemon[DefaultInfo].default_runfiles,
+        ctx.attr._bitstamp[DefaultInfo].default_runfiles,
         tools_runfiles,
     ]
 
@@ -123,11 +139,24 @@ def _vivado_program_device(ctx):
 vivado_program_device = rule(
     implementation = _vivado_program_device,
     executable = True,
+    doc = "Programs a bitstream into the FPGA over JTAG with `bazel run` " +
+          "(requires --hostport and --device). With --mode=identify, " +
+          "reads the build stamp of the running bitstream from the device " +
+          "instead, see the `stamp` attribute of `vivado_place_and_route2`. " +
+          "With `partial`, programs the partial bitstream of a " +
+          "reconfigurable module onto a device that already runs a full " +
+          "bitstream of the same `vivado_dfx` design.",
     attrs = VIVADO_CONFIG_ATTRS | {
         "deps": attr.label_list(
             providers = [VivadoBitstreamProvider],
             doc = "The list of deps containing bitstream code",
         ),
+        "partial": attr.label(
+            doc = "A reconfigurable module of the `vivado_dfx` target in " +
+                  "`deps`, as listed in its `modules`, whose partial " +
+                  "bitstream to program instead of the full bitstream. The " +
+                  "device must already be configured.",
+        ),
         "_script": attr.label(
             default="@rules_bid//build:docker_run",
             executable=True,
@@ -151,6 +180,12 @@ vivado_program_device = rule(
             doc = "The program to generate a programming wrapper",
             providers = [DefaultInfo],
         ),
+        "_bitstamp": attr.label(
+            default=Label("//build/vivado/bin/bitstamp"),
+            executable=True,
+            cfg="target",
+            doc = "The build stamp decoder, for --mode=identify",
+        ),
         "prog_daemon": attr.label(
             doc = "The binary to start before programming",
             executable = True,
@@ -11,20 +11,38 @@ It works in two phases:
 1. A hermetic build action runs Vivado's `write_cfgmem` to convert the `.bit`
    into a flash image (`.mcs`/`.bin`) for the configured flash part. This needs
    Vivado but no hardware, so it is a normal, cacheable Bazel build action --
-   `bazel build` on the target produces the flash image.
+   `bazel build` on the target produces the flash image. If `deps` is a
+   `vivado_cfgmem` target instead, its image is used as is. If that image
+   follows a MultiBoot layout, `bazel run ... -- --image=update` programs
+   only the named layout image.
 2. A generated `bazel run` wrapper connects to a running hardware server and
    erases + writes that image into the device flash via Vivado's
    `create_hw_cfgmem` / `program_hw_cfgmem`. This needs the physical board.
+
+Besides programming, the wrapper has modes for diagnosing boards in the lab
+and recovering bricked units, selected with `--mode`:
+
+* `erase` erases the whole flash, or the sectors of the `--image`.
+* `blank-check` checks that the flash, or the sectors of the `--image`, are
+  erased.
+* `verify` compares the flash with the built image, or with `--verify_file`.
+* `readback` reads `--readback_size` bytes at `--readback_address` into
+  `--readback_file` (.bin or .mcs).
 """
 
 load("//internal:providers.bzl",
     "VivadoBitstreamProvider",
+    "VivadoCfgmemProvider",
 )
 load("//internal:defines.bzl",
     "VIVADO_CONFIG_ATTRS",
-    _script_cmd = "script_cmd",
     _vivado_config = "vivado_config",
 )
+load("//internal:vivado_cfgmem.bzl",
+    "CFGMEM_ATTRS",
+    "CFGMEM_INTERFACES",
+    _write_cfgmem = "write_cfgmem",
+)
 
 def _vivado_program_flash_impl(ctx):
     """Implementation for the vivado_program_flash rule.
@@ -36,69 +54,42 @@ def _vivado_program_flash_impl(ctx):
       A list of providers with DefaultInfo carrying the flash image and the
       executable programming wrapper.
     """
-    if not ctx.attr.deps:
+    if len(ctx.attr.deps) != 1:
         fail("vivado_program_flash: `deps` must contain exactly one target " +
-             "providing VivadoBitstreamProvider.")
+             "providing VivadoBitstreamProvider or VivadoCfgmemProvider.")
 
     config = _vivado_config(ctx)
     docker_run = ctx.executable._script
-    bitfile = ctx.attr.deps[0][VivadoBitstreamProvider].bitstream
+    dep = ctx.attr.deps[0]
 
     # --- Phase 1: build the flash image with write_cfgmem (no hardware). ---
-    mcs = ctx.actions.declare_file("{}.{}".format(ctx.attr.name, ctx.attr.format))
-    cache_dir = ctx.actions.declare_directory(
-        "_vivado_program_flash.cache.{}".format(ctx.label.name),
-    )
-
-    # write_cfgmem runs inside the container whose working directory is the exec
-    # root, so exec-root-relative paths (bitfile.path, mcs.path) resolve. The
-    # TCL braces around `up 0x0 <bit>` are required by write_cfgmem.
-    cfgmem_tcl = ctx.actions.declare_file("{}.cfgmem.tcl".format(ctx.attr.name))
-    ctx.actions.write(
-        output = cfgmem_tcl,
-        content = (
-            "write_cfgmem -force -format {fmt} -size {size} -interface {iface}" +
-            " -loadbit {{up 0x0 {bit}}} -file {mcs}\n"
-        ).format(
-            fmt = ctx.attr.format,
-            size = ctx.attr.size,
-            iface = ctx.attr.interface,
-            bit = bitfile.path,
-            mcs = mcs.path,
-        ),
-    )
-
-    script = _script_cmd(
-        docker_run.path,
-        mcs.path,
-        cache_dir.path,
-        freeargs = ["--net=host", "-e", "HOME=/work"],
-        container = config.container,
-    )
-
-    ctx.actions.run_shell(
-        progress_message = "Vivado write_cfgmem \"{}\" ({} {})".format(
+    layout = None
+    layout_images = {}
+    if VivadoCfgmemProvider in dep:
+        cfgmem = dep[VivadoCfgmemProvider]
+        images = cfgmem.images
+        interface = cfgmem.interface
+        layout = cfgmem.layout
+        layout_images = cfgmem.layout_images
+    else:
+        if not ctx.attr.size:
+            fail("vivado_program_flash: `size` is required to build the " +
+                 "flash image from a bitstream.")
+        interface = ctx.attr.interface
+        images = _write_cfgmem(
+            ctx,
+            config,
             ctx.attr.name,
-            ctx.attr.format,
-            ctx.attr.flash_part,
-        ),
-        inputs = [docker_run, cfgmem_tcl, bitfile],
-        outputs = [mcs, cache_dir],
-        tools = [docker_run],
-        mnemonic = "VivadoCfgmem",
-        command = (
-            "mkdir -p \"$(dirname {mcs})\" && " +
-            "{script} " +
-            "LD_LIBRARY_PATH=\"{vivado_path}/lib/lnx64.o\" " +
-            "{vivado_path}/bin/setEnvAndRunCmd.sh vivado " +
-            "-notrace -mode batch -source {tcl} 1>&2"
-        ).format(
-            mcs = mcs.path,
-            script = script,
-            vivado_path = config.vivado_path,
-            tcl = cfgmem_tcl.path,
-        ),
-    )
+            dep[VivadoBitstreamProvider].bitstream,
+            format = ctx.attr.format,
+            size = ctx.attr.size,
+            interface = interface,
+            load_address = ctx.attr.load_address,
+        )
+    if len(images) != 1:
+        fail("vivado_program_flash: programming dual QSPI (SPIx8) images " +
+             "is not supported.")
+    mcs = images[0]
 
     # --- Phase 2: generate the bazel-run flash programming wrapper. ---
     gotopt2 = ctx.attr._gotopt2.files.to_list()[0]
@@ -123,8 +114,12 @@ def _vivado_program_flash_impl(ctx):
     args.add("--template", template.path)
     args.add("--mcs-file", mcs.short_path)
     args.add("--flash-part", ctx.attr.flash_part)
-    args.add("--flash-interface", ctx.attr.interface)
+    args.add("--flash-interface", interface)
     args.add("--vivado-version", config.vivado_version)
+    if layout:
+        args.add("--layout-file-path", layout.path)
+        for (image, f) in layout_images.items():
+            args.add("--layout-image", "{}={}".format(image, f.short_path))
 
     if ctx.attr.prog_daemon:
         prog_runner_args = ctx.expand_location(
@@ -135,7 +130,7 @@ def _vivado_program_flash_impl(ctx):
         args.add("--prog-runner-binary", ctx.files.prog_daemon[0].short_path)
 
     ctx.actions.run(
-        inputs = [generator, gotopt2, docker_run, mcs],
+        inputs = [generator, gotopt2, docker_run, mcs] + ([layout] if layout else []),
         outputs = [outfile],
         executable = generator,
         tools = [gotopt2, docker_run] + data,
@@ -146,7 +141,7 @@ def _vivado_program_flash_impl(ctx):
 
     # --- Runfiles for the generated wrapper. ---
     runfiles = ctx.runfiles(
-        files = [docker_run, gotopt2, yaml, mcs],
+        files = [docker_run, gotopt2, yaml, mcs] + layout_images.values(),
         collect_data = True,
     )
     tools_files = []
@@ -181,10 +176,11 @@ vivado_program_flash = rule(
           "flash (SPI/QSPI) so it loads automatically on power-up. " +
           "`bazel build` produces the flash image (.mcs/.bin); `bazel run` " +
           "writes it to the board (requires --hostport and --device).",
-    attrs = VIVADO_CONFIG_ATTRS | {
+    attrs = VIVADO_CONFIG_ATTRS | CFGMEM_ATTRS | {
         "deps": attr.label_list(
-            providers = [VivadoBitstreamProvider],
-            doc = "Exactly one target providing the bitstream to flash.",
+            providers = [[VivadoBitstreamProvider], [VivadoCfgmemProvider]],
+            doc = "Exactly one target providing the bitstream to flash, or " +
+                  "the flash image built by `vivado_cfgmem`.",
         ),
         "flash_part": attr.string(
             mandatory = True,
@@ -193,19 +189,24 @@ vivado_program_flash = rule(
                   "`get_cfgmem_parts` in Vivado.",
         ),
         "size": attr.int(
-            mandatory = True,
             doc = "The flash capacity in megabytes (MB), passed to " +
-                  "`write_cfgmem -size`.",
+                  "`write_cfgmem -size`. Required if `deps` is a bitstream.",
         ),
         "interface": attr.string(
             default = "SPIx4",
-            doc = "The flash programming interface, e.g. SPIx1/SPIx2/SPIx4.",
+            values = CFGMEM_INTERFACES,
+            doc = "The flash programming interface, e.g. SPIx1/SPIx2/SPIx4. " +
+                  "Taken from the image if `deps` is a `vivado_cfgmem` target.",
         ),
         "format": attr.string(
             default = "mcs",
             values = ["mcs", "bin"],
             doc = "The flash image format produced by `write_cfgmem`.",
         ),
+        "load_address": attr.string(
+            default = "0x0",
+            doc = "The flash address to load the bitstream at.",
+        ),
         "prog_daemon": attr.label(
             doc = "Optional binary to start before programming (e.g. a " +
                   "hardware server).",
@@ -218,12 +219,6 @@ vivado_program_flash = rule(
         "data": attr.label_list(
             doc = "The list of dependencies to expand in prog_daemon_args.",
         ),
-        "_script": attr.label(
-            default = "@rules_bid//build:docker_run",
-            executable = True,
-            cfg = "host",
-            doc = "The docker run script.",
-        ),
         "_gotopt2": attr.label(
             default = "@gotopt2//:bin",
             executable = True,
@@ -3,6 +3,7 @@
 load("@bazel_skylib//lib:paths.bzl", "paths")
 load("//internal:defines.bzl",
     "VIVADO_CONFIG_ATTRS",
+    _check_include_dirs = "check_include_dirs",
     _script_cmd = "script_cmd",
     _vivado_config = "vivado_config",
 )
@@ -202,6 +203,7 @@ def _vivado_project_impl(ctx):
 
     # Prepare include dirs
     include_dirs = ctx.attr.include_dirs  # list(string)
+    _check_include_dirs(ctx, include_dirs, hdrs_files)
 
     # Handle output files
     xpr = ctx.actions.declare_file("{}.xpr.tcl".format(name))
@@ -223,6 +225,8 @@ def _vivado_project_impl(ctx):
     args.add("--out-xpr", xpr.path)
     args.add("--out-synth", synth_tcl.path)
     args.add("--out-pnr", pnr_tcl.path)
+    # No --work-dir: Vivado creates the project in the exec root, where it
+    # starts, and `_xpr_gen` copies the XPR file from there.
 
 
     part = ctx.attr.part
@@ -296,7 +300,9 @@ vivado_project = rule(
             doc = "A list of defines.",
         ),
         "include_dirs": attr.string_list(
-            doc = "A list of include directories.",
+            doc = "A list of include directories, relative to the " +
+                  "workspace root. Each must contain at least one of the " +
+                  "`hdrs`, as the Vivado actions only see those.",
         ),
         "env": attr.string_dict(
             allow_empty = True,
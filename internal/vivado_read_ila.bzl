@@ -50,6 +50,7 @@ def _vivado_read_ila(ctx):
     args.add("--run-docker", script.path)
     args.add("--template", tpl1.path)
     args.add("--ltxfile", probes_file.short_path)
+    args.add("--ltxfile-path", probes_file.path)
     args.add("--vivado-version", config.vivado_version)
 
     ctx.actions.run(
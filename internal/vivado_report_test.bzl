@@ -0,0 +1,146 @@
+"""Test rule checking the reports of an implementation run.
+
+`vivado_report_test` checks the reports and logs of a
+`vivado_place_and_route2` target against quality gates: timing, utilization
+budgets, DRC and log warnings. Each check is a test case of the JUnit XML
+that the test writes to `XML_OUTPUT_FILE`, so CI shows the failing checks
+next to the software unit tests.
+"""
+
+load("@bazel_skylib//lib:shell.bzl", "shell")
+load("//internal:providers.bzl",
+    "VivadoReportsProvider",
+)
+
+def _get_rlocation(file, ctx):
+    if file.short_path.startswith("../"):
+        return file.short_path[3:]
+    else:
+        return ctx.workspace_name + "/" + file.short_path
+
+def _rlocation_arg(file, ctx):
+    """Returns the shell word that resolves file in the test's runfiles."""
+    return "\"$(rlocation {})\"".format(_get_rlocation(file, ctx))
+
+def _vivado_report_test_impl(ctx):
+    """Implementation for the vivado_report_test rule.
+
+    Args:
+      ctx: The rule context.
+
+    Returns:
+      A list of providers.
+    """
+    reports = ctx.attr.implementation[VivadoReportsProvider]
+    reportcheck = ctx.executable._reportcheck
+    files = []
+
+    args = ["--suite", shell.quote(str(ctx.label))]
+    if ctx.attr.timing:
+        files.append(reports.timing_summary)
+        args += ["--timing-summary", _rlocation_arg(reports.timing_summary, ctx)]
+    if ctx.attr.max_utilization:
+        files.append(reports.utilization)
+        args += ["--utilization", _rlocation_arg(reports.utilization, ctx)]
+        for (site_type, pct) in ctx.attr.max_utilization.items():
+            args += ["--max-utilization", shell.quote("{}={}".format(site_type, pct))]
+    if ctx.attr.drc:
+        files.append(reports.drc)
+        args += ["--drc", _rlocation_arg(reports.drc, ctx)]
+        if ctx.file.drc_waivers:
+            files.append(ctx.file.drc_waivers)
+            args += ["--drc-waivers", _rlocation_arg(ctx.file.drc_waivers, ctx)]
+    elif ctx.file.drc_waivers:
+        fail("vivado_report_test: set drc to use drc_waivers")
+    if ctx.attr.log_warnings:
+        for (step, log) in reports.logs:
+            files.append(log)
+            args += ["--log", "{}={}".format(step, _rlocation_arg(log, ctx))]
+        for id in ctx.attr.allowed_warnings:
+            args += ["--allow-warning", shell.quote(id)]
+        args += ["--max-warnings", str(ctx.attr.max_warnings)]
+
+    executable = ctx.actions.declare_file(ctx.label.name + ".sh")
+    ctx.actions.expand_template(
+        template = ctx.file._test_template,
+        output = executable,
+        substitutions = {
+            "{{REPORTCHECK_RLOCATION}}": _get_rlocation(reportcheck, ctx),
+            "{{ARGS}}": " \\\n    ".join(args),
+        },
+        is_executable = True,
+    )
+
+    return [
+        DefaultInfo(
+            executable = executable,
+            runfiles = ctx.runfiles(files = files + [reportcheck])
+                .merge(ctx.attr._bash_runfiles[DefaultInfo].default_runfiles)
+                .merge(ctx.attr._reportcheck[DefaultInfo].default_runfiles),
+        ),
+    ]
+
+vivado_report_test = rule(
+    implementation = _vivado_report_test_impl,
+    test = True,
+    doc = "Checks the reports and logs of an implementation run, and " +
+          "writes one JUnit test case per check. The timing checks are " +
+          "setup, hold and pulse width; the utilization checks are one per " +
+          "budget; the DRC checks are one per DRC rule with violations; the " +
+          "log checks are one per step.",
+    attrs = {
+        "implementation": attr.label(
+            mandatory = True,
+            providers = [VivadoReportsProvider],
+            doc = "The `vivado_place_and_route2` target to check.",
+        ),
+        "timing": attr.bool(
+            default = True,
+            doc = "If set, checks that the design meets setup, hold and " +
+                  "pulse width timing.",
+        ),
+        "max_utilization": attr.string_dict(
+            doc = "The utilization budget in percent of each site type, as " +
+                  "named in `report_utilization`, e.g. " +
+                  "`{\"Slice LUTs\": \"80\", \"Block RAM Tile\": \"90\"}`.",
+        ),
+        "drc": attr.bool(
+            default = True,
+            doc = "If set, checks that the design has no critical or error " +
+                  "DRC violations that are not waived.",
+        ),
+        "drc_waivers": attr.label(
+            allow_single_file = [".json"],
+            doc = "A JSON file of waivers for the DRC violations, in the " +
+                  "format of `cdc_waivers`. The `id` is the DRC rule, e.g. " +
+                  "`NSTD-1`, and the `description` matches its message.",
+        ),
+        "log_warnings": attr.bool(
+            default = True,
+            doc = "If set, checks that the log of each step has no errors " +
+                  "or critical warnings, and at most `max_warnings` warnings.",
+        ),
+        "allowed_warnings": attr.string_list(
+            doc = "The message IDs that the log checks do not count, e.g. " +
+                  "`Synth 8-7129`.",
+        ),
+        "max_warnings": attr.int(
+            default = -1,
+            doc = "The most warnings allowed in the log of each step, -1 for " +
+                  "any number.",
+        ),
+        "_reportcheck": attr.label(
+            default = Label("//build/vivado/bin/reportcheck"),
+            executable = True,
+            cfg = "target",
+            doc = "The report checking tool.",
+        ),
+        "_bash_runfiles": attr.label(
+            default = "@bazel_tools//tools/bash/runfiles",
+        ),
+        "_test_template": attr.label(
+            default = "//internal:vivado_report_test.sh.tpl",
+            allow_single_file = True,
+        ),
+    },
+)
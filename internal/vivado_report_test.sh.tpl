@@ -0,0 +1,41 @@
+#!/usr/bin/env bash
+
+# --- begin runfiles.bash initialization ---
+# Copy-pasted from Bazel's Bash runfiles library (tools/bash/runfiles/runfiles.bash).
+if [[ ! -d "${RUNFILES_DIR:-/dev/null}" && ! -f "${RUNFILES_MANIFEST_FILE:-/dev/null}" ]]; then
+  if [[ -f "$0.runfiles_manifest" ]]; then
+    export RUNFILES_MANIFEST_FILE="$0.runfiles_manifest"
+  elif [[ -f "$0.runfiles/MANIFEST" ]]; then
+    export RUNFILES_MANIFEST_FILE="$0.runfiles/MANIFEST"
+  elif [[ -f "$0.runfiles/bazel_tools/tools/bash/runfiles/runfiles.bash" ]]; then
+    export RUNFILES_DIR="$0.runfiles"
+  fi
+fi
+if [[ -f "${RUNFILES_DIR:-/dev/null}/bazel_tools/tools/bash/runfiles/runfiles.bash" ]]; then
+  source "${RUNFILES_DIR}/bazel_tools/tools/bash/runfiles/runfiles.bash"
+elif [[ -f "${RUNFILES_MANIFEST_FILE:-/dev/null}" ]]; then
+  source "$(grep -m1 "^bazel_tools/tools/bash/runfiles/runfiles.bash " \
+            "${RUNFILES_MANIFEST_FILE}" | cut -d ' ' -f 2-)"
+else
+  echo >&2 "ERROR: cannot find @bazel_tools//tools/bash/runfiles:runfiles.bash"
+  exit 1
+fi
+# --- end runfiles.bash initialization ---
+
+set -eo pipefail
+
+REPORTCHECK=$(rlocation {{REPORTCHECK_RLOCATION}})
+if [[ ! -f "${REPORTCHECK}" ]]; then
+  echo >&2 "ERROR: cannot find reportcheck at ${REPORTCHECK}"
+  exit 1
+fi
+
+# Bazel reads the test cases from the JUnit XML file that it asks for.
+XML_ARGS=()
+if [[ -n "${XML_OUTPUT_FILE:-}" ]]; then
+  XML_ARGS=(--xml-output "${XML_OUTPUT_FILE}")
+fi
+
+"${REPORTCHECK}" \
+    "${XML_ARGS[@]}" \
+    {{ARGS}}
@@ -2,12 +2,26 @@
 
 load("//internal:defines.bzl",
     "DOCKER_RUN_SCRIPT_ATTRS",
+    "CDC_ATTRS",
+    "METHODOLOGY_ATTRS",
+    "METRICS_ATTRS",
+    "SIM_NETLIST_ATTRS",
     "VIVADO_CONFIG_ATTRS",
     _script_cmd = "script_cmd",
+    _cdc_check = "cdc_check",
+    _cdc_reports = "cdc_reports",
+    _check_include_dirs = "check_include_dirs",
+    _hook_attrs = "hook_attrs",
+    _log_metrics = "log_metrics",
+    _methodology_check = "methodology_check",
+    _methodology_reports = "methodology_reports",
+    _sim_netlists = "sim_netlists",
+    _stage_hooks = "stage_hooks",
     _vivado_config = "vivado_config",
 )
 load("//internal:providers.bzl",
     "VivadoLibraryProvider",
+    "VivadoOocProvider",
     "VivadoSynthProvider",
 )
 
@@ -43,8 +57,28 @@ def _vivado_synthesis2_impl(ctx):
     template_file = ctx.attr._synth_batch_template.files.to_list()[0]
     inputs += [template_file]
 
-    # Get library deps.
+    # Out-of-context partitions replace their libraries: the design reads
+    # their stubs instead, so that their tops are black boxes, and links
+    # their checkpoints into the black box cells after synthesis. Black
+    # boxes are read the same way, but never linked.
     seen_libraries = {}
+    partitions = ctx.attr.ooc.items() + [(target, "") for target in ctx.attr.black_boxes]
+    for (target, cells) in partitions:
+        ooc = target[VivadoOocProvider]
+        if ooc.part != ctx.attr.part:
+            fail("ooc: {} is synthesized for part {}, want {}".format(
+                target.label, ooc.part, ctx.attr.part))
+        for lib_name in ooc.libraries:
+            seen_libraries[lib_name] = True
+        stub_library = ooc.libraries[0] if ooc.libraries else "work"
+        args.add("--library-file", "{}={}".format(stub_library, ooc.stub.path))
+        inputs += [ooc.stub]
+        if cells:
+            inputs += [ooc.dcp]
+        for cell in cells.split():
+            args.add("--ooc-cell", "{}={}".format(cell, ooc.dcp.path))
+
+    # Get library deps.
     for dep in ctx.attr.deps:
         provider = dep[VivadoLibraryProvider]
 
@@ -87,6 +121,7 @@ def _vivado_synthesis2_impl(ctx):
     xdcs_paths = [ f.path for f in xdcs_files ]
     # Prepare include dirs
     include_dirs = ctx.attr.include_dirs  # list(string)
+    _check_include_dirs(ctx, include_dirs, hdrs_files)
 
     # Handle output files
     dcp_file = ctx.actions.declare_file("{}.dcp".format(name))
@@ -117,6 +152,11 @@ def _vivado_synthesis2_impl(ctx):
     inputs += data_files
 
 
+    output_dir_path = "_synthesis.work.{}".format(name)
+    output_dir = ctx.actions.declare_directory(output_dir_path)
+    cache_dir_rpath = "_synthesis.cache.{}".format(name)
+    cache_dir = ctx.actions.declare_directory(cache_dir_rpath)
+
     # Prepare args
     args.add("--custom-filename", tcl_file.path)
     args.add("--custom-template", template_file.path)
@@ -125,6 +165,8 @@ def _vivado_synthesis2_impl(ctx):
     args.add("--probes-file", probes_file.path)
     args.add("--timing-report", timing_summary_file.path)
     args.add("--top-name", top_level)
+    # Vivado starts in the exec root, and the script changes to the work dir.
+    args.add("--work-dir", output_dir.path)
     args.add("--utilization-report", utilization_file.path)
     args.add_all(processed_defines, before_each="--define")
     args.add_all(processed_generics, before_each="--generic")
@@ -134,11 +176,48 @@ def _vivado_synthesis2_impl(ctx):
     args.add_all(xdcs_paths, before_each="--constraints")
     args.add("--synth-design-options", ctx.attr.synth_design_options)
     args.add_all(ctx.attr.post_synth_design, before_each="--post-synth-design")
+    inputs += _stage_hooks(ctx, "synth", args)
+    if ctx.attr.allow_dummy_outputs:
+        args.add("--allow-dummy-outputs")
 
 
     part = ctx.attr.part
     args.add("--part", part)
 
+    # An out-of-context partition also writes the stub that the designs that
+    # link it read in place of its sources.
+    stub_file = None
+    ooc_libraries = []
+    if ctx.attr.out_of_context:
+        top_files = srcs_files
+        if not ctx.attr.srcs:
+            if not ctx.attr.deps:
+                fail("out_of_context: one of `srcs` or `deps` is required.")
+            top_library = ctx.attr.deps[0][VivadoLibraryProvider]
+            ooc_libraries = [top_library.name]
+            top_files = top_library.files
+        stub_ext = "v"
+        for f in top_files:
+            if f.extension in ["vhd", "vhdl"]:
+                stub_ext = "vhdl"
+                break
+        stub_file = ctx.actions.declare_file("{}_stub.{}".format(name, stub_ext))
+        outputs += [stub_file]
+        args.add("--ooc")
+        args.add("--stub-file", stub_file.path)
+
+    netlists = _sim_netlists(ctx, args)
+    netlist_files = []
+    if netlists:
+        netlist_files = [f for f in [netlists.funcsim, netlists.timesim, netlists.sdf] if f]
+        outputs += netlist_files
+    cdc = _cdc_reports(ctx, "_synth", args)
+    if cdc:
+        outputs += cdc.reports
+    methodology = _methodology_reports(ctx, "_synth", args)
+    if methodology:
+        outputs += methodology.reports
+
     # Generate `tcl_file` script for running the synth step.
     ctx.actions.run(
         outputs = [tcl_file],
@@ -160,11 +239,6 @@ def _vivado_synthesis2_impl(ctx):
       "/tmp/.X11-unix": "/tmp/.X11-unix:ro",
     })
 
-    output_dir_path = "_synthesis.work.{}".format(name)
-    output_dir = ctx.actions.declare_directory(output_dir_path)
-    cache_dir_rpath = "_synthesis.cache.{}".format(name)
-    cache_dir = ctx.actions.declare_directory(cache_dir_rpath)
-
     script = _script_cmd(
       docker_run.path,
       output_dir.path,
@@ -206,7 +280,36 @@ def _vivado_synthesis2_impl(ctx):
         ),
     )
 
-    return [
+    cdc_files = _cdc_check(ctx, cdc)
+    methodology_files = _methodology_check(ctx, methodology)
+    metrics_files = _log_metrics(ctx, "_synth", [("synth", logfile)])
+    # Build the checks with the target, so that they fail the build.
+    if cdc:
+        outputs.append(cdc.json)
+    if methodology:
+        outputs.append(methodology.json)
+    providers = [OutputGroupInfo(
+        netlists = depset(netlist_files),
+        cdc = depset(cdc_files),
+        methodology = depset(methodology_files),
+        metrics = depset(metrics_files),
+    )]
+    if netlists:
+        providers.append(netlists)
+
+    if ctx.attr.out_of_context:
+        return providers + [
+            DefaultInfo(files = depset(outputs)),
+            VivadoOocProvider(
+                dcp = dcp_file,
+                stub = stub_file,
+                top = top_level,
+                part = part,
+                libraries = ooc_libraries,
+            ),
+        ]
+
+    return providers + [
         DefaultInfo(
             # DCP outfile, plus reports.
             files = depset(outputs),
@@ -214,12 +317,15 @@ def _vivado_synthesis2_impl(ctx):
         VivadoSynthProvider(
             synth_dcp_file = dcp_file,
             probes = probes_file,
+            part = part,
+            log = logfile,
         ),
     ]
 
 vivado_synthesis2 = rule(
     implementation = _vivado_synthesis2_impl,
-    attrs = DOCKER_RUN_SCRIPT_ATTRS | VIVADO_CONFIG_ATTRS | {
+    attrs = DOCKER_RUN_SCRIPT_ATTRS | VIVADO_CONFIG_ATTRS | SIM_NETLIST_ATTRS | CDC_ATTRS |
+             METHODOLOGY_ATTRS | METRICS_ATTRS | _hook_attrs(["synth"]) | {
         "srcs": attr.label_list(
             allow_files = True,
             doc = "The sources for the `work` library",
@@ -255,7 +361,9 @@ vivado_synthesis2 = rule(
         ),
         "include_dirs": attr.string_list(
             allow_empty = True,
-            doc = "A list of include directories.",
+            doc = "A list of include directories, relative to the " +
+                  "workspace root. Each must contain at least one of the " +
+                  "`hdrs`, as the Vivado actions only see those.",
         ),
         "synth_design_options": attr.string(
             default = "",
@@ -265,6 +373,40 @@ vivado_synthesis2 = rule(
             default = [],
             doc = "TCL commands, one per line, to add after `synth_design` command in Vivado",
         ),
+        "allow_dummy_outputs": attr.bool(
+            default = False,
+            doc = "If set, writes a placeholder probes file instead of " +
+                  "failing when Vivado can not generate it.",
+        ),
+        "out_of_context": attr.bool(
+            default = False,
+            doc = "If set, synthesizes `top` as an out-of-context (OOC) " +
+                  "partition with `-mode out_of_context`, for linking into " +
+                  "other designs through their `ooc` attribute. The target " +
+                  "then provides `VivadoOocProvider` instead of " +
+                  "`VivadoSynthProvider`, and also writes a synthesis stub " +
+                  "of `top`. Without `srcs`, the first library in `deps` " +
+                  "must hold `top`, and is left out of the designs that " +
+                  "link the partition.",
+        ),
+        "ooc": attr.label_keyed_string_dict(
+            providers = [VivadoOocProvider],
+            doc = "Out-of-context partitions to link in, keyed by label, " +
+                  "with the instance paths of the cells to link each into, " +
+                  "separated by spaces, e.g. `{\":pcie_ooc\": \"u_pcie\"}`. " +
+                  "The design reads the stub of each partition instead of " +
+                  "its sources, and links its checkpoint into the cells with " +
+                  "`read_checkpoint -cell` after synthesis. Partitions are " +
+                  "only resynthesized when their own sources change.",
+        ),
+        "black_boxes": attr.label_list(
+            providers = [VivadoOocProvider],
+            doc = "Out-of-context partitions to leave as black boxes. The " +
+                  "design reads the stub of each instead of its sources, and " +
+                  "links nothing into its cells. Use this for the static " +
+                  "design of `vivado_dfx`, with one of the reconfigurable " +
+                  "modules of each partition.",
+        ),
         "_generator": attr.label(
             doc = "xprgen binary",
             default = Label("//build/vivado/bin/xprgen"),
@@ -54,10 +54,30 @@ def _vivado_test_impl(ctx):
     files += [file for file in provider.files]
     files += [provider.library_dir]
 
+    # Gate-level simulation of a netlist needs the glbl module alongside the
+    # top, and a timing netlist is elaborated with its SDF delays, which it
+    # annotates itself.
+    netlist_tops = []
+    timing = False
+    for p in [provider] + provider.deps.to_list():
+        netlist = getattr(p, "netlist", None)
+        if not netlist:
+            continue
+        if netlist.glbl and not netlist_tops:
+            netlist_tops += ["--top", "'{}.glbl'".format(p.name)]
+        if netlist.sdf:
+            files += [netlist.sdf]
+            timing = True
+    if timing:
+        args += ["-maxdelay", "-transport_int_delays",
+                 "-pulse_r", "0", "-pulse_int_r", "0",
+                 "-pulse_e", "0", "-pulse_int_e", "0"]
+
     top_entity = ctx.attr.top
     if ctx.attr.config:
         top_entity = ctx.attr.config
     args += ["--top", "'{}.{}'".format(provider.name, top_entity)]
+    args += netlist_tops
     args += ctx.attr.extra_modules
 
     for (k, v) in ctx.attr.defines.items():
@@ -210,7 +230,10 @@ vivado_test = rule(
     test = True,
     attrs = DOCKER_RUN_SCRIPT_ATTRS | {
         "library": attr.label(
-            doc = "The library to run the simulation from",
+            doc = "The library to run the simulation from. If it or its " +
+                  "deps hold a simulation netlist, see the `netlist` of " +
+                  "`vivado_library`, it is elaborated with the `glbl` " +
+                  "module and, for timing netlists, with SDF delays.",
             providers = [VivadoLibraryProvider],
         ),
         "top": attr.string(
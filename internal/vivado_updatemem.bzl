@@ -0,0 +1,144 @@
+"""Rule to patch block RAM contents into a routed bitstream.
+
+`vivado_updatemem` runs Vivado's `updatemem` to replace the block RAM
+contents of a routed bitstream, such as the firmware of a soft CPU, with new
+`.elf` or `.mem` files. Only the bitstream is rewritten: synthesis and place
+and route are not run again, and are cached separately from the firmware.
+
+The place and route target must be built with `write_mem_info = True`, so
+that it provides the block RAM memory map (`.mmi`) that `updatemem` needs.
+The result provides `VivadoBitstreamProvider`, so it can be programmed or
+written to flash like the original bitstream.
+"""
+
+load("//internal:providers.bzl",
+    "VivadoBitstreamProvider",
+)
+load("//internal:defines.bzl",
+    "VIVADO_CONFIG_ATTRS",
+    _script_cmd = "script_cmd",
+    _vivado_config = "vivado_config",
+)
+
+def _vivado_updatemem_impl(ctx):
+    """Implementation for the vivado_updatemem rule.
+
+    Args:
+      ctx: The rule context.
+
+    Returns:
+      A list of providers with DefaultInfo and VivadoBitstreamProvider
+      carrying the patched bitstream.
+    """
+    config = _vivado_config(ctx)
+    name = ctx.attr.name
+    bitstream = ctx.attr.bitstream[VivadoBitstreamProvider]
+    mem_info = getattr(bitstream, "mem_info", None)
+    if not mem_info:
+        fail("vivado_updatemem: {} has no memory map, build it with `write_mem_info = True`.".format(
+            ctx.attr.bitstream.label))
+
+    data_files = []
+    args = ctx.actions.args()
+    for (target, proc) in ctx.attr.data.items():
+        files = target.files.to_list()
+        if len(files) != 1:
+            fail("data: expected exactly one file in {}, got: {}".format(
+                target.label, files))
+        data_files += files
+        args.add("--data", "{}={}".format(proc, files[0].path))
+
+    bit_file = ctx.actions.declare_file("{}.bit".format(name))
+    args_file = ctx.actions.declare_file("{}.updatemem.args".format(name))
+    args.add("--out-args", args_file.path)
+    args.add("--meminfo", mem_info.path)
+    args.add("--bitfile", bitstream.bitstream.path)
+    args.add("--out-bit", bit_file.path)
+
+    inputs = [mem_info, bitstream.bitstream] + data_files
+    ctx.actions.run(
+        inputs = inputs,
+        outputs = [args_file],
+        executable = ctx.executable._updatememgen,
+        arguments = [args],
+        mnemonic = "UPDATEMEMGEN",
+        progress_message = "Generating updatemem arguments: {}".format(args_file.path),
+    )
+
+    docker_run = ctx.executable._script
+    cache_dir = ctx.actions.declare_directory("_vivado_updatemem.cache.{}".format(name))
+
+    # updatemem runs inside the container whose working directory is the
+    # exec root, so exec-root-relative paths resolve. It needs no license
+    # and does not start Vivado, so it takes seconds.
+    script = _script_cmd(
+        docker_run.path,
+        bit_file.path,
+        cache_dir.path,
+        freeargs = ["--net=host", "-e", "HOME=/work"],
+        container = config.container,
+    )
+
+    ctx.actions.run_shell(
+        progress_message = "Vivado updatemem \"{}\"".format(name),
+        inputs = [docker_run, args_file] + inputs,
+        outputs = [bit_file, cache_dir],
+        tools = [docker_run],
+        mnemonic = "VivadoUpdatemem",
+        command = (
+            "mkdir -p \"$(dirname {bit})\" && " +
+            "{script} " +
+            "LD_LIBRARY_PATH=\"{vivado_path}/lib/lnx64.o\" " +
+            "{vivado_path}/bin/updatemem $(cat {args}) 1>&2"
+        ).format(
+            bit = bit_file.path,
+            script = script,
+            vivado_path = config.vivado_path,
+            args = args_file.path,
+        ),
+    )
+
+    return [
+        DefaultInfo(files = depset([bit_file])),
+        VivadoBitstreamProvider(
+            bitstream = bit_file,
+            probes = bitstream.probes,
+            mem_info = mem_info,
+        ),
+    ]
+
+vivado_updatemem = rule(
+    implementation = _vivado_updatemem_impl,
+    doc = "Patches new block RAM contents (.elf/.mem) into a routed " +
+          "bitstream with `updatemem`, without running synthesis or place " +
+          "and route again.",
+    attrs = VIVADO_CONFIG_ATTRS | {
+        "bitstream": attr.label(
+            mandatory = True,
+            providers = [VivadoBitstreamProvider],
+            doc = "The place and route target to patch. It must be built " +
+                  "with `write_mem_info = True`.",
+        ),
+        "data": attr.label_keyed_string_dict(
+            mandatory = True,
+            allow_files = [".elf", ".mem"],
+            doc = "The memory contents to patch in, keyed by label, with " +
+                  "the processor instance path to load each into, e.g. " +
+                  "`{\":firmware\": \"design_1_i/microblaze_0\"}`. The " +
+                  "instance path may be empty if the design has only one " +
+                  "processor.",
+        ),
+        "_script": attr.label(
+            default = "@rules_bid//build:docker_run",
+            executable = True,
+            cfg = "host",
+            doc = "The docker run script.",
+        ),
+        "_updatememgen": attr.label(
+            default = Label("//build/vivado/bin/updatememgen"),
+            executable = True,
+            cfg = "host",
+            doc = "The program that generates the updatemem arguments.",
+        ),
+    },
+)